@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"JourneyPlanner/internal/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// @Summary AddActivity
+// @Tags activities
+// @Description Add an activity to the wishlist of the group, the scheduler will find time for it later
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param title query string true "Activity title"
+// @Param duration query models.Duration true "Activity duration"
+// @Param priority query int false "from 1 to 5, default 3"
+// @Param not_before query string false "earliest day" example(2024-10-21)
+// @Param not_after query string false "latest day" example(2024-10-25)
+// @Param opens query string false "opening time in trip timezone" example(09:00)
+// @Param closes query string false "closing time in trip timezone" example(18:00)
+// @Param location_name query string false "name of the place"
+// @Param address query string false "address of the place"
+// @Param lat query number false "latitude" example(41.9028)
+// @Param lon query number false "longitude" example(12.4964)
+// @Router /activities/add [post]
+func (h *Handler) AddActivity(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	numbers := map[string]int{"priority": 3}
+	for _, key := range []string{"days", "hours", "minutes", "priority"} {
+		if query.Get(key) == "" {
+			continue
+		}
+		value, err := strconv.Atoi(query.Get(key))
+		if err != nil {
+			http.Error(w, "Invalid "+key+" parameter", http.StatusBadRequest)
+			return
+		}
+		numbers[key] = value
+	}
+	location, err := parseLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	info := models.CreateActivity{
+		GroupID: query.Get("group_id"),
+		Title:   strings.TrimSpace(query.Get("title")),
+		Duration: models.Duration{
+			DurDays:    numbers["days"],
+			DurHours:   numbers["hours"],
+			DurMinutes: numbers["minutes"],
+		},
+		Priority:  numbers["priority"],
+		NotBefore: query.Get("not_before"),
+		NotAfter:  query.Get("not_after"),
+		Opens:     query.Get("opens"),
+		Closes:    query.Get("closes"),
+		Location:  location,
+	}
+	if err := validate.Struct(info); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = h.Activity.AddActivity(r.Context(), userLogin, info)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Activity is added")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary GetActivities
+// @Tags activities
+// @Description Get the wishlist of the group
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Router /activities/getlist [get]
+func (h *Handler) GetActivities(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	activities, err := h.Activity.GetActivities(r.Context(), r.URL.Query().Get("group_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"activities": activities,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary DeleteActivity
+// @Tags activities
+// @Description Remove an activity from the wishlist, only for leader or the member who added it
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param activity_id query string true "Id of activity"
+// @Router /activities/delete [delete]
+func (h *Handler) DeleteActivity(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Activity.DeleteActivity(r.Context(), query.Get("activity_id"), query.Get("group_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func planOptions(r *http.Request) (models.PlanOptions, error) {
+	query := r.URL.Query()
+	opts := models.PlanOptions{GroupID: query.Get("group_id"), Mode: strings.ToLower(query.Get("mode"))}
+	var err error
+	if query.Get("quiet_hours") != "" {
+		if opts.QuietHours, err = strconv.ParseBool(query.Get("quiet_hours")); err != nil {
+			return opts, err
+		}
+	}
+	if query.Get("buffer") != "" {
+		if opts.Buffer, err = strconv.Atoi(query.Get("buffer")); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// @Summary PlanActivities
+// @Tags activities
+// @Description Propose a schedule for the wishlist within free time of the trip, keeping time to travel between places. No task is created, the plan is kept for an hour to be accepted by its id
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param quiet_hours query bool false "skip 23:00-07:00 of trip local time"
+// @Param buffer query int false "least minutes to keep free between tasks"
+// @Param mode query string false "travel mode: walk, bike, transit, car (default), train"
+// @Router /activities/plan [get]
+func (h *Handler) PlanActivities(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	opts, err := planOptions(r)
+	if err != nil {
+		http.Error(w, "Invalid plan options", http.StatusBadRequest)
+		return
+	}
+	plan, err := h.Activity.PlanActivities(r.Context(), userLogin, opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"plan": plan,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary AcceptPlan
+// @Tags activities
+// @Description Turn a proposed plan into tasks exactly as it was shown, only for leader. All tasks are created or none
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param plan_id query string true "Id of the plan"
+// @Param activity_ids query string false "comma separated ids to accept, the whole plan if empty"
+// @Router /activities/accept [post]
+func (h *Handler) AcceptPlan(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	var activityIDs []string
+	for _, id := range strings.Split(query.Get("activity_ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			activityIDs = append(activityIDs, id)
+		}
+	}
+	accepted, err := h.Activity.AcceptPlan(r.Context(), userLogin, query.Get("group_id"), query.Get("plan_id"), activityIDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"result": accepted,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"JourneyPlanner/internal/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultAPIKeyExpiry = 90
+
+// @Summary Create API key
+// @Tags apikeys
+// @Description Create personal API key for scripts and bots. The key is shown only once
+// @Security BearerAuth
+// @Produce  json
+// @Param name query string true "name of key"
+// @Param scopes query string true "comma separated scopes" example(tasks:read,polls:write,chat)
+// @Param expires_in_days query int false "days until the key expires, 90 by default" minimum(1) maximum(365)
+// @Router /apikeys/add [post]
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	expiresInDays := defaultAPIKeyExpiry
+	var err error
+	expiresStr := r.URL.Query().Get("expires_in_days")
+	if expiresStr != "" {
+		expiresInDays, err = strconv.Atoi(expiresStr)
+		if err != nil {
+			http.Error(w, "Invalid expires_in_days parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	var scopes []string
+	for _, scope := range strings.Split(r.URL.Query().Get("scopes"), ",") {
+		scope = strings.TrimSpace(scope)
+		if scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	keyInfo := models.CreateAPIKey{
+		Name:          strings.TrimSpace(r.URL.Query().Get("name")),
+		Scopes:        scopes,
+		ExpiresInDays: expiresInDays,
+	}
+	if err := validate.Struct(keyInfo); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	key, err := h.APIKey.CreateAPIKey(r.Context(), userLogin, keyInfo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(key)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Get API keys
+// @Tags apikeys
+// @Description Get list of your API keys
+// @Security BearerAuth
+// @Produce  json
+// @Router /apikeys/getlist [get]
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	keys, err := h.APIKey.GetAPIKeys(r.Context(), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"api_keys": keys,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Delete API key
+// @Tags apikeys
+// @Description Revoke API key
+// @Security BearerAuth
+// @Produce  json
+// @Param key_id query string true "id of key"
+// @Router /apikeys/delete [delete]
+func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	keyID := r.URL.Query().Get("key_id")
+	err := h.APIKey.DeleteAPIKey(r.Context(), keyID, userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
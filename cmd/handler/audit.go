@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"JourneyPlanner/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// auditFilter reads the filter of the audit log from the query, from and to are RFC 3339 times
+func auditFilter(query url.Values) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		GroupID: query.Get("group_id"),
+		Actor:   query.Get("actor"),
+		Action:  query.Get("action"),
+	}
+	for key, date := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if query.Get(key) == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, query.Get(key))
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s parameter, use RFC 3339 like 2024-10-21T10:00:00Z", key)
+		}
+		*date = &parsed
+	}
+	return filter, nil
+}
+
+// @Summary GetAuditLog
+// @Tags Groups
+// @Description Who did what in the group and when, newest first. Task changes have the fields before and after the change. Only the leader can see it
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param actor query string false "login of who did it"
+// @Param action query string false "action" Enums(member.invited, member.joined, member.left, member.banned, member.unbanned, leader.changed, task.created, task.updated, task.deleted, poll.created, poll.closed, poll.deleted)
+// @Param from query string false "entries from this time" example(2024-10-21T00:00:00Z)
+// @Param to query string false "entries until this time" example(2024-10-28T00:00:00Z)
+// @Param page query int false "page number, starts with 1" example(1)
+// @Param limit query int false "entries per page, up to 100" example(50)
+// @Router /groups/audit [get]
+func (h *Handler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	filter, err := auditFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for key, value := range map[string]*int64{"page": &filter.Page, "limit": &filter.Limit} {
+		if query.Get(key) == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(query.Get(key), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid "+key+" parameter", http.StatusBadRequest)
+			return
+		}
+		*value = parsed
+	}
+	page, err := h.Audit.GetAuditLog(r.Context(), userLogin, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(page)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary ExportAuditLog
+// @Tags Groups
+// @Description Download the audit log of the group as CSV or JSON, with the same filters as the list. Only the leader can do it
+// @Security BearerAuth
+// @Produce  json
+// @Produce  text/csv
+// @Param group_id query string true "Id of group"
+// @Param format query string false "file format" Enums(csv, json)
+// @Param actor query string false "login of who did it"
+// @Param action query string false "action" Enums(member.invited, member.joined, member.left, member.banned, member.unbanned, leader.changed, task.created, task.updated, task.deleted, poll.created, poll.closed, poll.deleted)
+// @Param from query string false "entries from this time" example(2024-10-21T00:00:00Z)
+// @Param to query string false "entries until this time" example(2024-10-28T00:00:00Z)
+// @Router /groups/audit/export [get]
+func (h *Handler) ExportAuditLog(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	filter, err := auditFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	export, err := h.Audit.ExportAuditLog(r.Context(), userLogin, query.Get("format"), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	_, err = w.Write(export.Data)
+	if err != nil {
+		logs.Error("failed to write export: %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// @Summary SetTaskStatus
+// @Tags Tasks
+// @Description Change status of the task, only for leader. Cancelled tasks do not block time
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "task id"
+// @Param status query string true "new status" Enums(planned, confirmed, done, cancelled)
+// @Router /tasks/status [put]
+func (h *Handler) SetTaskStatus(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Task.SetTaskStatus(r.Context(), query.Get("group_id"), query.Get("task_id"), userLogin, query.Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary AddChecklistItem
+// @Tags Tasks
+// @Description Add item to the checklist of the task, only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "task id"
+// @Param title query string true "what has to be done"
+// @Param assignee query string false "login of the member responsible for the item"
+// @Router /tasks/checklist/add [post]
+func (h *Handler) AddChecklistItem(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Task.AddChecklistItem(r.Context(), query.Get("group_id"), query.Get("task_id"), userLogin, query.Get("title"), query.Get("assignee"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary AssignChecklistItem
+// @Tags Tasks
+// @Description Change the member responsible for the checklist item, only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "task id"
+// @Param item_id query string true "checklist item id"
+// @Param assignee query string false "login of the member, empty to unassign"
+// @Router /tasks/checklist/assign [put]
+func (h *Handler) AssignChecklistItem(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Task.AssignChecklistItem(r.Context(), query.Get("group_id"), query.Get("task_id"), query.Get("item_id"), userLogin, query.Get("assignee"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary CheckChecklistItem
+// @Tags Tasks
+// @Description Mark checklist item as done or not done. Allowed for the assignee and leader, unassigned items for any member
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "task id"
+// @Param item_id query string true "checklist item id"
+// @Param done query bool false "false to reopen the item" default(true)
+// @Router /tasks/checklist/check [put]
+func (h *Handler) CheckChecklistItem(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	done := true
+	var err error
+	if query.Get("done") != "" {
+		done, err = strconv.ParseBool(query.Get("done"))
+		if err != nil {
+			http.Error(w, "Invalid done parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	err = h.Task.CheckChecklistItem(r.Context(), query.Get("group_id"), query.Get("task_id"), query.Get("item_id"), userLogin, done)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary DeleteChecklistItem
+// @Tags Tasks
+// @Description Remove item from the checklist, only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "task id"
+// @Param item_id query string true "checklist item id"
+// @Router /tasks/checklist/delete [delete]
+func (h *Handler) DeleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Task.DeleteChecklistItem(r.Context(), query.Get("group_id"), query.Get("task_id"), query.Get("item_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary GetProgress
+// @Tags Tasks
+// @Description Tasks by status, checklist completion, outstanding items per member and days left until departure
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Router /tasks/progress [get]
+func (h *Handler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	progress, err := h.Task.GetProgress(r.Context(), r.URL.Query().Get("group_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"progress": progress,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"JourneyPlanner/internal/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Send friend request
+// @Tags contacts
+// @Description Send friend request to user
+// @Security BearerAuth
+// @Produce  json
+// @Param login query string true "login of user"
+// @Router /contacts/request [post]
+func (h *Handler) SendFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	otherLogin := r.URL.Query().Get("login")
+	if otherLogin == "" {
+		http.Error(w, "login is required", http.StatusBadRequest)
+		return
+	}
+	err := h.Contact.SendRequest(r.Context(), userLogin, otherLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Friend request is sent")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Accept friend request
+// @Tags contacts
+// @Description Accept incoming friend request
+// @Security BearerAuth
+// @Produce  json
+// @Param login query string true "login of user"
+// @Router /contacts/accept [post]
+func (h *Handler) AcceptFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	otherLogin := r.URL.Query().Get("login")
+	if otherLogin == "" {
+		http.Error(w, "login is required", http.StatusBadRequest)
+		return
+	}
+	err := h.Contact.AcceptRequest(r.Context(), userLogin, otherLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Decline friend request
+// @Tags contacts
+// @Description Decline incoming friend request
+// @Security BearerAuth
+// @Produce  json
+// @Param login query string true "login of user"
+// @Router /contacts/decline [post]
+func (h *Handler) DeclineFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	otherLogin := r.URL.Query().Get("login")
+	if otherLogin == "" {
+		http.Error(w, "login is required", http.StatusBadRequest)
+		return
+	}
+	err := h.Contact.DeclineRequest(r.Context(), userLogin, otherLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Remove contact
+// @Tags contacts
+// @Description Remove user from friends or cancel your friend request
+// @Security BearerAuth
+// @Produce  json
+// @Param login query string true "login of user"
+// @Router /contacts/remove [delete]
+func (h *Handler) RemoveContact(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	otherLogin := r.URL.Query().Get("login")
+	if otherLogin == "" {
+		http.Error(w, "login is required", http.StatusBadRequest)
+		return
+	}
+	err := h.Contact.RemoveContact(r.Context(), userLogin, otherLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Block user
+// @Tags contacts
+// @Description Block user, blocked users cant send you friend requests and dont see you in search
+// @Security BearerAuth
+// @Produce  json
+// @Param login query string true "login of user"
+// @Router /contacts/block [post]
+func (h *Handler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	otherLogin := r.URL.Query().Get("login")
+	if otherLogin == "" {
+		http.Error(w, "login is required", http.StatusBadRequest)
+		return
+	}
+	err := h.Contact.BlockUser(r.Context(), userLogin, otherLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Unblock user
+// @Tags contacts
+// @Description Unblock user
+// @Security BearerAuth
+// @Produce  json
+// @Param login query string true "login of user"
+// @Router /contacts/unblock [post]
+func (h *Handler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	otherLogin := r.URL.Query().Get("login")
+	if otherLogin == "" {
+		http.Error(w, "login is required", http.StatusBadRequest)
+		return
+	}
+	err := h.Contact.UnblockUser(r.Context(), userLogin, otherLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Get contacts
+// @Tags contacts
+// @Description Get your friends, friend requests and blocked users
+// @Security BearerAuth
+// @Produce  json
+// @Router /contacts/getlist [get]
+func (h *Handler) GetContacts(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	contacts, err := h.Contact.GetContactList(r.Context(), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"contacts": contacts,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Search users
+// @Tags users
+// @Description Search users by the beginning of login or display name
+// @Security BearerAuth
+// @Produce  json
+// @Param q query string true "beginning of login or display name" minlength(2)
+// @Param limit query int false "max number of results, 20 by default" minimum(1) maximum(50)
+// @Router /users/search [get]
+func (h *Handler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	var limit int
+	var err error
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	users, err := h.Contact.SearchUsers(r.Context(), userLogin, r.URL.Query().Get("q"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"users": users,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Update privacy
+// @Tags users
+// @Description Choose who can find you in user search
+// @Security BearerAuth
+// @Produce  json
+// @Param search_visibility query string true "who can find you" Enums(everyone, contacts, nobody)
+// @Router /users/privacy [put]
+func (h *Handler) UpdatePrivacy(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	privacy := models.Privacy{
+		SearchVisibility: r.URL.Query().Get("search_visibility"),
+	}
+	err := h.Contact.UpdatePrivacy(r.Context(), userLogin, privacy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Get invite suggestions
+// @Tags invites
+// @Description Get contacts and past co-travellers that can be invited to the group
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "id of group"
+// @Router /groups/invitesuggestions [get]
+func (h *Handler) GetInviteSuggestions(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	groupID := r.URL.Query().Get("group_id")
+	suggestions, err := h.Contact.GetInviteSuggestions(r.Context(), groupID, userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"suggestions": suggestions,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -55,6 +56,11 @@ func (h *Handler) AddGroup(w http.ResponseWriter, r *http.Request) {
 // @Description Get a list of all the groups you are a member of
 // @Security BearerAuth
 // @Produce  json
+// @Param status query string false "trip status" Enums(planning, booked, ongoing, finished)
+// @Param from query string false "only trips that end on or after this date" example(2024-10-21)
+// @Param to query string false "only trips that start on or before this date" example(2024-10-28)
+// @Param sort query string false "sort field" Enums(start_date, status, name)
+// @Param order query string false "sort order" Enums(asc, desc)
 // @Router /groups/getlist [get]
 func (h *Handler) GetGroups(w http.ResponseWriter, r *http.Request) {
 	userLogin, ok := r.Context().Value(UserLoginKey).(string)
@@ -63,7 +69,24 @@ func (h *Handler) GetGroups(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
-	groups, err := h.Group.GetGroupList(r.Context(), userLogin)
+	query := r.URL.Query()
+	filter := models.GroupFilter{
+		Status: query.Get("status"),
+		SortBy: query.Get("sort"),
+		Desc:   query.Get("order") == "desc",
+	}
+	for key, date := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if query.Get(key) == "" {
+			continue
+		}
+		parsed, err := time.Parse(models.TripDateFormat, query.Get(key))
+		if err != nil {
+			http.Error(w, "Invalid date format, use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		*date = &parsed
+	}
+	groups, err := h.Group.GetGroupList(r.Context(), userLogin, filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -112,6 +135,66 @@ func (h *Handler) GetGroupInfo(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// @Summary UpdateTrip
+// @Tags groups
+// @Description Update trip details of the group, only sent fields are changed, send empty value to clear a field. Only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "id of group"
+// @Param description query string false "description of the trip"
+// @Param destinations query string false "comma separated destinations" example(Rome,Florence)
+// @Param start_date query string false "first day of the trip" example(2024-10-21)
+// @Param end_date query string false "last day of the trip" example(2024-10-28)
+// @Param cover_image query string false "link to cover image"
+// @Param status query string false "trip status" Enums(planning, booked, ongoing, finished)
+// @Param timezone query string false "trip timezone, UTC if empty" example(Europe/Rome)
+// @Router /groups/trip [put]
+func (h *Handler) UpdateTrip(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	optional := func(key string) *string {
+		if !query.Has(key) {
+			return nil
+		}
+		value := strings.TrimSpace(query.Get(key))
+		return &value
+	}
+	trip := models.UpdateTrip{
+		Description:  optional("description"),
+		Destinations: optional("destinations"),
+		StartDate:    optional("start_date"),
+		EndDate:      optional("end_date"),
+		CoverImage:   optional("cover_image"),
+		Status:       optional("status"),
+		Timezone:     optional("timezone"),
+	}
+	if trip.IsEmpty() {
+		http.Error(w, "No new details", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(trip); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	err := h.Group.UpdateTrip(r.Context(), query.Get("group_id"), userLogin, trip)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
 // @Summary Get group blacklist
 // @Tags blacklist
 // @Description Get blacklist of group
@@ -256,6 +339,47 @@ func (h *Handler) ChangeLeader(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// @Summary AddModerator
+// @Tags groups
+// @Description Give member the moderator role, moderators review task proposals. Only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param user_login query string true "member login"
+// @Param group_id query string true "id of group"
+// @Router /groups/moderator/add [put]
+func (h *Handler) AddModerator(w http.ResponseWriter, r *http.Request) {
+	h.setModerator(w, r, true)
+}
+
+// @Summary RemoveModerator
+// @Tags groups
+// @Description Take the moderator role from member, only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param user_login query string true "member login"
+// @Param group_id query string true "id of group"
+// @Router /groups/moderator/remove [put]
+func (h *Handler) RemoveModerator(w http.ResponseWriter, r *http.Request) {
+	h.setModerator(w, r, false)
+}
+
+func (h *Handler) setModerator(w http.ResponseWriter, r *http.Request, moderator bool) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	groupId := r.URL.Query().Get("group_id")
+	memberLogin := r.URL.Query().Get("user_login")
+	err := h.Group.SetModerator(r.Context(), groupId, userLogin, memberLogin, moderator)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
 // @Summary DeleteGroup
 // @Tags groups
 // @Description Delete group by id
@@ -14,11 +14,13 @@ import (
 
 type GroupService interface {
 	CreateGroup(ctx context.Context, groupName, userLogin string) error
-	GetGroupList(ctx context.Context, userLogin string) ([]models.GroupList, error)
+	GetGroupList(ctx context.Context, userLogin string, filter models.GroupFilter) ([]models.GroupList, error)
 	GetGroupByID(ctx context.Context, groupID, userLogin string) (*models.Group, error)
+	UpdateTrip(ctx context.Context, groupID, userLogin string, update models.UpdateTrip) error
 	LeaveGroup(ctx context.Context, groupID, userLogin string) error
 	DeleteGroup(ctx context.Context, groupID, userLogin string) error
 	GiveLeaderRole(ctx context.Context, groupID, userLogin, memberLogin string) error
+	SetModerator(ctx context.Context, groupID, userLogin, memberLogin string, moderator bool) error
 	InviteUser(ctx context.Context, groupID, userLogin, invitedUser string) error
 	GetInviteList(ctx context.Context, userLogin string) ([]models.InvitationList, error)
 	DeclineInvite(ctx context.Context, userLogin, inviteID string) error
@@ -31,21 +33,119 @@ type PollService interface {
 	CreatePoll(ctx context.Context, pollInfo models.CreatePoll, userLogin string) error
 	GetPollList(ctx context.Context, groupID, userLogin string) (*models.PollList, error)
 	DeletePollByID(ctx context.Context, pollID, groupID, userLogin string) error
-	ClosePoll(ctx context.Context, pollID, groupID, userLogin string) error
+	ClosePoll(ctx context.Context, pollID, groupID, userLogin string) (*models.PollResult, error)
 	VotePoll(ctx context.Context, userLogin string, vote models.AddVote) error
 }
 
 type TaskService interface {
 	CreateTask(ctx context.Context, taskInfo models.CreateTask, userLogin string) error
 	GetTaskList(ctx context.Context, groupID, userLogin string) ([]models.Task, error)
-	UpdateTask(ctx context.Context, taskID, userLogin string, task models.CreateTask) error
+	UpdateTask(ctx context.Context, taskID, userLogin string, task models.CreateTask) ([]models.Task, error)
 	DeleteTask(ctx context.Context, taskID, groupID, userLogin string) error
+	GetItinerary(ctx context.Context, userLogin string, filter models.ItineraryFilter) (*models.Itinerary, error)
+	FindFreeSlots(ctx context.Context, userLogin string, search models.SlotSearch) ([]models.FreeSlot, error)
+	ExportRoute(ctx context.Context, userLogin, format string, filter models.ItineraryFilter) (*models.RouteExport, error)
+	UpdateOccurrence(ctx context.Context, taskID, userLogin, occurrence, scope string, update models.CreateTask) error
+	CancelOccurrence(ctx context.Context, groupID, taskID, userLogin, occurrence string) error
+	AddDependency(ctx context.Context, groupID, taskID, dependsOnID, userLogin string, gap int) error
+	RemoveDependency(ctx context.Context, groupID, taskID, dependsOnID, userLogin string) error
+	SetTaskStatus(ctx context.Context, groupID, taskID, userLogin, status string) error
+	AddChecklistItem(ctx context.Context, groupID, taskID, userLogin, title, assignee string) error
+	AssignChecklistItem(ctx context.Context, groupID, taskID, itemID, userLogin, assignee string) error
+	CheckChecklistItem(ctx context.Context, groupID, taskID, itemID, userLogin string, done bool) error
+	DeleteChecklistItem(ctx context.Context, groupID, taskID, itemID, userLogin string) error
+	GetProgress(ctx context.Context, groupID, userLogin string) (*models.GroupProgress, error)
+	GetTaskHistory(ctx context.Context, groupID, taskID, userLogin string) ([]models.TaskRevision, error)
+	GetDeletedTasks(ctx context.Context, groupID, userLogin string) ([]models.TaskRevision, error)
+	UndoTaskChange(ctx context.Context, groupID, taskID, userLogin string) (*models.Task, error)
+	RestoreTask(ctx context.Context, groupID, taskID, userLogin string) (*models.Task, error)
 }
 
 type UserService interface {
-	LoginUser(ctx context.Context, option, password string) (string, error)
+	LoginUser(ctx context.Context, option, password, ip string) (string, error)
 	RegisterUser(ctx context.Context, user models.SignUp) error
-	ValidatePasetoToken(tokenString string) (*service.TokenPayload, error)
+	ValidatePasetoToken(ctx context.Context, tokenString string) (*service.TokenPayload, error)
+	Logout(ctx context.Context, tokenString string) error
+	LogoutEverywhere(ctx context.Context, userLogin string) error
+	GetProfile(ctx context.Context, userLogin string) (*models.User, error)
+	GetPublicProfile(ctx context.Context, login string) (*models.PublicProfile, error)
+	UpdateProfile(ctx context.Context, userLogin string, profile models.UpdateProfile) error
+	ChangeEmail(ctx context.Context, userLogin, newEmail, password string) error
+	VerifyEmail(ctx context.Context, token string) error
+	DeleteAccount(ctx context.Context, userLogin, password string) (bool, error)
+	ConfirmAccountDeletion(ctx context.Context, token string) error
+	GetFailedLogins(ctx context.Context, userLogin string) ([]models.LoginAttempt, error)
+}
+
+type OIDCService interface {
+	BeginLogin(ctx context.Context) (string, error)
+	CompleteLogin(ctx context.Context, code, state string) (*models.OIDCLoginResult, error)
+	RegisterUser(ctx context.Context, signUp models.OIDCSignUp) (string, error)
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, userLogin string, keyInfo models.CreateAPIKey) (*models.CreatedAPIKey, error)
+	GetAPIKeys(ctx context.Context, userLogin string) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, keyID, userLogin string) error
+	ValidateAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error)
+}
+
+type ContactService interface {
+	SendRequest(ctx context.Context, userLogin, otherLogin string) error
+	AcceptRequest(ctx context.Context, userLogin, otherLogin string) error
+	DeclineRequest(ctx context.Context, userLogin, otherLogin string) error
+	RemoveContact(ctx context.Context, userLogin, otherLogin string) error
+	BlockUser(ctx context.Context, userLogin, otherLogin string) error
+	UnblockUser(ctx context.Context, userLogin, otherLogin string) error
+	GetContactList(ctx context.Context, userLogin string) (*models.ContactList, error)
+	SearchUsers(ctx context.Context, userLogin, prefix string, limit int) ([]models.UserSearchResult, error)
+	UpdatePrivacy(ctx context.Context, userLogin string, privacy models.Privacy) error
+	GetInviteSuggestions(ctx context.Context, groupID, userLogin string) ([]models.InviteSuggestion, error)
+}
+
+type ActivityService interface {
+	AddActivity(ctx context.Context, userLogin string, info models.CreateActivity) error
+	GetActivities(ctx context.Context, groupID, userLogin string) ([]models.Activity, error)
+	DeleteActivity(ctx context.Context, activityID, groupID, userLogin string) error
+	PlanActivities(ctx context.Context, userLogin string, opts models.PlanOptions) (*models.ActivityPlan, error)
+	AcceptPlan(ctx context.Context, userLogin, groupID, planID string, activityIDs []string) (*models.AcceptedPlan, error)
+}
+
+type ProposalService interface {
+	ProposeTask(ctx context.Context, userLogin string, taskInfo models.CreateTask, comment string) error
+	GetProposals(ctx context.Context, groupID, userLogin, status string) ([]models.TaskProposal, error)
+	EditProposal(ctx context.Context, proposalID, userLogin string, update models.CreateTask) error
+	ApproveProposal(ctx context.Context, groupID, proposalID, userLogin, reason string) error
+	RejectProposal(ctx context.Context, groupID, proposalID, userLogin, reason string) error
+}
+
+type ReminderService interface {
+	GetReminderSettings(ctx context.Context, groupID, userLogin string) (*models.ReminderSettings, error)
+	SetGroupReminder(ctx context.Context, groupID, userLogin string, minutes int) error
+	SetMyReminder(ctx context.Context, groupID, userLogin string, minutes int) error
+	ResetMyReminder(ctx context.Context, groupID, userLogin string) error
+}
+
+type NotificationService interface {
+	GetNotifications(ctx context.Context, filter models.NotificationFilter) (*models.NotificationPage, error)
+	MarkRead(ctx context.Context, notificationID, userLogin string) error
+	MarkAllRead(ctx context.Context, userLogin string) (int64, error)
+	GetNotificationSettings(ctx context.Context, userLogin string) (*models.NotificationSettings, error)
+	SetPreference(ctx context.Context, userLogin string, preference models.NotificationPreference) error
+	ResetPreference(ctx context.Context, userLogin, groupID, kind string) error
+	SetDigest(ctx context.Context, userLogin string, digest bool, hour *int) error
+}
+
+type WebhookService interface {
+	AddWebhook(ctx context.Context, groupID, userLogin, rawURL string, events []string) (*models.CreatedWebhook, error)
+	GetWebhooks(ctx context.Context, groupID, userLogin string) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, groupID, webhookID, userLogin string) error
+	GetDeliveries(ctx context.Context, groupID, webhookID, userLogin string) ([]models.WebhookDelivery, error)
+}
+
+type AuditService interface {
+	GetAuditLog(ctx context.Context, userLogin string, filter models.AuditFilter) (*models.AuditPage, error)
+	ExportAuditLog(ctx context.Context, userLogin, format string, filter models.AuditFilter) (*models.AuditExport, error)
 }
 
 type WsHandler interface {
@@ -53,60 +153,211 @@ type WsHandler interface {
 }
 
 type Handler struct {
-	Poll  PollService
-	Task  TaskService
-	User  UserService
-	Group GroupService
+	Poll         PollService
+	Task         TaskService
+	User         UserService
+	Group        GroupService
+	OIDC         OIDCService
+	APIKey       APIKeyService
+	Contact      ContactService
+	Activity     ActivityService
+	Proposal     ProposalService
+	Reminder     ReminderService
+	Notification NotificationService
+	Webhook      WebhookService
+	Audit        AuditService
 }
 
 func NewHandler(pollService PollService, taskService TaskService,
-	userService UserService, groupService GroupService) *Handler {
+	userService UserService, groupService GroupService, oidcService OIDCService,
+	apiKeyService APIKeyService, contactService ContactService, activityService ActivityService,
+	proposalService ProposalService, reminderService ReminderService, notificationService NotificationService,
+	webhookService WebhookService, auditService AuditService) *Handler {
 	return &Handler{
-		Poll:  pollService,
-		Task:  taskService,
-		User:  userService,
-		Group: groupService,
+		Poll:         pollService,
+		Task:         taskService,
+		User:         userService,
+		Group:        groupService,
+		OIDC:         oidcService,
+		APIKey:       apiKeyService,
+		Contact:      contactService,
+		Activity:     activityService,
+		Proposal:     proposalService,
+		Reminder:     reminderService,
+		Notification: notificationService,
+		Webhook:      webhookService,
+		Audit:        auditService,
 	}
 }
 
-func (h *Handler) InitRoutes(wsHandler WsHandler) *chi.Mux {
+func (h *Handler) InitRoutes(wsHandler, notificationsWs WsHandler) *chi.Mux {
 	r := chi.NewRouter()
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
 	r.Get("/join-group", h.JoinGroup)
+	r.Get("/users/verifyemail", h.VerifyEmail)
+	r.Get("/users/confirmdelete", h.ConfirmAccountDeletion)
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/singUp", h.SignUp)
 		r.Post("/signIn", h.SignIn)
+		r.Get("/oidc/login", h.OIDCLogin)
+		r.Get("/oidc/callback", h.OIDCCallback)
+		r.Post("/oidc/signUp", h.OIDCSignUp)
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware)
+			r.Use(h.RequireSession)
+			r.Post("/logout", h.Logout)
+			r.Post("/logoutAll", h.LogoutAll)
+		})
+	})
+	r.Route("/users", func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Use(h.RequireSession)
+		r.Get("/profile", h.GetProfile)
+		r.Put("/profile", h.UpdateProfile)
+		r.Get("/info", h.GetUserInfo)
+		r.Get("/search", h.SearchUsers)
+		r.Put("/privacy", h.UpdatePrivacy)
+		r.Put("/email", h.ChangeEmail)
+		r.Delete("/delete", h.DeleteAccount)
+		r.Get("/failedlogins", h.GetFailedLogins)
+	})
+	r.Route("/apikeys", func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Use(h.RequireSession)
+		r.Post("/add", h.CreateAPIKey)
+		r.Get("/getlist", h.GetAPIKeys)
+		r.Delete("/delete", h.DeleteAPIKey)
+	})
+	r.Route("/contacts", func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Use(h.RequireSession)
+		r.Get("/getlist", h.GetContacts)
+		r.Post("/request", h.SendFriendRequest)
+		r.Post("/accept", h.AcceptFriendRequest)
+		r.Post("/decline", h.DeclineFriendRequest)
+		r.Delete("/remove", h.RemoveContact)
+		r.Post("/block", h.BlockUser)
+		r.Post("/unblock", h.UnblockUser)
 	})
 	r.Route("/groups", func(r chi.Router) {
 		r.Use(h.AuthMiddleware)
-		r.Get("/ws", wsHandler.HandleConnections)
-		r.Post("/add", h.AddGroup)
-		r.Get("/getlist", h.GetGroups)
-		r.Get("/getgroupinfo", h.GetGroupInfo)
-		r.Post("/leaveGroup", h.LeaveFromGroup)
-		r.Put("/givelead", h.ChangeLeader)
-		r.Delete("/delete", h.DeleteGroup)
-		r.Post("/invite", h.Invite)
-		r.Get("/invitelist", h.GetInviteList)
-		r.Post("/declineinvite", h.DeclineInvite)
-		r.Put("/ban", h.BanMember)
-		r.Put("/unban", h.UnbanMember)
-		r.Get("/blacklist", h.GetBlacklist)
+		r.With(h.RequireScope(models.ScopeChat)).Get("/ws", wsHandler.HandleConnections)
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireScope(models.ScopeGroupsRead))
+			r.Get("/getlist", h.GetGroups)
+			r.Get("/getgroupinfo", h.GetGroupInfo)
+			r.Get("/invitelist", h.GetInviteList)
+			r.Get("/invitesuggestions", h.GetInviteSuggestions)
+			r.Get("/blacklist", h.GetBlacklist)
+			r.Get("/reminders", h.GetReminders)
+			r.Get("/audit", h.GetAuditLog)
+			r.Get("/audit/export", h.ExportAuditLog)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireScope(models.ScopeGroupsWrite))
+			r.Post("/add", h.AddGroup)
+			r.Post("/leaveGroup", h.LeaveFromGroup)
+			r.Put("/givelead", h.ChangeLeader)
+			r.Put("/moderator/add", h.AddModerator)
+			r.Put("/moderator/remove", h.RemoveModerator)
+			r.Put("/trip", h.UpdateTrip)
+			r.Delete("/delete", h.DeleteGroup)
+			r.Post("/invite", h.Invite)
+			r.Post("/declineinvite", h.DeclineInvite)
+			r.Put("/ban", h.BanMember)
+			r.Put("/unban", h.UnbanMember)
+			r.Put("/reminders", h.SetGroupReminder)
+			r.Put("/myreminders", h.SetMyReminder)
+			r.Delete("/myreminders", h.ResetMyReminder)
+		})
 	})
 	r.Route("/tasks", func(r chi.Router) {
 		r.Use(h.AuthMiddleware)
-		r.Post("/add", h.AddTask)
-		r.Get("/getlist", h.GetTasks)
-		r.Delete("/delete", h.DeleteTask)
-		r.Put("/update", h.UpdateTask)
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireScope(models.ScopeTasksRead))
+			r.Get("/getlist", h.GetTasks)
+			r.Get("/itinerary", h.GetItinerary)
+			r.Get("/freeslots", h.FindFreeSlots)
+			r.Get("/export", h.ExportRoute)
+			r.Get("/progress", h.GetProgress)
+			r.Get("/history", h.GetTaskHistory)
+			r.Get("/deleted", h.GetDeletedTasks)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireScope(models.ScopeTasksWrite))
+			r.Post("/add", h.AddTask)
+			r.Delete("/delete", h.DeleteTask)
+			r.Put("/update", h.UpdateTask)
+			r.Delete("/occurrence/cancel", h.CancelOccurrence)
+			r.Post("/dependency/add", h.AddDependency)
+			r.Delete("/dependency/delete", h.RemoveDependency)
+			r.Put("/status", h.SetTaskStatus)
+			r.Post("/checklist/add", h.AddChecklistItem)
+			r.Put("/checklist/assign", h.AssignChecklistItem)
+			r.Put("/checklist/check", h.CheckChecklistItem)
+			r.Delete("/checklist/delete", h.DeleteChecklistItem)
+			r.Put("/undo", h.UndoTaskChange)
+			r.Put("/restore", h.RestoreTask)
+		})
+	})
+	r.Route("/activities", func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireScope(models.ScopeTasksRead))
+			r.Get("/getlist", h.GetActivities)
+			r.Get("/plan", h.PlanActivities)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireScope(models.ScopeTasksWrite))
+			r.Post("/add", h.AddActivity)
+			r.Delete("/delete", h.DeleteActivity)
+			r.Post("/accept", h.AcceptPlan)
+		})
+	})
+	r.Route("/proposals", func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireScope(models.ScopeTasksRead))
+			r.Get("/getlist", h.GetProposals)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireScope(models.ScopeTasksWrite))
+			r.Post("/add", h.ProposeTask)
+			r.Put("/update", h.EditProposal)
+			r.Put("/approve", h.ApproveProposal)
+			r.Put("/reject", h.RejectProposal)
+		})
+	})
+	r.Route("/notifications", func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Use(h.RequireSession)
+		r.Get("/ws", notificationsWs.HandleConnections)
+		r.Get("/getlist", h.GetNotifications)
+		r.Put("/read", h.MarkNotificationRead)
+		r.Put("/readall", h.MarkAllNotificationsRead)
+		r.Get("/settings", h.GetNotificationSettings)
+		r.Put("/preference", h.SetNotificationPreference)
+		r.Delete("/preference", h.ResetNotificationPreference)
+		r.Put("/digest", h.SetDigest)
+	})
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Use(h.AuthMiddleware)
+		r.Use(h.RequireSession)
+		r.Post("/add", h.AddWebhook)
+		r.Get("/getlist", h.GetWebhooks)
+		r.Delete("/delete", h.DeleteWebhook)
+		r.Get("/deliveries", h.GetWebhookDeliveries)
 	})
 	r.Route("/polls", func(r chi.Router) {
 		r.Use(h.AuthMiddleware)
-		r.Post("/add", h.CreatePoll)
-		r.Get("/getlist", h.GetPolls)
-		r.Delete("/delete", h.DeletePoll)
-		r.Put("/close", h.ClosePoll)
-		r.Put("/vote", h.VotePoll)
+		r.With(h.RequireScope(models.ScopePollsRead)).Get("/getlist", h.GetPolls)
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireScope(models.ScopePollsWrite))
+			r.Post("/add", h.CreatePoll)
+			r.Delete("/delete", h.DeletePoll)
+			r.Put("/close", h.ClosePoll)
+			r.Put("/vote", h.VotePoll)
+		})
 	})
 	return r
 }
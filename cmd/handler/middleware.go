@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"JourneyPlanner/internal/models"
 	"context"
 	"net/http"
+	"slices"
 	"strings"
 )
 
@@ -10,6 +12,7 @@ type ContextKey string
 
 const (
 	UserLoginKey ContextKey = "user_id"
+	ScopesKey    ContextKey = "scopes"
 )
 
 func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
@@ -26,7 +29,19 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		payload, err := h.User.ValidatePasetoToken(token)
+		if strings.HasPrefix(token, models.APIKeyPrefix) {
+			apiKey, err := h.APIKey.ValidateAPIKey(r.Context(), token)
+			if err != nil {
+				http.Error(w, "Invalid or expired api key", http.StatusUnauthorized)
+				return
+			}
+			ctx := context.WithValue(r.Context(), UserLoginKey, apiKey.UserLogin)
+			ctx = context.WithValue(ctx, ScopesKey, apiKey.Scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		payload, err := h.User.ValidatePasetoToken(r.Context(), token)
 		if err != nil {
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
@@ -36,3 +51,30 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// RequireScope lets through sessions and api keys that were granted the scope.
+// It must be used after AuthMiddleware.
+func (h *Handler) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, isAPIKey := r.Context().Value(ScopesKey).([]string)
+			if isAPIKey && !slices.Contains(scopes, scope) {
+				http.Error(w, "API key has no "+scope+" scope", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession rejects requests authorized with an api key.
+// It must be used after AuthMiddleware.
+func (h *Handler) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, isAPIKey := r.Context().Value(ScopesKey).([]string); isAPIKey {
+			http.Error(w, "This action requires signing in with a password or OIDC", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
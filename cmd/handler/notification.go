@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"JourneyPlanner/internal/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// @Summary GetNotifications
+// @Tags Notifications
+// @Description Your notifications, newest first, with the number of unread ones
+// @Security BearerAuth
+// @Produce  json
+// @Param page query int false "page number, starts with 1" example(1)
+// @Param limit query int false "notifications per page, up to 100" example(20)
+// @Param unread query bool false "only unread notifications"
+// @Router /notifications/getlist [get]
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	filter := models.NotificationFilter{UserLogin: userLogin}
+	for key, value := range map[string]*int64{"page": &filter.Page, "limit": &filter.Limit} {
+		if query.Get(key) == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(query.Get(key), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid "+key+" parameter", http.StatusBadRequest)
+			return
+		}
+		*value = parsed
+	}
+	if query.Get("unread") != "" {
+		unread, err := strconv.ParseBool(query.Get("unread"))
+		if err != nil {
+			http.Error(w, "invalid unread parameter", http.StatusBadRequest)
+			return
+		}
+		filter.UnreadOnly = unread
+	}
+	page, err := h.Notification.GetNotifications(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(page)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary MarkNotificationRead
+// @Tags Notifications
+// @Description Mark one notification as read
+// @Security BearerAuth
+// @Produce  json
+// @Param notification_id query string true "Id of notification"
+// @Router /notifications/read [put]
+func (h *Handler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	err := h.Notification.MarkRead(r.Context(), r.URL.Query().Get("notification_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary MarkAllNotificationsRead
+// @Tags Notifications
+// @Description Mark all your notifications as read
+// @Security BearerAuth
+// @Produce  json
+// @Router /notifications/readall [put]
+func (h *Handler) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	count, err := h.Notification.MarkAllRead(r.Context(), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"marked": count,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary GetNotificationSettings
+// @Tags Notifications
+// @Description Your delivery preferences and whether you get a daily digest instead of emails at once
+// @Security BearerAuth
+// @Produce  json
+// @Router /notifications/settings [get]
+func (h *Handler) GetNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	settings, err := h.Notification.GetNotificationSettings(r.Context(), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"settings": settings,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary SetNotificationPreference
+// @Tags Notifications
+// @Description Choose how notifications of a kind are delivered, in one group or, without group_id, in all groups
+// @Security BearerAuth
+// @Produce  json
+// @Param kind query string true "invite, ban, leader_changed, poll_created, poll_result, task_changed, task_reminder or proposal_rejected"
+// @Param delivery query string true "in_app, email, both or none"
+// @Param group_id query string false "Id of group"
+// @Router /notifications/preference [put]
+func (h *Handler) SetNotificationPreference(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Notification.SetPreference(r.Context(), userLogin, models.NotificationPreference{
+		GroupID:  query.Get("group_id"),
+		Kind:     query.Get("kind"),
+		Delivery: query.Get("delivery"),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary ResetNotificationPreference
+// @Tags Notifications
+// @Description Remove a preference, the one for all groups or in-app delivery applies again
+// @Security BearerAuth
+// @Produce  json
+// @Param kind query string true "kind of notifications"
+// @Param group_id query string false "Id of group"
+// @Router /notifications/preference [delete]
+func (h *Handler) ResetNotificationPreference(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Notification.ResetPreference(r.Context(), userLogin, query.Get("group_id"), query.Get("kind"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary SetDigest
+// @Tags Notifications
+// @Description Get one email a day with upcoming tasks, polls closing soon, mentions and notifications instead of emails at once, it is sent at the chosen hour in the timezone of your profile
+// @Security BearerAuth
+// @Produce  json
+// @Param enabled query bool true "true to get the daily digest"
+// @Param hour query int false "local hour the digest is sent at, 0-23, 8 by default"
+// @Router /notifications/digest [put]
+func (h *Handler) SetDigest(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	enabled, err := strconv.ParseBool(r.URL.Query().Get("enabled"))
+	if err != nil {
+		http.Error(w, "invalid enabled parameter", http.StatusBadRequest)
+		return
+	}
+	var hour *int
+	if hourStr := r.URL.Query().Get("hour"); hourStr != "" {
+		parsed, err := strconv.Atoi(hourStr)
+		if err != nil {
+			http.Error(w, "invalid hour parameter", http.StatusBadRequest)
+			return
+		}
+		hour = &parsed
+	}
+	err = h.Notification.SetDigest(r.Context(), userLogin, enabled, hour)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
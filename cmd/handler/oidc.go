@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"JourneyPlanner/internal/models"
+	"encoding/json"
+	"net/http"
+)
+
+// @Summary OIDC login
+// @Tags users
+// @Description Start sign in with the external OpenID Connect provider, redirects to the provider
+// @Router /auth/oidc/login [get]
+func (h *Handler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, err := h.OIDC.BeginLogin(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// @Summary OIDC callback
+// @Tags users
+// @Description Finish sign in with the external provider.
+// @Description Returns a token, or a registration ticket if there is no account with this email yet
+// @Produce  json
+// @Param code query string true "authorization code"
+// @Param state query string true "login state"
+// @Router /auth/oidc/callback [get]
+func (h *Handler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if providerErr := r.URL.Query().Get("error"); providerErr != "" {
+		http.Error(w, "Identity provider error: "+providerErr, http.StatusUnauthorized)
+		return
+	}
+	code := r.URL.Query().Get("code")
+	state := r.URL.Query().Get("state")
+	if code == "" || state == "" {
+		http.Error(w, "code and state are required", http.StatusBadRequest)
+		return
+	}
+	result, err := h.OIDC.CompleteLogin(r.Context(), code, state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary OIDC SignUp
+// @Tags users
+// @Description Create account for the identity from OIDC callback with chosen login
+// @Produce  json
+// @Param ticket query string true "registration ticket"
+// @Param login query string true "your login"
+// @Router /auth/oidc/signUp [post]
+func (h *Handler) OIDCSignUp(w http.ResponseWriter, r *http.Request) {
+	input := models.OIDCSignUp{
+		Ticket: r.URL.Query().Get("ticket"),
+		Login:  r.URL.Query().Get("login"),
+	}
+	if err := validate.Struct(input); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	token, err := h.OIDC.RegisterUser(r.Context(), input)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(token)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
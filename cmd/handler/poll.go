@@ -19,6 +19,8 @@ const noDuration = 15770000 // if selected no duration, poll will be active for
 // @Param firstOption query string true "first option "
 // @Param sercondOption query string true "second option"
 // @Param duration query uint false "duration of poll in minutes" minimum(0)
+// @Param firstAction query string false "JSON action run if the first option wins: kind task (with task as in AddTask), trip (with trip fields as in UpdateTrip) or leader (with login)" example({"kind":"task","task":{"title":"Museum","start_time":{"start_date":"2024-10-21","start_time":"10:00"},"duration":{"hours":3}}})
+// @Param secondAction query string false "JSON action run if the second option wins" example({"kind":"leader","leader":"anna"})
 // @Router /polls/add [post]
 func (h *Handler) CreatePoll(w http.ResponseWriter, r *http.Request) {
 	userLogin, ok := r.Context().Value(UserLoginKey).(string)
@@ -50,6 +52,16 @@ func (h *Handler) CreatePoll(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	pollInfo.FirstAction, err = parsePollAction(r.URL.Query().Get("firstAction"))
+	if err != nil {
+		http.Error(w, "Invalid firstAction: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	pollInfo.SecondAction, err = parsePollAction(r.URL.Query().Get("secondAction"))
+	if err != nil {
+		http.Error(w, "Invalid secondAction: "+err.Error(), http.StatusBadRequest)
+		return
+	}
 	err = h.Poll.CreatePoll(r.Context(), pollInfo, userLogin)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -63,6 +75,23 @@ func (h *Handler) CreatePoll(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// parsePollAction returns nil when the option has no action
+func parsePollAction(value string) (*models.PollAction, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var action models.PollAction
+	if err := json.Unmarshal([]byte(value), &action); err != nil {
+		return nil, err
+	}
+	if action.Trip != nil {
+		if err := validate.Struct(action.Trip); err != nil {
+			return nil, err
+		}
+	}
+	return &action, nil
+}
+
 // @Summary GetPolls
 // @Tags polls
 // @Description Get list of polls
@@ -128,7 +157,7 @@ func (h *Handler) DeletePoll(w http.ResponseWriter, r *http.Request) {
 
 // @Summary Close Poll
 // @Tags polls
-// @Description Close poll for voting
+// @Description Close poll for voting. If the winning option has an action, it is run and its result is returned
 // @Security BearerAuth
 // @Produce  json
 // @Param groupID query string true "id of group"
@@ -143,13 +172,17 @@ func (h *Handler) ClosePoll(w http.ResponseWriter, r *http.Request) {
 	}
 	groupID := r.URL.Query().Get("groupID")
 	pollID := r.URL.Query().Get("pollID")
-	err := h.Poll.ClosePoll(r.Context(), pollID, groupID, userLogin)
+	result, err := h.Poll.ClosePoll(r.Context(), pollID, groupID, userLogin)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	var response interface{} = "Done"
+	if result != nil {
+		response = map[string]interface{}{"result": result}
+	}
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode("Done")
+	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		logs.Error("failed to encode JSON: %v", err)
 		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
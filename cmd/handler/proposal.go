@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"JourneyPlanner/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// parseTaskInfo reads the task parameters shared with AddTask, missing duration parts are zero
+func parseTaskInfo(r *http.Request) (models.CreateTask, error) {
+	query := r.URL.Query()
+	var duration models.Duration
+	for key, value := range map[string]*int{"days": &duration.DurDays, "hours": &duration.DurHours, "minutes": &duration.DurMinutes} {
+		if query.Get(key) == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(query.Get(key))
+		if err != nil {
+			return models.CreateTask{}, fmt.Errorf("invalid %s duration parameter", key)
+		}
+		*value = parsed
+	}
+	location, err := parseLocation(r)
+	if err != nil {
+		return models.CreateTask{}, err
+	}
+	return models.CreateTask{
+		GroupID:    query.Get("group_id"),
+		Title:      query.Get("title"),
+		Location:   location,
+		Recurrence: query.Get("rrule"),
+		StartTime: models.StartTime{
+			StartDate: query.Get("start_date"),
+			StartTime: query.Get("start_time"),
+		},
+		Duration: duration,
+	}, nil
+}
+
+// @Summary ProposeTask
+// @Tags Proposals
+// @Description Suggest a task to the group, it is checked like a new task and created when the leader or a moderator approves it
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param title query string true "Task Details"
+// @Param start_time query models.StartTime true "Tasks start time"
+// @Param duration query models.Duration true "Tasks duration"
+// @Param location_name query string false "name of the place"
+// @Param address query string false "address of the place"
+// @Param lat query number false "latitude" example(41.9028)
+// @Param lon query number false "longitude" example(12.4964)
+// @Param rrule query string false "recurrence rule: FREQ=DAILY|WEEKLY with INTERVAL, COUNT, UNTIL, BYDAY" example(FREQ=DAILY;INTERVAL=2)
+// @Param comment query string false "why the task is worth it"
+// @Router /proposals/add [post]
+func (h *Handler) ProposeTask(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	taskInfo, err := parseTaskInfo(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if taskInfo.IsEmpty() {
+		http.Error(w, "Task is empty or missing required fields", http.StatusBadRequest)
+		return
+	}
+	err = h.Proposal.ProposeTask(r.Context(), userLogin, taskInfo, r.URL.Query().Get("comment"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Proposal is sent for review")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary GetProposals
+// @Tags Proposals
+// @Description Leader and moderators get the review queue (pending proposals by default), other members get their own proposals with decisions
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param status query string false "filter by status, all for any status" Enums(pending, approved, rejected, all)
+// @Router /proposals/getlist [get]
+func (h *Handler) GetProposals(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	status := query.Get("status")
+	switch status {
+	case "":
+		status = models.ProposalPending
+	case "all":
+		status = ""
+	}
+	proposals, err := h.Proposal.GetProposals(r.Context(), query.Get("group_id"), userLogin, status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"proposals": proposals,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary EditProposal
+// @Tags Proposals
+// @Description Change a pending proposal, for its author, leader and moderators. Only sent fields are changed
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param proposal_id query string true "proposal id"
+// @Param title query string false "Task Details"
+// @Param start_time query models.StartTime false "Tasks start time"
+// @Param duration query models.Duration false "Tasks duration"
+// @Param location_name query string false "name of the place"
+// @Param address query string false "address of the place"
+// @Param lat query number false "latitude" example(41.9028)
+// @Param lon query number false "longitude" example(12.4964)
+// @Param rrule query string false "recurrence rule" example(FREQ=DAILY;INTERVAL=2)
+// @Router /proposals/update [put]
+func (h *Handler) EditProposal(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	update, err := parseTaskInfo(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = h.Proposal.EditProposal(r.Context(), r.URL.Query().Get("proposal_id"), userLogin, update)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary ApproveProposal
+// @Tags Proposals
+// @Description Create the proposed task, for leader and moderators. The task is checked again against current tasks
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param proposal_id query string true "proposal id"
+// @Param reason query string false "message to the author"
+// @Router /proposals/approve [put]
+func (h *Handler) ApproveProposal(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Proposal.ApproveProposal(r.Context(), query.Get("group_id"), query.Get("proposal_id"), userLogin, query.Get("reason"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Task is created")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary RejectProposal
+// @Tags Proposals
+// @Description Reject the proposal with a reason for its author, for leader and moderators
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param proposal_id query string true "proposal id"
+// @Param reason query string true "why the proposal is rejected"
+// @Router /proposals/reject [put]
+func (h *Handler) RejectProposal(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Proposal.RejectProposal(r.Context(), query.Get("group_id"), query.Get("proposal_id"), userLogin, query.Get("reason"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
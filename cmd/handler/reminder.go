@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// @Summary GetReminders
+// @Tags Groups
+// @Description How long before tasks the group and the user are reminded, 0 means reminders are off
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Router /groups/reminders [get]
+func (h *Handler) GetReminders(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	settings, err := h.Reminder.GetReminderSettings(r.Context(), r.URL.Query().Get("group_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"reminders": settings,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary SetGroupReminder
+// @Tags Groups
+// @Description Set how long before tasks members are reminded in the group chat and their notifications, only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param minutes query int true "minutes before the task, 0 turns reminders off" example(30)
+// @Router /groups/reminders [put]
+func (h *Handler) SetGroupReminder(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	minutes, err := strconv.Atoi(r.URL.Query().Get("minutes"))
+	if err != nil {
+		http.Error(w, "invalid minutes parameter", http.StatusBadRequest)
+		return
+	}
+	err = h.Reminder.SetGroupReminder(r.Context(), r.URL.Query().Get("group_id"), userLogin, minutes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary SetMyReminder
+// @Tags Groups
+// @Description Set your own reminder time in the group instead of the group one
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param minutes query int true "minutes before the task, 0 turns your reminders off" example(60)
+// @Router /groups/myreminders [put]
+func (h *Handler) SetMyReminder(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	minutes, err := strconv.Atoi(r.URL.Query().Get("minutes"))
+	if err != nil {
+		http.Error(w, "invalid minutes parameter", http.StatusBadRequest)
+		return
+	}
+	err = h.Reminder.SetMyReminder(r.Context(), r.URL.Query().Get("group_id"), userLogin, minutes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary ResetMyReminder
+// @Tags Groups
+// @Description Go back to the reminder time of the group
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Router /groups/myreminders [delete]
+func (h *Handler) ResetMyReminder(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	err := h.Reminder.ResetMyReminder(r.Context(), r.URL.Query().Get("group_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
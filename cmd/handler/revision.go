@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// @Summary GetTaskHistory
+// @Tags Tasks
+// @Description All saved versions of the task, newest first, with who changed it and the task before and after the change. Works for deleted tasks too
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "Id of task"
+// @Router /tasks/history [get]
+func (h *Handler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	history, err := h.Task.GetTaskHistory(r.Context(), query.Get("group_id"), query.Get("task_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"history": history,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary GetDeletedTasks
+// @Tags Tasks
+// @Description Deleted tasks of the group that can still be restored, newest first
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Router /tasks/deleted [get]
+func (h *Handler) GetDeletedTasks(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	deleted, err := h.Task.GetDeletedTasks(r.Context(), r.URL.Query().Get("group_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"deleted": deleted,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary UndoTaskChange
+// @Tags Tasks
+// @Description Bring the task back to how it was before its last change, the task is checked against the current plan again. Only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "Id of task"
+// @Router /tasks/undo [put]
+func (h *Handler) UndoTaskChange(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	task, err := h.Task.UndoTaskChange(r.Context(), query.Get("group_id"), query.Get("task_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"task": task,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary RestoreTask
+// @Tags Tasks
+// @Description Bring a deleted task back as it was when it was deleted, the task is checked against the current plan again. Only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "Id of the deleted task"
+// @Router /tasks/restore [put]
+func (h *Handler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	task, err := h.Task.RestoreTask(r.Context(), query.Get("group_id"), query.Get("task_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"task": task,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -3,8 +3,11 @@ package handler
 import (
 	"JourneyPlanner/internal/models"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // @Summary AddTask
@@ -16,6 +19,11 @@ import (
 // @Param title query string true "Task Details"
 // @Param start_time query models.StartTime true "Tasks start time"
 // @Param duration query models.Duration true "Tasks duration"
+// @Param location_name query string false "name of the place"
+// @Param address query string false "address of the place"
+// @Param lat query number false "latitude" example(41.9028)
+// @Param lon query number false "longitude" example(12.4964)
+// @Param rrule query string false "recurrence rule: FREQ=DAILY|WEEKLY with INTERVAL, COUNT, UNTIL, BYDAY" example(FREQ=DAILY;INTERVAL=2)
 // @Router /tasks/add [post]
 func (h *Handler) AddTask(w http.ResponseWriter, r *http.Request) {
 	userLogin, ok := r.Context().Value(UserLoginKey).(string)
@@ -56,9 +64,16 @@ func (h *Handler) AddTask(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	location, err := parseLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	taskInfo := models.CreateTask{
-		GroupID: r.URL.Query().Get("group_id"),
-		Title:   r.URL.Query().Get("title"),
+		GroupID:    r.URL.Query().Get("group_id"),
+		Location:   location,
+		Recurrence: r.URL.Query().Get("rrule"),
+		Title:      r.URL.Query().Get("title"),
 		StartTime: models.StartTime{
 			StartDate: r.URL.Query().Get("start_date"),
 			StartTime: r.URL.Query().Get("start_time"),
@@ -88,6 +103,29 @@ func (h *Handler) AddTask(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// parseLocation returns nil when no location parameter was sent
+func parseLocation(r *http.Request) (*models.Location, error) {
+	query := r.URL.Query()
+	if !query.Has("location_name") && !query.Has("address") && !query.Has("lat") && !query.Has("lon") {
+		return nil, nil
+	}
+	location := &models.Location{
+		Name:    strings.TrimSpace(query.Get("location_name")),
+		Address: strings.TrimSpace(query.Get("address")),
+	}
+	for key, coordinate := range map[string]**float64{"lat": &location.Lat, "lon": &location.Lon} {
+		if query.Get(key) == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(query.Get(key), 64)
+		if err != nil {
+			return nil, errors.New("invalid " + key + " parameter")
+		}
+		*coordinate = &value
+	}
+	return location, nil
+}
+
 // @Summary GetTasks
 // @Tags Tasks
 // @Description Create new task
@@ -141,6 +179,14 @@ func (h *Handler) GetTasks(w http.ResponseWriter, r *http.Request) {
 // @Param title query string false "Task Details"
 // @Param start_time query models.StartTime false "Tasks start time"
 // @Param duration query models.Duration false "Tasks duration"
+// @Param location_name query string false "name of the place"
+// @Param address query string false "address of the place"
+// @Param lat query number false "latitude" example(41.9028)
+// @Param lon query number false "longitude" example(12.4964)
+// @Param rrule query string false "new recurrence rule" example(FREQ=WEEKLY;BYDAY=MO,WE)
+// @Param occurrence query string false "original start of the occurrence to change, for recurring tasks" example(2024-10-21T08:00:00Z)
+// @Param scope query string false "with occurrence: change only it or it and all following" Enums(this, following)
+// @Param cascade query bool false "shift dependent tasks when the task moves later"
 // @Router /tasks/update [put]
 func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	userLogin, ok := r.Context().Value(UserLoginKey).(string)
@@ -181,9 +227,16 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	location, err := parseLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	updateTask := models.CreateTask{
-		GroupID: r.URL.Query().Get("group_id"),
-		Title:   r.URL.Query().Get("title"),
+		GroupID:    r.URL.Query().Get("group_id"),
+		Location:   location,
+		Recurrence: r.URL.Query().Get("rrule"),
+		Title:      r.URL.Query().Get("title"),
 		StartTime: models.StartTime{
 			StartDate: r.URL.Query().Get("start_date"),
 			StartTime: r.URL.Query().Get("start_time"),
@@ -193,6 +246,7 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 			DurHours:   durHours,
 			DurMinutes: durMinutes,
 		},
+		Cascade: r.URL.Query().Get("cascade") == "true",
 	}
 	if updateTask.IsEmptyUpdate() {
 		http.Error(w, "No new details", http.StatusBadRequest)
@@ -202,14 +256,36 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "if you change start time, you need to fill and another part, and vice versa", http.StatusBadRequest)
 		return
 	}
-	err = h.Task.UpdateTask(r.Context(), taskID, userLogin, updateTask)
+	if occurrence := r.URL.Query().Get("occurrence"); occurrence != "" {
+		scope := r.URL.Query().Get("scope")
+		if scope == "" {
+			scope = models.ScopeThisOccurrence
+		}
+		err = h.Task.UpdateOccurrence(r.Context(), taskID, userLogin, occurrence, scope, updateTask)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode("Done")
+		if err != nil {
+			logs.Error("failed to encode JSON: %v", err)
+			http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		}
+		return
+	}
+	shifted, err := h.Task.UpdateTask(r.Context(), taskID, userLogin, updateTask)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode("Done")
+	if len(shifted) > 0 {
+		err = json.NewEncoder(w).Encode(map[string]interface{}{"shifted": shifted})
+	} else {
+		err = json.NewEncoder(w).Encode("Done")
+	}
 	if err != nil {
 		logs.Error("failed to encode JSON: %v", err)
 		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
@@ -248,3 +324,258 @@ func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// @Summary GetItinerary
+// @Tags Tasks
+// @Description Get tasks of the trip day by day in the trip timezone with free time between them. By default shows the trip dates
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param from query string false "first day to show" example(2024-10-21)
+// @Param to query string false "last day to show" example(2024-10-28)
+// @Param mode query string false "travel mode for travel time between tasks" Enums(walk, bike, transit, car, train)
+// @Router /tasks/itinerary [get]
+func (h *Handler) GetItinerary(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	filter := models.ItineraryFilter{
+		GroupID: query.Get("group_id"),
+		From:    query.Get("from"),
+		To:      query.Get("to"),
+		Mode:    query.Get("mode"),
+	}
+	itinerary, err := h.Task.GetItinerary(r.Context(), userLogin, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"itinerary": itinerary,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary FindFreeSlots
+// @Tags Tasks
+// @Description Find free time slots of at least the given duration. By default searches within the trip dates
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param duration query models.Duration true "Needed duration"
+// @Param from query string false "first day to search" example(2024-10-21)
+// @Param to query string false "last day to search" example(2024-10-28)
+// @Param quiet_hours query bool false "skip 23:00-07:00 of trip local time"
+// @Param buffer query int false "minutes to keep free before and after other tasks"
+// @Router /tasks/freeslots [get]
+func (h *Handler) FindFreeSlots(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	numbers := map[string]int{}
+	for _, key := range []string{"days", "hours", "minutes", "buffer"} {
+		if query.Get(key) == "" {
+			continue
+		}
+		value, err := strconv.Atoi(query.Get(key))
+		if err != nil {
+			http.Error(w, "Invalid "+key+" parameter", http.StatusBadRequest)
+			return
+		}
+		numbers[key] = value
+	}
+	var quietHours bool
+	if query.Get("quiet_hours") != "" {
+		var err error
+		quietHours, err = strconv.ParseBool(query.Get("quiet_hours"))
+		if err != nil {
+			http.Error(w, "Invalid quiet_hours parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	search := models.SlotSearch{
+		GroupID: query.Get("group_id"),
+		Duration: models.Duration{
+			DurDays:    numbers["days"],
+			DurHours:   numbers["hours"],
+			DurMinutes: numbers["minutes"],
+		},
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+		QuietHours: quietHours,
+		Buffer:     numbers["buffer"],
+	}
+	if search.Duration.IsEmpty() {
+		http.Error(w, "Duration is missing", http.StatusBadRequest)
+		return
+	}
+	slots, err := h.Task.FindFreeSlots(r.Context(), userLogin, search)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"slots": slots,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary CancelOccurrence
+// @Tags Tasks
+// @Description Cancel one occurrence of a recurring task without deleting the series, only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "task id"
+// @Param occurrence query string true "original start of the occurrence" example(2024-10-21T08:00:00Z)
+// @Router /tasks/occurrence/cancel [delete]
+func (h *Handler) CancelOccurrence(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Task.CancelOccurrence(r.Context(), query.Get("group_id"), query.Get("task_id"), userLogin, query.Get("occurrence"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary AddDependency
+// @Tags Tasks
+// @Description Make the task start only after another task ends, only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "task id"
+// @Param depends_on query string true "id of the task that must end first"
+// @Param gap query int false "minutes between the tasks"
+// @Router /tasks/dependency/add [post]
+func (h *Handler) AddDependency(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	var gap int
+	var err error
+	if query.Get("gap") != "" {
+		gap, err = strconv.Atoi(query.Get("gap"))
+		if err != nil {
+			http.Error(w, "Invalid gap parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	err = h.Task.AddDependency(r.Context(), query.Get("group_id"), query.Get("task_id"), query.Get("depends_on"), userLogin, gap)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary RemoveDependency
+// @Tags Tasks
+// @Description Remove dependency between tasks, only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param task_id query string true "task id"
+// @Param depends_on query string true "id of the task it depends on"
+// @Router /tasks/dependency/delete [delete]
+func (h *Handler) RemoveDependency(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	err := h.Task.RemoveDependency(r.Context(), query.Get("group_id"), query.Get("task_id"), query.Get("depends_on"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary ExportRoute
+// @Tags Tasks
+// @Description Export tasks with coordinates in chronological order as GeoJSON (points and a route line) or GPX (waypoints and a route)
+// @Security BearerAuth
+// @Produce  json
+// @Produce  xml
+// @Param group_id query string true "Id of group"
+// @Param format query string false "file format" Enums(geojson, gpx)
+// @Param from query string false "first day to export" example(2024-10-21)
+// @Param to query string false "last day to export" example(2024-10-28)
+// @Router /tasks/export [get]
+func (h *Handler) ExportRoute(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	filter := models.ItineraryFilter{
+		GroupID: query.Get("group_id"),
+		From:    query.Get("from"),
+		To:      query.Get("to"),
+	}
+	export, err := h.Task.ExportRoute(r.Context(), userLogin, query.Get("format"), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	_, err = w.Write(export.Data)
+	if err != nil {
+		logs.Error("failed to write export: %v", err)
+	}
+}
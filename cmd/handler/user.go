@@ -2,8 +2,15 @@ package handler
 
 import (
 	"JourneyPlanner/internal/models"
+	"JourneyPlanner/internal/service"
 	"encoding/json"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -52,8 +59,14 @@ func (h *Handler) SignIn(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
 		return
 	}
-	token, err := h.User.LoginUser(r.Context(), credentials.Option, credentials.Password)
+	token, err := h.User.LoginUser(r.Context(), credentials.Option, credentials.Password, clientIP(r))
 	if err != nil {
+		var lockedErr *service.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
@@ -65,3 +78,302 @@ func (h *Handler) SignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// clientIP trusts X-Forwarded-For only when the app runs behind a proxy that sets it
+func clientIP(r *http.Request) string {
+	if os.Getenv("TRUST_PROXY_HEADERS") == "true" {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// @Summary Logout
+// @Tags users
+// @Description Revoke current token
+// @Security BearerAuth
+// @Produce  json
+// @Router /auth/logout [post]
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	err := h.User.Logout(r.Context(), token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Logout everywhere
+// @Tags users
+// @Description Revoke all tokens issued to your account and delete its API keys
+// @Security BearerAuth
+// @Produce  json
+// @Router /auth/logoutAll [post]
+func (h *Handler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	err := h.User.LogoutEverywhere(r.Context(), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary GetProfile
+// @Tags users
+// @Description Get your account and profile
+// @Security BearerAuth
+// @Produce  json
+// @Router /users/profile [get]
+func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	user, err := h.User.GetProfile(r.Context(), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"user": user,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary GetUserInfo
+// @Tags users
+// @Description Get public profile of another user
+// @Security BearerAuth
+// @Produce  json
+// @Param login query string true "login of user"
+// @Router /users/info [get]
+func (h *Handler) GetUserInfo(w http.ResponseWriter, r *http.Request) {
+	login := r.URL.Query().Get("login")
+	profile, err := h.User.GetPublicProfile(r.Context(), login)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"user": profile,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary UpdateProfile
+// @Tags users
+// @Description Update profile, only sent fields are changed, send empty value to clear a field
+// @Security BearerAuth
+// @Produce  json
+// @Param display_name query string false "display name"
+// @Param avatar_url query string false "link to avatar image"
+// @Param timezone query string false "home timezone" example(Europe/Rome)
+// @Param locale query string false "locale" example(en-US)
+// @Param bio query string false "about you"
+// @Router /users/profile [put]
+func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	optional := func(key string) *string {
+		if !query.Has(key) {
+			return nil
+		}
+		value := strings.TrimSpace(query.Get(key))
+		return &value
+	}
+	profile := models.UpdateProfile{
+		DisplayName: optional("display_name"),
+		AvatarURL:   optional("avatar_url"),
+		Timezone:    optional("timezone"),
+		Locale:      optional("locale"),
+		Bio:         optional("bio"),
+	}
+	if profile.IsEmpty() {
+		http.Error(w, "No new details", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(profile); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	err := h.User.UpdateProfile(r.Context(), userLogin, profile)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary ChangeEmail
+// @Tags users
+// @Description Change email, the new email is used after confirming the link sent to it
+// @Security BearerAuth
+// @Produce  json
+// @Param email query string true "new email"
+// @Param password query string false "your password, not needed for OIDC accounts"
+// @Router /users/email [put]
+func (h *Handler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	email := strings.TrimSpace(r.URL.Query().Get("email"))
+	password := r.URL.Query().Get("password")
+	err := h.User.ChangeEmail(r.Context(), userLogin, email, password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Check your new email to confirm it")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	err := h.User.VerifyEmail(r.Context(), token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Email is confirmed")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary DeleteAccount
+// @Tags users
+// @Description Delete account, leave all groups and revoke invites, sessions and api keys. Accounts without a password get an email with a link to confirm it
+// @Security BearerAuth
+// @Produce  json
+// @Param password query string false "your password, not needed for OIDC accounts"
+// @Router /users/delete [delete]
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	password := r.URL.Query().Get("password")
+	sent, err := h.User.DeleteAccount(r.Context(), userLogin, password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !sent {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode("Check your email to confirm deleting the account")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+	}
+}
+
+func (h *Handler) ConfirmAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	err := h.User.ConfirmAccountDeletion(r.Context(), token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Account is deleted")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Get failed logins
+// @Tags users
+// @Description Get recent failed sign in attempts to your account
+// @Security BearerAuth
+// @Produce  json
+// @Router /users/failedlogins [get]
+func (h *Handler) GetFailedLogins(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	attempts, err := h.User.GetFailedLogins(r.Context(), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"failed_logins": attempts,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
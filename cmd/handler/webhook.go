@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// @Summary AddWebhook
+// @Tags Webhooks
+// @Description Subscribe a URL to events of the group, only for leader. The secret to check signatures is shown only once
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param url query string true "http or https URL receiving POST requests" example(https://example.com/hooks/trip)
+// @Param events query string true "comma separated events" example(task.created,poll.closed,member.joined)
+// @Router /webhooks/add [post]
+func (h *Handler) AddWebhook(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	var events []string
+	for _, event := range strings.Split(r.URL.Query().Get("events"), ",") {
+		event = strings.TrimSpace(event)
+		if event != "" {
+			events = append(events, event)
+		}
+	}
+	webhook, err := h.Webhook.AddWebhook(r.Context(), r.URL.Query().Get("group_id"), userLogin,
+		strings.TrimSpace(r.URL.Query().Get("url")), events)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(webhook)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary GetWebhooks
+// @Tags Webhooks
+// @Description Webhooks of the group, only for leader
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Router /webhooks/getlist [get]
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	webhooks, err := h.Webhook.GetWebhooks(r.Context(), r.URL.Query().Get("group_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"webhooks": webhooks,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary DeleteWebhook
+// @Tags Webhooks
+// @Description Delete the webhook, deliveries still being retried are dropped
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param webhook_id query string true "Id of webhook"
+// @Router /webhooks/delete [delete]
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	err := h.Webhook.DeleteWebhook(r.Context(), r.URL.Query().Get("group_id"), r.URL.Query().Get("webhook_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode("Done")
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary GetWebhookDeliveries
+// @Tags Webhooks
+// @Description Latest deliveries of the webhook with the response code of every attempt
+// @Security BearerAuth
+// @Produce  json
+// @Param group_id query string true "Id of group"
+// @Param webhook_id query string true "Id of webhook"
+// @Router /webhooks/deliveries [get]
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	deliveries, err := h.Webhook.GetDeliveries(r.Context(), r.URL.Query().Get("group_id"), r.URL.Query().Get("webhook_id"), userLogin)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := map[string]interface{}{
+		"deliveries": deliveries,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logs.Error("failed to encode JSON: %v", err)
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+		return
+	}
+}
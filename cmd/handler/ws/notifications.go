@@ -0,0 +1,132 @@
+package ws
+
+import (
+	"JourneyPlanner/internal/models"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// a connection that does not take a notification in this time is closed
+	notificationWriteWait = 10 * time.Second
+	// notifications waiting for a slow connection, it is closed when more come
+	notificationSendBuffer = 16
+)
+
+// NotificationsHandler keeps personal connections of users, they are not tied to any group.
+// A user can be connected from several devices, each of them gets every notification.
+// Every connection is written by its own goroutine, so a slow client does not hold up the others
+type NotificationsHandler struct {
+	Upgrader websocket.Upgrader
+	Clients  map[string]map[*notificationClient]bool
+	mu       sync.Mutex
+}
+
+type notificationClient struct {
+	conn *websocket.Conn
+	send chan models.Notification
+	// done is closed when the connection is removed
+	done chan struct{}
+}
+
+func NewNotificationsHandler() *NotificationsHandler {
+	return &NotificationsHandler{
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		Clients: make(map[string]map[*notificationClient]bool),
+	}
+}
+
+func (h *NotificationsHandler) HandleConnections(w http.ResponseWriter, r *http.Request) {
+	userLogin, ok := r.Context().Value(UserLoginKey).(string)
+	if !ok {
+		logs.Error("failed to get value from context")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	conn, err := h.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logs.Errorf("error during connecting:%v", err)
+		return
+	}
+	client := &notificationClient{
+		conn: conn,
+		send: make(chan models.Notification, notificationSendBuffer),
+		done: make(chan struct{}),
+	}
+	h.mu.Lock()
+	if h.Clients[userLogin] == nil {
+		h.Clients[userLogin] = make(map[*notificationClient]bool)
+	}
+	h.Clients[userLogin][client] = true
+	h.mu.Unlock()
+	go h.write(userLogin, client)
+
+	// the channel is one way, reading only notices when the client goes away
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	h.remove(userLogin, client)
+}
+
+// write sends the notifications of one connection until it is removed
+func (h *NotificationsHandler) write(userLogin string, client *notificationClient) {
+	for {
+		select {
+		case <-client.done:
+			return
+		case notification := <-client.send:
+			if err := client.conn.SetWriteDeadline(time.Now().Add(notificationWriteWait)); err != nil {
+				logs.Errorf("error sending notification: %v", err)
+				h.remove(userLogin, client)
+				return
+			}
+			if err := client.conn.WriteJSON(notification); err != nil {
+				logs.Errorf("error sending notification: %v", err)
+				h.remove(userLogin, client)
+				return
+			}
+		}
+	}
+}
+
+func (h *NotificationsHandler) remove(userLogin string, client *notificationClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.Clients[userLogin][client] {
+		return
+	}
+	delete(h.Clients[userLogin], client)
+	if len(h.Clients[userLogin]) == 0 {
+		delete(h.Clients, userLogin)
+	}
+	close(client.done)
+	if err := client.conn.Close(); err != nil {
+		logs.Errorf("failed to close connection: %v", err)
+	}
+}
+
+// Push queues the notification for every connection of the user without waiting for the network,
+// a connection that fell too far behind is closed and the client reloads the inbox when it reconnects
+func (h *NotificationsHandler) Push(userLogin string, notification models.Notification) {
+	h.mu.Lock()
+	var slow []*notificationClient
+	for client := range h.Clients[userLogin] {
+		select {
+		case client.send <- notification:
+		default:
+			slow = append(slow, client)
+		}
+	}
+	h.mu.Unlock()
+	for _, client := range slow {
+		logs.Errorf("notification connection of %s is too slow, closing it", userLogin)
+		h.remove(userLogin, client)
+	}
+}
@@ -3,9 +3,11 @@ package ws
 import (
 	"JourneyPlanner/cmd/handler"
 	"JourneyPlanner/internal/models"
+	"JourneyPlanner/internal/service"
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -129,11 +131,16 @@ func (h *WebSocketHandler) HandleConnections(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// broadcastMessage sends the message to the members of its group who are connected
 func (h *WebSocketHandler) broadcastMessage(msg models.Message) {
 	message := fmt.Sprintf("%v: %v\t %v", msg.User, msg.Content, msg.Time.Format("2006-01-02 15:04:05"))
+	suffix := "_" + msg.GroupID
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	for connKey, conn := range h.Clients {
+		if !strings.HasSuffix(connKey, suffix) {
+			continue
+		}
 		err := conn.WriteMessage(websocket.TextMessage, []byte(message))
 		if err != nil {
 			logs.Errorf("error sending message: %v", err)
@@ -146,7 +153,22 @@ func (h *WebSocketHandler) broadcastMessage(msg models.Message) {
 	}
 }
 
-func (h *WebSocketHandler) NotifyUserDisconnect(userLogin, groupID string) {
+// BroadcastSystemMessage pushes a message posted by the application, it is already saved to the history
+func (h *WebSocketHandler) BroadcastSystemMessage(msg models.Message) {
+	h.broadcastMessage(msg)
+}
+
+// Subscribe closes the chat of members who left the group or were banned from it
+func (h *WebSocketHandler) Subscribe(bus *service.EventBus) {
+	service.Subscribe(bus, func(ctx context.Context, event models.MemberLeft) {
+		h.disconnectUser(event.Login, event.Group.ID.Hex())
+	})
+	service.Subscribe(bus, func(ctx context.Context, event models.MemberBanned) {
+		h.disconnectUser(event.Login, event.Group.ID.Hex())
+	})
+}
+
+func (h *WebSocketHandler) disconnectUser(userLogin, groupID string) {
 	connKey := fmt.Sprintf("%s_%s", userLogin, groupID)
 
 	h.mu.Lock()
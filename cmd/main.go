@@ -4,20 +4,40 @@ import (
 	"JourneyPlanner/cmd/config"
 	"JourneyPlanner/cmd/handler"
 	"JourneyPlanner/cmd/handler/ws"
+	"JourneyPlanner/internal/models"
 	mongorepo "JourneyPlanner/internal/repository/mongo"
 	"JourneyPlanner/internal/service"
 	"JourneyPlanner/internal/service/chat"
 	logger "JourneyPlanner/pkg/log"
+	"JourneyPlanner/pkg/mail"
 	"context"
+	"errors"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 )
 
 const (
 	RWTimeout   = 10
 	IdleTimeout = 60
+	// requests in progress get this time to finish on shutdown
+	ShutdownTimeout = 30
+)
+
+// how often periodic background jobs run
+const (
+	missedPollsInterval    = 10 * time.Minute
+	purgeInvitesInterval   = time.Hour
+	purgeTokensInterval    = time.Hour
+	digestsInterval        = time.Hour
+	purgeOutboxInterval    = time.Hour
+	purgeRevisionsInterval = 24 * time.Hour
 )
 
 // @title Journer Planner
@@ -40,6 +60,7 @@ func main() {
 		}
 	}()
 	dbclient := mongorepo.CreateMongoClient(ctx)
+	mongorepo.CreateIndexes(ctx, dbclient)
 	userRepo := mongorepo.NewMongoUserRepo(dbclient)
 	taskRepo := mongorepo.NewMongoTaskRepo(dbclient)
 	pollRepo := mongorepo.NewMongoPollRepo(dbclient)
@@ -47,28 +68,149 @@ func main() {
 	inviteRepo := mongorepo.NewMongoInviteRepo(dbclient)
 	blacklistRepo := mongorepo.NewMongoBlacklistRepo(dbclient)
 	chatRepo := mongorepo.NewChatRepository(dbclient)
+	oidcRepo := mongorepo.NewMongoOIDCRepo(dbclient)
+	apiKeyRepo := mongorepo.NewMongoAPIKeyRepo(dbclient)
+	tokenRepo := mongorepo.NewMongoTokenRepo(dbclient)
+	contactRepo := mongorepo.NewMongoContactRepo(dbclient)
+	activityRepo := mongorepo.NewMongoActivityRepo(dbclient)
+	proposalRepo := mongorepo.NewMongoProposalRepo(dbclient)
+	reminderRepo := mongorepo.NewMongoReminderRepo(dbclient)
+	notificationRepo := mongorepo.NewMongoNotificationRepo(dbclient)
+	notifySettingsRepo := mongorepo.NewMongoNotificationSettingsRepo(dbclient)
+	webhookRepo := mongorepo.NewMongoWebhookRepo(dbclient)
+	outboxRepo := mongorepo.NewMongoOutboxRepo(dbclient)
+	auditRepo := mongorepo.NewMongoAuditRepo(dbclient)
+	revisionRepo := mongorepo.NewMongoRevisionRepo(dbclient)
+	transactor := mongorepo.NewMongoTransactor(dbclient)
+	mailer := mail.NewMailerFromEnv(logs.Sugar())
 	
-	chatService := chat.NewChatService(chatRepo)
-	userSrv := service.NewUserSrv(userRepo)
-	pollSrv := service.NewPollSrv(pollRepo, groupRepo)
-	taskSrv := service.NewTaskSrv(taskRepo, groupRepo)
-	groupSrv := service.NewGroupSrv(groupRepo, userRepo, inviteRepo, blacklistRepo)
+	travelSpeeds, err := service.NewTravelSpeedsFromEnv()
+	if err != nil {
+		logs.Sugar().Fatal(err)
+	}
+	jobs := service.NewJobScheduler(mongorepo.NewMongoJobRepo(dbclient), mongorepo.NewMongoLeaseRepo(dbclient),
+		service.DefaultRetryPolicy)
+	events := service.NewEventBus()
+	relay := service.NewOutboxRelay(outboxRepo, mongorepo.NewMongoLeaseRepo(dbclient), events)
+	outboxRepo.Written = relay.Wake
+	transactor.Committed = relay.Wake
+	// events of the services go through the audit service, it saves their audit entries in the same transaction
+	auditSrv := service.NewAuditSrv(auditRepo, groupRepo, outboxRepo)
+	webhookSrv := service.NewWebhookSrv(webhookRepo, groupRepo, jobs, service.DefaultRetryPolicy.MaxAttempts)
+	chatService := chat.NewChatService(chatRepo, outboxRepo, transactor)
+	notificationSrv := service.NewNotificationSrv(notificationRepo, notifySettingsRepo, groupRepo, userRepo, mailer, jobs)
+	digestSrv := service.NewDigestSrv(notifySettingsRepo, notificationRepo, userRepo, groupRepo, taskRepo, pollRepo,
+		chatRepo, mailer)
+	reminderSrv := service.NewReminderSrv(taskRepo, groupRepo, reminderRepo, notificationSrv, chatService, jobs)
+	taskSrv := service.NewTaskSrv(taskRepo, groupRepo, travelSpeeds, reminderSrv, revisionRepo, auditSrv, transactor)
+	apiKeySrv := service.NewAPIKeySrv(apiKeyRepo)
+	activitySrv := service.NewActivitySrv(activityRepo, groupRepo, taskRepo, taskSrv, travelSpeeds, transactor)
+	proposalSrv := service.NewProposalSrv(proposalRepo, groupRepo, taskRepo, taskSrv, reminderSrv, revisionRepo, auditSrv, transactor)
+	groupSrv := service.NewGroupSrv(groupRepo, userRepo, inviteRepo, blacklistRepo, taskRepo, auditSrv, transactor)
+	pollSrv := service.NewPollSrv(pollRepo, groupRepo, taskSrv, proposalSrv, groupSrv, jobs, auditSrv, transactor)
+	userSrv := service.NewUserSrv(userRepo, tokenRepo, groupRepo, groupSrv, inviteRepo,
+		blacklistRepo, apiKeyRepo, contactRepo, mailer, newLoginProtection(dbclient), notificationRepo,
+		notifySettingsRepo, reminderRepo)
+	contactSrv := service.NewContactSrv(contactRepo, userRepo, groupRepo, blacklistRepo)
+	oidcSrv := service.NewOIDCSrv(userRepo, oidcRepo, service.NewOIDCProviderFromEnv(), userSrv)
 	wsHandler := ws.NewWebSocketHandler(chatService, groupSrv)
-	groupSrv.NotifyUserDisconnect = wsHandler.NotifyUserDisconnect
+	reminderSrv.Broadcast = wsHandler.BroadcastSystemMessage
+	notificationsWs := ws.NewNotificationsHandler()
+	notificationSrv.Push = notificationsWs.Push
+	notificationSrv.Subscribe(events)
+	webhookSrv.Subscribe(events)
+	wsHandler.Subscribe(events)
+
+	registerJobs(jobs, pollSrv, groupSrv, userSrv, taskSrv, reminderSrv, notificationSrv, digestSrv, webhookSrv, relay)
+	// on SIGINT or SIGTERM the jobs and the relay stop and give their leases away, so another replica takes over at once
+	runCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var background sync.WaitGroup
+	background.Add(3)
+	go func() {
+		defer background.Done()
+		jobs.Run(runCtx)
+	}()
+	go func() {
+		defer background.Done()
+		relay.Run(runCtx)
+	}()
+	// every replica pushes the new notifications to its own sockets, whichever replica saved them
+	go func() {
+		defer background.Done()
+		notificationSrv.RunPush(runCtx)
+	}()
 
-	handler := handler.NewHandler(pollSrv, taskSrv, userSrv, groupSrv)
+	handler := handler.NewHandler(pollSrv, taskSrv, userSrv, groupSrv, oidcSrv, apiKeySrv, contactSrv, activitySrv, proposalSrv,
+		reminderSrv, notificationSrv, webhookSrv, auditSrv)
 	logs.Sugar().Info("Server is now listening 8080...")
 	srv := &http.Server{
 		Addr:         ":8080",
-		Handler:      handler.InitRoutes(wsHandler),
+		Handler:      handler.InitRoutes(wsHandler, notificationsWs),
 		ReadTimeout:  RWTimeout * time.Second,
 		WriteTimeout: RWTimeout * time.Second,
 		IdleTimeout:  IdleTimeout * time.Second,
 	}
-	err := srv.ListenAndServe()
-	if err != nil {
-		logs.Sugar().Fatal("Server error", zap.Error(err))
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	select {
+	case err = <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			logs.Sugar().Fatal("Server error", zap.Error(err))
+		}
+	case <-runCtx.Done():
+		logs.Sugar().Info("Shutting down...")
+		shutdownCtx, cancel := context.WithTimeout(ctx, ShutdownTimeout*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logs.Error("Server shutdown error", zap.Error(err))
+		}
 	}
+	stop()
+	background.Wait()
+}
+
+// LOGIN_LIMITER=memory keeps failed attempts counters in process, it is enough for a single node
+func newLoginProtection(dbclient *mongo.Client) service.LoginProtection {
+	protection := service.LoginProtection{Audit: mongorepo.NewMongoLoginAuditRepo(dbclient)}
+	if os.Getenv("LOGIN_LIMITER") == "memory" {
+		protection.Account = service.NewMemoryLoginLimiter(service.AccountLockoutPolicy)
+		protection.IP = service.NewMemoryLoginLimiter(service.IPLockoutPolicy)
+	} else {
+		protection.Account = mongorepo.NewMongoLoginLimiter(dbclient, service.AccountLockoutPolicy)
+		protection.IP = mongorepo.NewMongoLoginLimiter(dbclient, service.IPLockoutPolicy)
+	}
+	return protection
+}
+
+// periodic jobs run on one replica at a time, the others keep them in reserve
+func registerJobs(jobs *service.JobScheduler, pollSrv *service.PollSrv, groupSrv *service.GroupSrv, userSrv *service.UserSrv,
+	taskSrv *service.TaskSrv, reminderSrv *service.ReminderSrv, notificationSrv *service.NotificationSrv, digestSrv *service.DigestSrv,
+	webhookSrv *service.WebhookSrv, relay *service.OutboxRelay) {
+	jobs.Register(models.JobFinalizePoll, pollSrv.FinalizePollJob)
+	jobs.Register(models.JobTaskReminder, reminderSrv.SendTaskReminders)
+	jobs.Register(models.JobNotificationEmail, notificationSrv.SendNotificationEmail)
+	jobs.Register(models.JobWebhookDelivery, webhookSrv.DeliverWebhook)
+	jobs.Every(models.JobFinalizePolls, missedPollsInterval, func(ctx context.Context, _ models.Job) error {
+		return pollSrv.FinalizePolls(ctx)
+	})
+	jobs.Every(models.JobPurgeInvites, purgeInvitesInterval, func(ctx context.Context, _ models.Job) error {
+		return groupSrv.PurgeInvites(ctx)
+	})
+	jobs.Every(models.JobPurgeRevokedTokens, purgeTokensInterval, func(ctx context.Context, _ models.Job) error {
+		return userSrv.PurgeRevokedTokens(ctx)
+	})
+	jobs.Every(models.JobSendDigests, digestsInterval, func(ctx context.Context, _ models.Job) error {
+		return digestSrv.SendDigests(ctx)
+	})
+	jobs.Every(models.JobPurgeOutbox, purgeOutboxInterval, func(ctx context.Context, _ models.Job) error {
+		return relay.PurgeDispatched(ctx)
+	})
+	jobs.Every(models.JobPurgeRevisions, purgeRevisionsInterval, func(ctx context.Context, _ models.Job) error {
+		return taskSrv.PurgeRevisions(ctx)
+	})
 }
 
 func setUpProjectLogger(logger *zap.Logger) {
@@ -15,91 +15,185 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/signIn": {
+        "/activities/accept": {
             "post": {
-                "description": "Authorization to the account",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turn a proposed plan into tasks exactly as it was shown, only for leader. All tasks are created or none",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "activities"
                 ],
-                "summary": "SignIn",
+                "summary": "AcceptPlan",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "your login or email",
-                        "name": "option",
+                        "description": "Id of group",
+                        "name": "group_id",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "your password",
-                        "name": "password",
+                        "description": "Id of the plan",
+                        "name": "plan_id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated ids to accept, the whole plan if empty",
+                        "name": "activity_ids",
+                        "in": "query"
                     }
                 ],
                 "responses": {}
             }
         },
-        "/auth/singUp": {
+        "/activities/add": {
             "post": {
-                "description": "Create account",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add an activity to the wishlist of the group, the scheduler will find time for it later",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "activities"
                 ],
-                "summary": "SignUp",
+                "summary": "AddActivity",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "your login",
-                        "name": "login",
+                        "description": "Id of group",
+                        "name": "group_id",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "your password",
-                        "name": "password",
+                        "description": "Activity title",
+                        "name": "title",
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2,
+                        "name": "hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 30,
+                        "name": "minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "from 1 to 5, default 3",
+                        "name": "priority",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "your email",
-                        "name": "email",
-                        "in": "query",
-                        "required": true
+                        "example": "2024-10-21",
+                        "description": "earliest day",
+                        "name": "not_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-25",
+                        "description": "latest day",
+                        "name": "not_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "09:00",
+                        "description": "opening time in trip timezone",
+                        "name": "opens",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "18:00",
+                        "description": "closing time in trip timezone",
+                        "name": "closes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the place",
+                        "name": "location_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "address of the place",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 41.9028,
+                        "description": "latitude",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 12.4964,
+                        "description": "longitude",
+                        "name": "lon",
+                        "in": "query"
                     }
                 ],
                 "responses": {}
             }
         },
-        "/groups/add": {
-            "post": {
+        "/activities/delete": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create new group",
+                "description": "Remove an activity from the wishlist, only for leader or the member who added it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "activities"
                 ],
-                "summary": "AddGroup",
+                "summary": "DeleteActivity",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "name of group",
-                        "name": "name",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of activity",
+                        "name": "activity_id",
                         "in": "query",
                         "required": true
                     }
@@ -107,114 +201,141 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/groups/ban": {
-            "put": {
+        "/activities/getlist": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Kick and ban member from group",
+                "description": "Get the wishlist of the group",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "blacklist"
+                    "activities"
                 ],
-                "summary": "BanMember",
+                "summary": "GetActivities",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
+                        "description": "Id of group",
                         "name": "group_id",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "login of member",
-                        "name": "memberLogin",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {}
             }
         },
-        "/groups/blacklist": {
+        "/activities/plan": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get blacklist of group",
+                "description": "Propose a schedule for the wishlist within free time of the trip, keeping time to travel between places. No task is created, the plan is kept for an hour to be accepted by its id",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "blacklist"
+                    "activities"
                 ],
-                "summary": "Get group blacklist",
+                "summary": "PlanActivities",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
+                        "description": "Id of group",
                         "name": "group_id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "skip 23:00-07:00 of trip local time",
+                        "name": "quiet_hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "least minutes to keep free between tasks",
+                        "name": "buffer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "travel mode: walk, bike, transit, car (default), train",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {}
             }
         },
-        "/groups/declineinvite": {
+        "/apikeys/add": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Decline invite",
+                "description": "Create personal API key for scripts and bots. The key is shown only once",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invites"
+                    "apikeys"
                 ],
-                "summary": "Decline invite",
+                "summary": "Create API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Id of invite",
-                        "name": "invite_id",
+                        "description": "name of key",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "tasks:read,polls:write,chat",
+                        "description": "comma separated scopes",
+                        "name": "scopes",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "maximum": 365,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "days until the key expires, 90 by default",
+                        "name": "expires_in_days",
+                        "in": "query"
                     }
                 ],
                 "responses": {}
             }
         },
-        "/groups/delete": {
+        "/apikeys/delete": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete group by id",
+                "description": "Revoke API key",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "apikeys"
                 ],
-                "summary": "DeleteGroup",
+                "summary": "Delete API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
-                        "name": "group_id",
+                        "description": "id of key",
+                        "name": "key_id",
                         "in": "query",
                         "required": true
                     }
@@ -222,112 +343,82 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/groups/getgroupinfo": {
+        "/apikeys/getlist": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get full info about group you are a member of",
+                "description": "Get list of your API keys",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
-                ],
-                "summary": "GetGroupInfo",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "id of group",
-                        "name": "group_id",
-                        "in": "query",
-                        "required": true
-                    }
+                    "apikeys"
                 ],
+                "summary": "Get API keys",
                 "responses": {}
             }
         },
-        "/groups/getlist": {
-            "get": {
+        "/auth/logout": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of all the groups you are a member of",
+                "description": "Revoke current token",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "users"
                 ],
-                "summary": "GetGroups",
+                "summary": "Logout",
                 "responses": {}
             }
         },
-        "/groups/givelead": {
-            "put": {
+        "/auth/logoutAll": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Give another member of group leader role",
+                "description": "Revoke all tokens issued to your account and delete its API keys",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
-                ],
-                "summary": "GiveLeaderRole",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "member login",
-                        "name": "user_login",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "id of group",
-                        "name": "group_id",
-                        "in": "query",
-                        "required": true
-                    }
+                    "users"
                 ],
+                "summary": "Logout everywhere",
                 "responses": {}
             }
         },
-        "/groups/invite": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Invite user to group",
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Finish sign in with the external provider.\nReturns a token, or a registration ticket if there is no account with this email yet",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "invites"
+                    "users"
                 ],
-                "summary": "Invite user to group",
+                "summary": "OIDC callback",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
-                        "name": "group_id",
+                        "description": "authorization code",
+                        "name": "code",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "invited user",
-                        "name": "user_login",
+                        "description": "login state",
+                        "name": "state",
                         "in": "query",
                         "required": true
                     }
@@ -335,44 +426,38 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/groups/invitelist": {
+        "/auth/oidc/login": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get your list of invites",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Start sign in with the external OpenID Connect provider, redirects to the provider",
                 "tags": [
-                    "invites"
+                    "users"
                 ],
-                "summary": "Get invite list",
+                "summary": "OIDC login",
                 "responses": {}
             }
         },
-        "/groups/leaveGroup": {
+        "/auth/oidc/signUp": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Leave from group",
+                "description": "Create account for the identity from OIDC callback with chosen login",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "users"
                 ],
-                "summary": "LeaveFromGroup",
+                "summary": "OIDC SignUp",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
-                        "name": "group_id",
+                        "description": "registration ticket",
+                        "name": "ticket",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "your login",
+                        "name": "login",
                         "in": "query",
                         "required": true
                     }
@@ -380,33 +465,28 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/groups/unban": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Unban member in group",
+        "/auth/signIn": {
+            "post": {
+                "description": "Authorization to the account",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "blacklist"
+                    "users"
                 ],
-                "summary": "UnbanMember",
+                "summary": "SignIn",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
-                        "name": "group_id",
+                        "description": "your login or email",
+                        "name": "option",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "login of member",
-                        "name": "memberLogin",
+                        "description": "your password",
+                        "name": "password",
                         "in": "query",
                         "required": true
                     }
@@ -414,88 +494,62 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/polls/add": {
+        "/auth/singUp": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create new poll",
+                "description": "Create account",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "polls"
+                    "users"
                 ],
-                "summary": "CreatePoll",
+                "summary": "SignUp",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
-                        "name": "groupID",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "title of poll",
-                        "name": "title",
+                        "description": "your login",
+                        "name": "login",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "first option ",
-                        "name": "firstOption",
+                        "description": "your password",
+                        "name": "password",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "second option",
-                        "name": "sercondOption",
+                        "description": "your email",
+                        "name": "email",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "minimum": 0,
-                        "type": "integer",
-                        "description": "duration of poll in minutes",
-                        "name": "duration",
-                        "in": "query"
                     }
                 ],
                 "responses": {}
             }
         },
-        "/polls/close": {
-            "put": {
+        "/contacts/accept": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Close poll for voting",
+                "description": "Accept incoming friend request",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "polls"
+                    "contacts"
                 ],
-                "summary": "Close Poll",
+                "summary": "Accept friend request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
-                        "name": "groupID",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "id of poll",
-                        "name": "pollID",
+                        "description": "login of user",
+                        "name": "login",
                         "in": "query",
                         "required": true
                     }
@@ -503,33 +557,26 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/polls/delete": {
-            "delete": {
+        "/contacts/block": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete poll by id",
+                "description": "Block user, blocked users cant send you friend requests and dont see you in search",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "polls"
+                    "contacts"
                 ],
-                "summary": "Delete Poll",
+                "summary": "Block user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
-                        "name": "groupID",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "id of poll",
-                        "name": "pollID",
+                        "description": "login of user",
+                        "name": "login",
                         "in": "query",
                         "required": true
                     }
@@ -537,26 +584,26 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/polls/getlist": {
-            "get": {
+        "/contacts/decline": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get list of polls",
+                "description": "Decline incoming friend request",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "polls"
+                    "contacts"
                 ],
-                "summary": "GetPolls",
+                "summary": "Decline friend request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of group",
-                        "name": "groupID",
+                        "description": "login of user",
+                        "name": "login",
                         "in": "query",
                         "required": true
                     }
@@ -564,44 +611,44 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/polls/vote": {
-            "put": {
+        "/contacts/getlist": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Vote for poll option",
+                "description": "Get your friends, friend requests and blocked users",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "polls"
+                    "contacts"
                 ],
-                "summary": "Vote Poll",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "id of group",
-                        "name": "groupID",
-                        "in": "query",
-                        "required": true
-                    },
+                "summary": "Get contacts",
+                "responses": {}
+            }
+        },
+        "/contacts/remove": {
+            "delete": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "id of poll",
-                        "name": "pollID",
-                        "in": "query",
-                        "required": true
-                    },
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove user from friends or cancel your friend request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contacts"
+                ],
+                "summary": "Remove contact",
+                "parameters": [
                     {
-                        "enum": [
-                            "firstOption",
-                            "secondOption"
-                        ],
                         "type": "string",
-                        "description": "vote option",
-                        "name": "option",
+                        "description": "login of user",
+                        "name": "login",
                         "in": "query",
                         "required": true
                     }
@@ -609,97 +656,53 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/tasks/add": {
+        "/contacts/request": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create new task",
+                "description": "Send friend request to user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "contacts"
                 ],
-                "summary": "AddTask",
+                "summary": "Send friend request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Id of group",
-                        "name": "group_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Task Details",
-                        "name": "title",
+                        "description": "login of user",
+                        "name": "login",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "example": "2024-10-21",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "example": "14:00",
-                        "name": "start_time",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "example": 0,
-                        "name": "days",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "example": 2,
-                        "name": "hours",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "example": 30,
-                        "name": "minutes",
-                        "in": "query"
                     }
                 ],
                 "responses": {}
             }
         },
-        "/tasks/delete": {
-            "delete": {
+        "/contacts/unblock": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete existing task",
+                "description": "Unblock user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "contacts"
                 ],
-                "summary": "DeleteTask",
+                "summary": "Unblock user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Id of group",
-                        "name": "group_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Id of group",
-                        "name": "task_id",
+                        "description": "login of user",
+                        "name": "login",
                         "in": "query",
                         "required": true
                     }
@@ -707,26 +710,26 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/tasks/getlist": {
-            "get": {
+        "/groups/add": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create new task",
+                "description": "Create new group",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "groups"
                 ],
-                "summary": "GetTasks",
+                "summary": "AddGroup",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Id of group",
-                        "name": "group_id",
+                        "description": "name of group",
+                        "name": "name",
                         "in": "query",
                         "required": true
                     }
@@ -734,21 +737,21 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/tasks/update": {
-            "put": {
+        "/groups/audit": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "update existing task",
+                "description": "Who did what in the group and when, newest first. Task changes have the fields before and after the change. Only the leader can see it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Groups"
                 ],
-                "summary": "UpdateTask",
+                "summary": "GetAuditLog",
                 "parameters": [
                     {
                         "type": "string",
@@ -759,46 +762,2882 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "task id",
-                        "name": "task_id",
-                        "in": "query",
-                        "required": true
+                        "description": "login of who did it",
+                        "name": "actor",
+                        "in": "query"
                     },
                     {
+                        "enum": [
+                            "member.invited",
+                            "member.joined",
+                            "member.left",
+                            "member.banned",
+                            "member.unbanned",
+                            "leader.changed",
+                            "task.created",
+                            "task.updated",
+                            "task.deleted",
+                            "poll.created",
+                            "poll.closed",
+                            "poll.deleted"
+                        ],
                         "type": "string",
-                        "description": "Task Details",
-                        "name": "title",
+                        "description": "action",
+                        "name": "action",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "example": "2024-10-21",
-                        "name": "start_date",
+                        "example": "2024-10-21T00:00:00Z",
+                        "description": "entries from this time",
+                        "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "example": "14:00",
-                        "name": "start_time",
+                        "example": "2024-10-28T00:00:00Z",
+                        "description": "entries until this time",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "example": 0,
-                        "name": "days",
+                        "example": 1,
+                        "description": "page number, starts with 1",
+                        "name": "page",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "example": 2,
-                        "name": "hours",
+                        "example": 50,
+                        "description": "entries per page, up to 100",
+                        "name": "limit",
                         "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/audit/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download the audit log of the group as CSV or JSON, with the same filters as the list. Only the leader can do it",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "ExportAuditLog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "type": "integer",
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "login of who did it",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "member.invited",
+                            "member.joined",
+                            "member.left",
+                            "member.banned",
+                            "member.unbanned",
+                            "leader.changed",
+                            "task.created",
+                            "task.updated",
+                            "task.deleted",
+                            "poll.created",
+                            "poll.closed",
+                            "poll.deleted"
+                        ],
+                        "type": "string",
+                        "description": "action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21T00:00:00Z",
+                        "description": "entries from this time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-28T00:00:00Z",
+                        "description": "entries until this time",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/ban": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Kick and ban member from group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blacklist"
+                ],
+                "summary": "BanMember",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "login of member",
+                        "name": "memberLogin",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/blacklist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get blacklist of group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blacklist"
+                ],
+                "summary": "Get group blacklist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/declineinvite": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Decline invite",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Decline invite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of invite",
+                        "name": "invite_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete group by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "DeleteGroup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/getgroupinfo": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get full info about group you are a member of",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "GetGroupInfo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/getlist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all the groups you are a member of",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "GetGroups",
+                "parameters": [
+                    {
+                        "enum": [
+                            "planning",
+                            "booked",
+                            "ongoing",
+                            "finished"
+                        ],
+                        "type": "string",
+                        "description": "trip status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21",
+                        "description": "only trips that end on or after this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-28",
+                        "description": "only trips that start on or before this date",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "start_date",
+                            "status",
+                            "name"
+                        ],
+                        "type": "string",
+                        "description": "sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/givelead": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Give another member of group leader role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "GiveLeaderRole",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "member login",
+                        "name": "user_login",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/invite": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Invite user to group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Invite user to group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "invited user",
+                        "name": "user_login",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/invitelist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get your list of invites",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Get invite list",
+                "responses": {}
+            }
+        },
+        "/groups/invitesuggestions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get contacts and past co-travellers that can be invited to the group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Get invite suggestions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/leaveGroup": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Leave from group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "LeaveFromGroup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/moderator/add": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Give member the moderator role, moderators review task proposals. Only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "AddModerator",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "member login",
+                        "name": "user_login",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/moderator/remove": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take the moderator role from member, only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "RemoveModerator",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "member login",
+                        "name": "user_login",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/myreminders": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set your own reminder time in the group instead of the group one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "SetMyReminder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 60,
+                        "description": "minutes before the task, 0 turns your reminders off",
+                        "name": "minutes",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Go back to the reminder time of the group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "ResetMyReminder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/reminders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "How long before tasks the group and the user are reminded, 0 means reminders are off",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "GetReminders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set how long before tasks members are reminded in the group chat and their notifications, only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "SetGroupReminder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 30,
+                        "description": "minutes before the task, 0 turns reminders off",
+                        "name": "minutes",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/trip": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update trip details of the group, only sent fields are changed, send empty value to clear a field. Only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "UpdateTrip",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "description of the trip",
+                        "name": "description",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Rome,Florence",
+                        "description": "comma separated destinations",
+                        "name": "destinations",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21",
+                        "description": "first day of the trip",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-28",
+                        "description": "last day of the trip",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "link to cover image",
+                        "name": "cover_image",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "planning",
+                            "booked",
+                            "ongoing",
+                            "finished"
+                        ],
+                        "type": "string",
+                        "description": "trip status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Europe/Rome",
+                        "description": "trip timezone, UTC if empty",
+                        "name": "timezone",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/groups/unban": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Unban member in group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blacklist"
+                ],
+                "summary": "UnbanMember",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "login of member",
+                        "name": "memberLogin",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notifications/digest": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get one email a day with upcoming tasks, polls closing soon, mentions and notifications instead of emails at once, it is sent at the chosen hour in the timezone of your profile",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "SetDigest",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "true to get the daily digest",
+                        "name": "enabled",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "local hour the digest is sent at, 0-23, 8 by default",
+                        "name": "hour",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notifications/getlist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Your notifications, newest first, with the number of unread ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "GetNotifications",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "page number, starts with 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 20,
+                        "description": "notifications per page, up to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notifications/preference": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Choose how notifications of a kind are delivered, in one group or, without group_id, in all groups",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "SetNotificationPreference",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "invite, ban, leader_changed, poll_created, poll_result, task_changed, task_reminder or proposal_rejected",
+                        "name": "kind",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "in_app, email, both or none",
+                        "name": "delivery",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a preference, the one for all groups or in-app delivery applies again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "ResetNotificationPreference",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "kind of notifications",
+                        "name": "kind",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notifications/read": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark one notification as read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "MarkNotificationRead",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of notification",
+                        "name": "notification_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notifications/readall": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark all your notifications as read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "MarkAllNotificationsRead",
+                "responses": {}
+            }
+        },
+        "/notifications/settings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Your delivery preferences and whether you get a daily digest instead of emails at once",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "GetNotificationSettings",
+                "responses": {}
+            }
+        },
+        "/polls/add": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create new poll",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "polls"
+                ],
+                "summary": "CreatePoll",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "groupID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "title of poll",
+                        "name": "title",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "first option ",
+                        "name": "firstOption",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "second option",
+                        "name": "sercondOption",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "duration of poll in minutes",
+                        "name": "duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "{\"kind\":\"task\",\"task\":{\"title\":\"Museum\",\"start_time\":{\"start_date\":\"2024-10-21\",\"start_time\":\"10:00\"},\"duration\":{\"hours\":3}}}",
+                        "description": "JSON action run if the first option wins: kind task (with task as in AddTask), trip (with trip fields as in UpdateTrip) or leader (with login)",
+                        "name": "firstAction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "{\"kind\":\"leader\",\"leader\":\"anna\"}",
+                        "description": "JSON action run if the second option wins",
+                        "name": "secondAction",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/polls/close": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Close poll for voting. If the winning option has an action, it is run and its result is returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "polls"
+                ],
+                "summary": "Close Poll",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "groupID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of poll",
+                        "name": "pollID",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/polls/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete poll by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "polls"
+                ],
+                "summary": "Delete Poll",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "groupID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of poll",
+                        "name": "pollID",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/polls/getlist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get list of polls",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "polls"
+                ],
+                "summary": "GetPolls",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "groupID",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/polls/vote": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Vote for poll option",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "polls"
+                ],
+                "summary": "Vote Poll",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of group",
+                        "name": "groupID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of poll",
+                        "name": "pollID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "firstOption",
+                            "secondOption"
+                        ],
+                        "type": "string",
+                        "description": "vote option",
+                        "name": "option",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/proposals/add": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Suggest a task to the group, it is checked like a new task and created when the leader or a moderator approves it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proposals"
+                ],
+                "summary": "ProposeTask",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task Details",
+                        "name": "title",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "14:00",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2,
+                        "name": "hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 30,
+                        "name": "minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the place",
+                        "name": "location_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "address of the place",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 41.9028,
+                        "description": "latitude",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 12.4964,
+                        "description": "longitude",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "FREQ=DAILY;INTERVAL=2",
+                        "description": "recurrence rule: FREQ=DAILY|WEEKLY with INTERVAL, COUNT, UNTIL, BYDAY",
+                        "name": "rrule",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "why the task is worth it",
+                        "name": "comment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/proposals/approve": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create the proposed task, for leader and moderators. The task is checked again against current tasks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proposals"
+                ],
+                "summary": "ApproveProposal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "proposal id",
+                        "name": "proposal_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "message to the author",
+                        "name": "reason",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/proposals/getlist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Leader and moderators get the review queue (pending proposals by default), other members get their own proposals with decisions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proposals"
+                ],
+                "summary": "GetProposals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "filter by status, all for any status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/proposals/reject": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reject the proposal with a reason for its author, for leader and moderators",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proposals"
+                ],
+                "summary": "RejectProposal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "proposal id",
+                        "name": "proposal_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "why the proposal is rejected",
+                        "name": "reason",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/proposals/update": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change a pending proposal, for its author, leader and moderators. Only sent fields are changed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proposals"
+                ],
+                "summary": "EditProposal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "proposal id",
+                        "name": "proposal_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task Details",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "14:00",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2,
+                        "name": "hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 30,
+                        "name": "minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the place",
+                        "name": "location_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "address of the place",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 41.9028,
+                        "description": "latitude",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 12.4964,
+                        "description": "longitude",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "FREQ=DAILY;INTERVAL=2",
+                        "description": "recurrence rule",
+                        "name": "rrule",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/add": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create new task",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "AddTask",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task Details",
+                        "name": "title",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "14:00",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2,
+                        "name": "hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 30,
+                        "name": "minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the place",
+                        "name": "location_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "address of the place",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 41.9028,
+                        "description": "latitude",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 12.4964,
+                        "description": "longitude",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "FREQ=DAILY;INTERVAL=2",
+                        "description": "recurrence rule: FREQ=DAILY|WEEKLY with INTERVAL, COUNT, UNTIL, BYDAY",
+                        "name": "rrule",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/checklist/add": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add item to the checklist of the task, only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "AddChecklistItem",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "what has to be done",
+                        "name": "title",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "login of the member responsible for the item",
+                        "name": "assignee",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/checklist/assign": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the member responsible for the checklist item, only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "AssignChecklistItem",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checklist item id",
+                        "name": "item_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "login of the member, empty to unassign",
+                        "name": "assignee",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/checklist/check": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark checklist item as done or not done. Allowed for the assignee and leader, unassigned items for any member",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "CheckChecklistItem",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checklist item id",
+                        "name": "item_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "false to reopen the item",
+                        "name": "done",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/checklist/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove item from the checklist, only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "DeleteChecklistItem",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checklist item id",
+                        "name": "item_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete existing task",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "DeleteTask",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/deleted": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deleted tasks of the group that can still be restored, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "GetDeletedTasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/dependency/add": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make the task start only after another task ends, only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "AddDependency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the task that must end first",
+                        "name": "depends_on",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "minutes between the tasks",
+                        "name": "gap",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/dependency/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove dependency between tasks, only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "RemoveDependency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the task it depends on",
+                        "name": "depends_on",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Export tasks with coordinates in chronological order as GeoJSON (points and a route line) or GPX (waypoints and a route)",
+                "produces": [
+                    "application/json",
+                    "text/xml"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "ExportRoute",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "geojson",
+                            "gpx"
+                        ],
+                        "type": "string",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21",
+                        "description": "first day to export",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-28",
+                        "description": "last day to export",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/freeslots": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Find free time slots of at least the given duration. By default searches within the trip dates",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "FindFreeSlots",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2,
+                        "name": "hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 30,
+                        "name": "minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21",
+                        "description": "first day to search",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-28",
+                        "description": "last day to search",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "skip 23:00-07:00 of trip local time",
+                        "name": "quiet_hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "minutes to keep free before and after other tasks",
+                        "name": "buffer",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/getlist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create new task",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "GetTasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "All saved versions of the task, newest first, with who changed it and the task before and after the change. Works for deleted tasks too",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "GetTaskHistory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of task",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/itinerary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get tasks of the trip day by day in the trip timezone with free time between them. By default shows the trip dates",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "GetItinerary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21",
+                        "description": "first day to show",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-28",
+                        "description": "last day to show",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "walk",
+                            "bike",
+                            "transit",
+                            "car",
+                            "train"
+                        ],
+                        "type": "string",
+                        "description": "travel mode for travel time between tasks",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/occurrence/cancel": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel one occurrence of a recurring task without deleting the series, only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "CancelOccurrence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21T08:00:00Z",
+                        "description": "original start of the occurrence",
+                        "name": "occurrence",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/progress": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Tasks by status, checklist completion, outstanding items per member and days left until departure",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "GetProgress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/restore": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Bring a deleted task back as it was when it was deleted, the task is checked against the current plan again. Only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "RestoreTask",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the deleted task",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change status of the task, only for leader. Cancelled tasks do not block time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "SetTaskStatus",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "planned",
+                            "confirmed",
+                            "done",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "new status",
+                        "name": "status",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/undo": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Bring the task back to how it was before its last change, the task is checked against the current plan again. Only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "UndoTaskChange",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of task",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/tasks/update": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update existing task",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "UpdateTask",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task Details",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "14:00",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2,
+                        "name": "hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
                         "example": 30,
                         "name": "minutes",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the place",
+                        "name": "location_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "address of the place",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 41.9028,
+                        "description": "latitude",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 12.4964,
+                        "description": "longitude",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "FREQ=WEEKLY;BYDAY=MO,WE",
+                        "description": "new recurrence rule",
+                        "name": "rrule",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-10-21T08:00:00Z",
+                        "description": "original start of the occurrence to change, for recurring tasks",
+                        "name": "occurrence",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "this",
+                            "following"
+                        ],
+                        "type": "string",
+                        "description": "with occurrence: change only it or it and all following",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "shift dependent tasks when the task moves later",
+                        "name": "cascade",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete account, leave all groups and revoke invites, sessions and api keys. Accounts without a password get an email with a link to confirm it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "DeleteAccount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "your password, not needed for OIDC accounts",
+                        "name": "password",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/email": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change email, the new email is used after confirming the link sent to it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "ChangeEmail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "new email",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "your password, not needed for OIDC accounts",
+                        "name": "password",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/failedlogins": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get recent failed sign in attempts to your account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get failed logins",
+                "responses": {}
+            }
+        },
+        "/users/info": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get public profile of another user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "GetUserInfo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "login of user",
+                        "name": "login",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/privacy": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Choose who can find you in user search",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update privacy",
+                "parameters": [
+                    {
+                        "enum": [
+                            "everyone",
+                            "contacts",
+                            "nobody"
+                        ],
+                        "type": "string",
+                        "description": "who can find you",
+                        "name": "search_visibility",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get your account and profile",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "GetProfile",
+                "responses": {}
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update profile, only sent fields are changed, send empty value to clear a field",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "UpdateProfile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "display name",
+                        "name": "display_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "link to avatar image",
+                        "name": "avatar_url",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Europe/Rome",
+                        "description": "home timezone",
+                        "name": "timezone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "en-US",
+                        "description": "locale",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "about you",
+                        "name": "bio",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/users/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Search users by the beginning of login or display name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Search users",
+                "parameters": [
+                    {
+                        "minLength": 2,
+                        "type": "string",
+                        "description": "beginning of login or display name",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "max number of results, 20 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks/add": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Subscribe a URL to events of the group, only for leader. The secret to check signatures is shown only once",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "AddWebhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "https://example.com/hooks/trip",
+                        "description": "http or https URL receiving POST requests",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "task.created,poll.closed,member.joined",
+                        "description": "comma separated events",
+                        "name": "events",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the webhook, deliveries still being retried are dropped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "DeleteWebhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of webhook",
+                        "name": "webhook_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Latest deliveries of the webhook with the response code of every attempt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "GetWebhookDeliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of webhook",
+                        "name": "webhook_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks/getlist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Webhooks of the group, only for leader",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "GetWebhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of group",
+                        "name": "group_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {}
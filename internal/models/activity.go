@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	MinActivityPriority = 1
+	MaxActivityPriority = 5
+)
+
+// Activity is a wishlist item that is not scheduled yet, Duration is in minutes
+type Activity struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GroupID      primitive.ObjectID `json:"group_id" bson:"group_id"`
+	Title        string             `json:"title" bson:"title"`
+	Duration     int                `json:"duration" bson:"duration"`
+	Priority     int                `json:"priority" bson:"priority"`
+	NotBefore    *time.Time         `json:"not_before,omitempty" bson:"not_before,omitempty"`
+	NotAfter     *time.Time         `json:"not_after,omitempty" bson:"not_after,omitempty"`
+	OpeningHours *OpeningHours      `json:"opening_hours,omitempty" bson:"opening_hours,omitempty"`
+	Location     *Location          `json:"location,omitempty" bson:"location,omitempty"`
+	AddedBy      string             `json:"added_by" bson:"added_by"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// OpeningHours are the same every day and given in trip local time
+type OpeningHours struct {
+	Opens  string `json:"opens" bson:"opens" example:"09:00"`
+	Closes string `json:"closes" bson:"closes" example:"18:00"`
+}
+
+type CreateActivity struct {
+	GroupID   string   `validate:"required"`
+	Title     string   `validate:"required,max=200"`
+	Duration  Duration `validate:"-"`
+	Priority  int      `validate:"min=1,max=5"`
+	NotBefore string
+	NotAfter  string
+	Opens     string
+	Closes    string
+	Location  *Location `validate:"-"`
+}
+
+// PlanOptions tune the scheduler. Buffer is the least time in minutes kept free between tasks,
+// Mode is the travel mode used to keep time for getting between places with coordinates
+type PlanOptions struct {
+	GroupID    string
+	QuietHours bool
+	Buffer     int
+	Mode       string
+}
+
+type PlannedActivity struct {
+	ActivityID primitive.ObjectID `json:"activity_id" bson:"activity_id"`
+	Title      string             `json:"title" bson:"title"`
+	Priority   int                `json:"priority" bson:"priority"`
+	StartTime  time.Time          `json:"start_time" bson:"start_time"`
+	EndTime    time.Time          `json:"end_time" bson:"end_time"`
+	Location   *Location          `json:"location,omitempty" bson:"location,omitempty"`
+}
+
+// ActivityPlan is a proposal, no task is created until the leader accepts it.
+// The plan is kept until ExpiresAt, so exactly what was shown is accepted
+type ActivityPlan struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	GroupID     primitive.ObjectID `json:"-" bson:"group_id"`
+	TravelMode  string             `json:"travel_mode" bson:"travel_mode"`
+	Planned     []PlannedActivity  `json:"planned" bson:"planned"`
+	Unscheduled []Activity         `json:"unscheduled" bson:"unscheduled"`
+	ExpiresAt   time.Time          `json:"expires_at" bson:"expires_at"`
+}
+
+type AcceptedPlan struct {
+	Created []PlannedActivity `json:"created"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const APIKeyPrefix = "jp_"
+
+const (
+	ScopeTasksRead   = "tasks:read"
+	ScopeTasksWrite  = "tasks:write"
+	ScopePollsRead   = "polls:read"
+	ScopePollsWrite  = "polls:write"
+	ScopeGroupsRead  = "groups:read"
+	ScopeGroupsWrite = "groups:write"
+	ScopeChat        = "chat"
+)
+
+var APIKeyScopes = []string{
+	ScopeTasksRead, ScopeTasksWrite,
+	ScopePollsRead, ScopePollsWrite,
+	ScopeGroupsRead, ScopeGroupsWrite,
+	ScopeChat,
+}
+
+type APIKey struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserLogin  string             `json:"-" bson:"user_login"`
+	Name       string             `json:"name" bson:"name"`
+	Prefix     string             `json:"prefix" bson:"prefix"`
+	KeyHash    string             `json:"-" bson:"key_hash"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt  time.Time          `json:"expires_at" bson:"expires_at"`
+	LastUsedAt *time.Time         `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+}
+
+type CreateAPIKey struct {
+	Name          string   `json:"name" validate:"required,max=50"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"min=1,max=365"`
+}
+
+type CreatedAPIKey struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"api_key"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditTargetMember = "member"
+	AuditTargetTask   = "task"
+	AuditTargetPoll   = "poll"
+)
+
+// AuditActions are the actions written to the audit log, an action is the name of the event it comes from
+var AuditActions = []string{
+	MemberInvited{}.EventName(), MemberJoined{}.EventName(), MemberLeft{}.EventName(),
+	MemberBanned{}.EventName(), MemberUnbanned{}.EventName(), LeaderChanged{}.EventName(),
+	TaskCreated{}.EventName(), TaskUpdated{}.EventName(), TaskDeleted{}.EventName(),
+	PollCreated{}.EventName(), PollClosed{}.EventName(), PollDeleted{}.EventName(),
+}
+
+// AuditEntry is a record of the audit log of a group, entries are never changed or removed.
+// The id is the id of the event in the outbox, so the same event is written once
+type AuditEntry struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id"`
+	GroupID string             `json:"-" bson:"group_id"`
+	// Actor is empty when the application did it, like closing a poll whose time is over
+	Actor      string        `json:"actor,omitempty" bson:"actor,omitempty"`
+	Action     string        `json:"action" bson:"action"`
+	TargetType string        `json:"target_type" bson:"target_type"`
+	TargetID   string        `json:"target_id" bson:"target_id"`
+	Target     string        `json:"target" bson:"target"`
+	Summary    string        `json:"summary" bson:"summary"`
+	Changes    []AuditChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	CreatedAt  time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// AuditChange is one field of a task before and after the change, values are written as text
+type AuditChange struct {
+	Field  string `json:"field" bson:"field"`
+	Before string `json:"before,omitempty" bson:"before,omitempty"`
+	After  string `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+type AuditFilter struct {
+	GroupID string
+	Actor   string
+	Action  string
+	From    *time.Time
+	To      *time.Time
+	Page    int64
+	Limit   int64
+}
+
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	Page    int64        `json:"page"`
+	Limit   int64        `json:"limit"`
+	Total   int64        `json:"total"`
+}
+
+const (
+	ExportCSV  = "csv"
+	ExportJSON = "json"
+)
+
+// AuditExport is a ready to download file with the audit log of a group
+type AuditExport struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
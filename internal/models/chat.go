@@ -7,8 +7,13 @@ type Message struct {
 	Content string    `json:"content" bson:"content"`
 	GroupID string    `json:"group_id" bson:"group_id"`
 	Time    time.Time `bson:"time"`
+	// Key is set only on system messages, so the same message is never posted twice
+	Key string `json:"-" bson:"key,omitempty"`
 }
 
+// SystemUser is the author of messages posted by the application
+const SystemUser = "system"
+
 type UserConn struct {
 	UserLogin string
 	GroupID   string
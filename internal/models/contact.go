@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ContactPending  = "pending"
+	ContactAccepted = "accepted"
+	ContactDeclined = "declined"
+	ContactBlocked  = "blocked"
+)
+
+const (
+	VisibilityEveryone = "everyone"
+	VisibilityContacts = "contacts"
+	VisibilityNobody   = "nobody"
+)
+
+// Contact is the relation between two users, there is one document per pair of users
+type Contact struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Pair      string             `bson:"pair"`
+	Requester string             `bson:"requester"`
+	Addressee string             `bson:"addressee"`
+	Status    string             `bson:"status"`
+	BlockedBy []string           `bson:"blocked_by"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+func ContactPair(firstLogin, secondLogin string) string {
+	if firstLogin > secondLogin {
+		firstLogin, secondLogin = secondLogin, firstLogin
+	}
+	return firstLogin + "|" + secondLogin
+}
+
+func (c Contact) Other(userLogin string) string {
+	if c.Requester == userLogin {
+		return c.Addressee
+	}
+	return c.Requester
+}
+
+type ContactList struct {
+	Friends  []string `json:"friends"`
+	Incoming []string `json:"incoming_requests"`
+	Outgoing []string `json:"outgoing_requests"`
+	Blocked  []string `json:"blocked"`
+}
+
+type Privacy struct {
+	SearchVisibility string `json:"search_visibility" bson:"search_visibility"`
+}
+
+type UserSearch struct {
+	Prefix        string
+	ExcludeLogins []string
+	ContactLogins []string
+	Limit         int64
+}
+
+type InviteSuggestion struct {
+	Login        string `json:"login"`
+	DisplayName  string `json:"display_name,omitempty"`
+	IsContact    bool   `json:"is_contact"`
+	SharedGroups int    `json:"shared_groups"`
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event is a change of the domain, services publish events and other parts of the application subscribe to them.
+// Group in the events is the group as it was before the change
+type Event interface {
+	EventName() string
+}
+
+type MemberJoined struct {
+	Group Group
+	Login string
+	// InvitedBy is the sender of the invite that was used
+	InvitedBy string
+}
+
+type MemberLeft struct {
+	Group Group
+	Login string
+	// NewLeader is set when the leader left and the group got a random one
+	NewLeader string
+}
+
+type MemberBanned struct {
+	Group Group
+	Login string
+	Actor string
+}
+
+type MemberUnbanned struct {
+	Group Group
+	Login string
+	Actor string
+}
+
+type MemberInvited struct {
+	Group Group
+	Login string
+	Actor string
+}
+
+type LeaderChanged struct {
+	Group  Group
+	Leader string
+	Actor  string
+	// ActorLeft is true when the leader was picked because the previous one left the group
+	ActorLeft bool
+}
+
+// TaskChange describes the task in TaskCreated, TaskUpdated and TaskDeleted,
+// Summary is a human readable text of the change. Before is nil for a new task and After is nil for a deleted one
+type TaskChange struct {
+	Group   Group
+	Actor   string
+	TaskID  primitive.ObjectID
+	Title   string
+	Summary string
+	Before  *Task `bson:",omitempty"`
+	After   *Task `bson:",omitempty"`
+}
+
+type TaskCreated struct {
+	TaskChange `bson:",inline"`
+}
+
+type TaskUpdated struct {
+	TaskChange `bson:",inline"`
+}
+
+type TaskDeleted struct {
+	TaskChange `bson:",inline"`
+}
+
+type PollCreated struct {
+	Group Group
+	Actor string
+	Poll  Poll
+}
+
+type PollClosed struct {
+	Poll   Poll
+	Result PollResult
+	// ClosedBy is who closed the poll early, it is empty when the time of the poll was over
+	ClosedBy string
+}
+
+type PollDeleted struct {
+	Group Group
+	Actor string
+	Poll  Poll
+}
+
+type MessagePosted struct {
+	Message Message
+}
+
+// TaskProposalRejected tells the member why the proposed task was not added
+type TaskProposalRejected struct {
+	Group    Group
+	Actor    string
+	Proposal TaskProposal
+	Reason   string
+}
+
+func (MemberJoined) EventName() string         { return "member.joined" }
+func (MemberLeft) EventName() string           { return "member.left" }
+func (MemberBanned) EventName() string         { return "member.banned" }
+func (MemberUnbanned) EventName() string       { return "member.unbanned" }
+func (MemberInvited) EventName() string        { return "member.invited" }
+func (LeaderChanged) EventName() string        { return "leader.changed" }
+func (TaskCreated) EventName() string          { return "task.created" }
+func (TaskUpdated) EventName() string          { return "task.updated" }
+func (TaskDeleted) EventName() string          { return "task.deleted" }
+func (PollCreated) EventName() string          { return "poll.created" }
+func (PollClosed) EventName() string           { return "poll.closed" }
+func (PollDeleted) EventName() string          { return "poll.deleted" }
+func (MessagePosted) EventName() string        { return "message.posted" }
+func (TaskProposalRejected) EventName() string { return "proposal.rejected" }
+
+// OutboxEvent is an event saved together with the change it describes, the relay dispatches it after the commit
+type OutboxEvent struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	Name         string             `bson:"name"`
+	Payload      bson.Raw           `bson:"payload"`
+	CreatedAt    time.Time          `bson:"created_at"`
+	DispatchedAt *time.Time         `bson:"dispatched_at,omitempty"`
+	// Error is set when the event could not be read back, it is not dispatched
+	Error string `bson:"error,omitempty"`
+}
+
+// DecodeEvent reads an event of the outbox back into its type
+func DecodeEvent(name string, payload bson.Raw) (Event, error) {
+	switch name {
+	case MemberJoined{}.EventName():
+		return decodeEvent[MemberJoined](payload)
+	case MemberLeft{}.EventName():
+		return decodeEvent[MemberLeft](payload)
+	case MemberBanned{}.EventName():
+		return decodeEvent[MemberBanned](payload)
+	case MemberUnbanned{}.EventName():
+		return decodeEvent[MemberUnbanned](payload)
+	case MemberInvited{}.EventName():
+		return decodeEvent[MemberInvited](payload)
+	case LeaderChanged{}.EventName():
+		return decodeEvent[LeaderChanged](payload)
+	case TaskCreated{}.EventName():
+		return decodeEvent[TaskCreated](payload)
+	case TaskUpdated{}.EventName():
+		return decodeEvent[TaskUpdated](payload)
+	case TaskDeleted{}.EventName():
+		return decodeEvent[TaskDeleted](payload)
+	case PollCreated{}.EventName():
+		return decodeEvent[PollCreated](payload)
+	case PollClosed{}.EventName():
+		return decodeEvent[PollClosed](payload)
+	case PollDeleted{}.EventName():
+		return decodeEvent[PollDeleted](payload)
+	case MessagePosted{}.EventName():
+		return decodeEvent[MessagePosted](payload)
+	case TaskProposalRejected{}.EventName():
+		return decodeEvent[TaskProposalRejected](payload)
+	}
+	return nil, fmt.Errorf("unknown event %q", name)
+}
+
+func decodeEvent[E Event](payload bson.Raw) (Event, error) {
+	var event E
+	if err := bson.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("decode %s error: %v", event.EventName(), err)
+	}
+	return event, nil
+}
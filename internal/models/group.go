@@ -1,6 +1,11 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type CreateGroup struct {
 	Name        string   `json:"name" validate:"required"`
@@ -11,6 +16,10 @@ type GroupList struct {
 	ID           primitive.ObjectID
 	Name         string
 	MembersCount int
+	Status       string
+	Destinations []string   `json:",omitempty"`
+	StartDate    *time.Time `json:",omitempty"`
+	EndDate      *time.Time `json:",omitempty"`
 }
 
 type Group struct {
@@ -18,7 +27,16 @@ type Group struct {
 	Name        string             `json:"name" bson:"name"`
 	LeaderLogin string             `json:"leader_login" bson:"leader_login"`
 	Members     []string           `json:"members" bson:"members"`
+	Moderators  []string           `json:"moderators,omitempty" bson:"moderators,omitempty"`
 	IsActive    bool               `json:"-" bson:"isActive"`
+	Trip        Trip               `json:"trip" bson:"trip"`
+	// ReminderMinutes is how long before a task members are reminded, nil means the default
+	ReminderMinutes *int `json:"reminder_minutes,omitempty" bson:"reminder_minutes,omitempty"`
+}
+
+// CanReview tells if the member reviews proposals of other members
+func (g Group) CanReview(userLogin string) bool {
+	return g.LeaderLogin == userLogin || slices.Contains(g.Moderators, userLogin)
 }
 
 type BlackList struct {
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Itinerary shows the tasks of a trip day by day in the trip timezone
+type Itinerary struct {
+	GroupID    primitive.ObjectID `json:"group_id"`
+	Timezone   string             `json:"timezone"`
+	TravelMode string             `json:"travel_mode"`
+	Days       []ItineraryDay     `json:"days"`
+}
+
+type ItineraryDay struct {
+	Date        string          `json:"date" example:"2024-10-21"`
+	Items       []ItineraryItem `json:"items"`
+	Gaps        []Gap           `json:"gaps"`
+	BusyMinutes int             `json:"busy_minutes"`
+	FreeMinutes int             `json:"free_minutes"`
+	// ImpossibleTransitions counts tasks that cant be reached in time from the previous one
+	ImpossibleTransitions int `json:"impossible_transitions"`
+}
+
+// ItineraryItem is a task as it is seen on one day,
+// a task that spans midnight is listed on every day it touches
+type ItineraryItem struct {
+	TaskID             primitive.ObjectID `json:"task_id"`
+	Title              string             `json:"title"`
+	Status             string             `json:"status"`
+	StartTime          time.Time          `json:"start_time"`
+	EndTime            time.Time          `json:"end_time"`
+	Duration           int                `json:"duration"`
+	SpansMidnight      bool               `json:"spans_midnight"`
+	ContinuesFromPrior bool               `json:"continues_from_prior_day"`
+	Location           *Location          `json:"location,omitempty"`
+	Occurrence         *time.Time         `json:"occurrence,omitempty"`
+	Travel             *Travel            `json:"travel,omitempty"`
+}
+
+// Travel estimates the way from the previous task with coordinates, by straight line
+type Travel struct {
+	FromTaskID       primitive.ObjectID `json:"from_task_id"`
+	DistanceKm       float64            `json:"distance_km"`
+	Mode             string             `json:"mode"`
+	Minutes          int                `json:"minutes"`
+	AvailableMinutes int                `json:"available_minutes"`
+	Impossible       bool               `json:"impossible"`
+}
+
+type ItineraryFilter struct {
+	GroupID string
+	From    string
+	To      string
+	Mode    string
+}
+
+// Gap is free time between two tasks of the same day
+type Gap struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Minutes   int       `json:"minutes"`
+}
+
+// SlotSearch describes free time the leader is looking for, Buffer is in minutes
+type SlotSearch struct {
+	GroupID    string
+	Duration   Duration
+	From       string
+	To         string
+	QuietHours bool
+	Buffer     int
+}
+
+type FreeSlot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Minutes   int       `json:"minutes"`
+}
+
+const (
+	ExportGeoJSON = "geojson"
+	ExportGPX     = "gpx"
+)
+
+// RouteExport is a ready to download file with the trip route
+type RouteExport struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
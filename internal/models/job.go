@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobFailed  = "failed"
+)
+
+const (
+	JobFinalizePoll       = "finalize_poll"
+	JobFinalizePolls      = "finalize_polls"
+	JobPurgeInvites       = "purge_invites"
+	JobPurgeRevokedTokens = "purge_revoked_tokens"
+	JobTaskReminder       = "task_reminder"
+	JobNotificationEmail  = "notification_email"
+	JobSendDigests        = "send_digests"
+	JobWebhookDelivery    = "webhook_delivery"
+	JobPurgeOutbox        = "purge_outbox"
+	JobPurgeRevisions     = "purge_task_revisions"
+)
+
+// Job is a persisted unit of background work, the key makes scheduling the same work twice a no-op
+type Job struct {
+	Key      string            `json:"key" bson:"_id"`
+	Kind     string            `json:"kind" bson:"kind"`
+	Payload  map[string]string `json:"payload,omitempty" bson:"payload,omitempty"`
+	RunAt    time.Time         `json:"run_at" bson:"run_at"`
+	Interval time.Duration     `json:"interval,omitempty" bson:"interval,omitempty"`
+	Status   string            `json:"status" bson:"status"`
+	Attempts int               `json:"attempts" bson:"attempts"`
+	// LockedUntil protects a running job from being taken by another replica, a crashed run is retried after it
+	LockedUntil time.Time `json:"locked_until" bson:"locked_until"`
+	LockedBy    string    `json:"locked_by,omitempty" bson:"locked_by,omitempty"`
+	LastError   string    `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+}
+
+// RetryPolicy describes how failed jobs are retried: the delay doubles from BaseDelay up to MaxDelay,
+// a job is failed after MaxAttempts, periodic jobs just wait for their next run
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// Lease is held by one replica at a time, it must be renewed before it expires
+type Lease struct {
+	Name      string    `bson:"_id"`
+	Holder    string    `bson:"holder"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
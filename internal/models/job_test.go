@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: 30 * time.Minute}
+	tests := []struct {
+		name    string
+		attempt int
+		want    time.Duration
+	}{
+		{name: "before first attempt", attempt: 0, want: 30 * time.Second},
+		{name: "first attempt", attempt: 1, want: 30 * time.Second},
+		{name: "doubles", attempt: 2, want: time.Minute},
+		{name: "doubles again", attempt: 3, want: 2 * time.Minute},
+		{name: "last below max", attempt: 6, want: 16 * time.Minute},
+		{name: "capped", attempt: 7, want: 30 * time.Minute},
+		{name: "stays capped", attempt: 100, want: 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Delay(tt.attempt); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	LoginFailUnknownUser     = "unknown_user"
+	LoginFailInvalidPassword = "invalid_password"
+	LoginFailLocked          = "locked"
+)
+
+// LoginAttempt is an audit record of failed sign in
+type LoginAttempt struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Option string             `json:"option" bson:"option"`
+	Login  string             `json:"login,omitempty" bson:"login,omitempty"`
+	IP     string             `json:"ip" bson:"ip"`
+	Reason string             `json:"reason" bson:"reason"`
+	Time   time.Time          `json:"time" bson:"time"`
+}
+
+type LoginCounter struct {
+	Key         string    `bson:"_id"`
+	Failures    int       `bson:"failures"`
+	LastFailure time.Time `bson:"last_failure"`
+	LockedUntil time.Time `bson:"locked_until"`
+}
+
+// LockoutPolicy describes how long a key is blocked after failed attempts:
+// first FreeAttempts failures are free, then the delay doubles from BaseDelay up to MaxDelay,
+// after LockoutAfter failures the key is locked for LockoutFor.
+// Failures are forgotten after ResetAfter without new ones.
+type LockoutPolicy struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	LockoutAfter int
+	LockoutFor   time.Duration
+	ResetAfter   time.Duration
+}
+
+func (p LockoutPolicy) BlockFor(failures int) time.Duration {
+	if failures >= p.LockoutAfter {
+		return p.LockoutFor
+	}
+	if failures < p.FreeAttempts {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.FreeAttempts; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+func (c LoginCounter) IsExpired(now time.Time, policy LockoutPolicy) bool {
+	return now.Sub(c.LastFailure) > policy.ResetAfter && now.After(c.LockedUntil)
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutPolicyBlockFor(t *testing.T) {
+	policy := LockoutPolicy{
+		FreeAttempts: 3,
+		BaseDelay:    time.Second,
+		MaxDelay:     5 * time.Minute,
+		LockoutAfter: 20,
+		LockoutFor:   30 * time.Minute,
+		ResetAfter:   time.Hour,
+	}
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", failures: 0, want: 0},
+		{name: "free attempt", failures: 2, want: 0},
+		{name: "first delay", failures: 3, want: time.Second},
+		{name: "doubles", failures: 4, want: 2 * time.Second},
+		{name: "last below max", failures: 11, want: 256 * time.Second},
+		{name: "capped", failures: 12, want: 5 * time.Minute},
+		{name: "lockout", failures: 20, want: 30 * time.Minute},
+		{name: "after lockout", failures: 25, want: 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.BlockFor(tt.failures); got != tt.want {
+				t.Errorf("BlockFor(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginCounterIsExpired(t *testing.T) {
+	policy := LockoutPolicy{ResetAfter: time.Hour}
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		counter LoginCounter
+		want    bool
+	}{
+		{name: "recent failure", counter: LoginCounter{LastFailure: now.Add(-time.Minute)}, want: false},
+		{name: "old failure", counter: LoginCounter{LastFailure: now.Add(-2 * time.Hour)}, want: true},
+		{name: "old failure still locked", counter: LoginCounter{
+			LastFailure: now.Add(-2 * time.Hour), LockedUntil: now.Add(time.Minute)}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.counter.IsExpired(now, policy); got != tt.want {
+				t.Errorf("IsExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	NotificationTaskReminder     = "task_reminder"
+	NotificationInvite           = "invite"
+	NotificationBan              = "ban"
+	NotificationLeaderChanged    = "leader_changed"
+	NotificationPollCreated      = "poll_created"
+	NotificationPollResult       = "poll_result"
+	NotificationTaskChanged      = "task_changed"
+	NotificationProposalRejected = "proposal_rejected"
+)
+
+var NotificationKinds = []string{NotificationInvite, NotificationBan, NotificationLeaderChanged,
+	NotificationPollCreated, NotificationPollResult, NotificationTaskChanged, NotificationTaskReminder,
+	NotificationProposalRejected}
+
+const (
+	DeliveryInApp = "in_app"
+	DeliveryEmail = "email"
+	DeliveryBoth  = "both"
+	DeliveryNone  = "none"
+)
+
+var Deliveries = []string{DeliveryInApp, DeliveryEmail, DeliveryBoth, DeliveryNone}
+
+// Notification is an entry of the personal inbox, the key makes delivering the same event twice a no-op
+type Notification struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Key       string             `json:"-" bson:"key"`
+	UserLogin string             `json:"-" bson:"user_login"`
+	Kind      string             `json:"kind" bson:"kind"`
+	GroupID   string             `json:"group_id,omitempty" bson:"group_id,omitempty"`
+	Text      string             `json:"text" bson:"text"`
+	Data      map[string]string  `json:"data,omitempty" bson:"data,omitempty"`
+	Read      bool               `json:"read" bson:"read"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	// Hidden notifications are delivered only by email and are not shown in the inbox
+	Hidden bool `json:"-" bson:"hidden,omitempty"`
+	// EmailPending notifications wait for the daily digest of the user
+	EmailPending bool `json:"-" bson:"email_pending,omitempty"`
+}
+
+// NotificationSettings says how the user wants to get notifications,
+// kinds without a preference are delivered in the app
+type NotificationSettings struct {
+	UserLogin string `json:"-" bson:"_id"`
+	// Digest collects emails into one a day instead of sending them at once
+	Digest bool `json:"digest" bson:"digest"`
+	// DigestHour is the hour of the day in the user's timezone the digest is sent at, nil means DefaultDigestHour
+	DigestHour   *int       `json:"digest_hour,omitempty" bson:"digest_hour,omitempty"`
+	LastDigestAt *time.Time `json:"last_digest_at,omitempty" bson:"last_digest_at,omitempty"`
+	// LastDigestDay is the local date of the last digest, so it is sent once a calendar day
+	LastDigestDay string                   `json:"-" bson:"last_digest_day,omitempty"`
+	Preferences   []NotificationPreference `json:"preferences" bson:"preferences"`
+}
+
+const DefaultDigestHour = 8
+
+func (s NotificationSettings) DigestSendHour() int {
+	if s.DigestHour == nil {
+		return DefaultDigestHour
+	}
+	return *s.DigestHour
+}
+
+// NotificationPreference without a group applies to all groups, a group one wins over it
+type NotificationPreference struct {
+	GroupID  string `json:"group_id,omitempty" bson:"group_id,omitempty"`
+	Kind     string `json:"kind" bson:"kind"`
+	Delivery string `json:"delivery" bson:"delivery"`
+}
+
+func (s NotificationSettings) Delivery(groupID, kind string) string {
+	delivery := DeliveryInApp
+	for _, preference := range s.Preferences {
+		if preference.Kind != kind {
+			continue
+		}
+		if preference.GroupID == groupID && groupID != "" {
+			return preference.Delivery
+		}
+		if preference.GroupID == "" {
+			delivery = preference.Delivery
+		}
+	}
+	return delivery
+}
+
+type NotificationFilter struct {
+	UserLogin  string
+	UnreadOnly bool
+	Page       int64
+	Limit      int64
+}
+
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	Page          int64          `json:"page"`
+	Limit         int64          `json:"limit"`
+	Total         int64          `json:"total"`
+	Unread        int64          `json:"unread"`
+}
+
+// ReminderOverride replaces the reminder time of the group for one member, 0 turns reminders off
+type ReminderOverride struct {
+	GroupID   string `bson:"group_id"`
+	UserLogin string `bson:"user_login"`
+	Minutes   int    `bson:"minutes"`
+}
+
+type ReminderSettings struct {
+	GroupMinutes int  `json:"group_minutes"`
+	MyMinutes    *int `json:"my_minutes,omitempty"`
+	Effective    int  `json:"effective_minutes"`
+}
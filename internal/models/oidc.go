@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OIDCState struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	State        string             `bson:"state"`
+	Nonce        string             `bson:"nonce"`
+	CodeVerifier string             `bson:"code_verifier"`
+	ExpiresAt    time.Time          `bson:"expires_at"`
+}
+
+type OIDCRegistration struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Ticket    string             `bson:"ticket"`
+	Issuer    string             `bson:"issuer"`
+	Subject   string             `bson:"subject"`
+	Email     string             `bson:"email"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+}
+
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+type OIDCLoginResult struct {
+	Token              string `json:"token,omitempty"`
+	RegistrationTicket string `json:"registration_ticket,omitempty"`
+	Email              string `json:"email,omitempty"`
+}
+
+type OIDCSignUp struct {
+	Ticket string `json:"ticket" validate:"required"`
+	Login  string `json:"login" validate:"required,min=6,max=15"`
+}
@@ -17,6 +17,45 @@ type Poll struct {
 	Votes2        []string           `bson:"votes2"`
 	EndTime       time.Time          `bson:"endtime"`
 	IsEarlyClosed bool               `bson:"isEarlyClosed"`
+	FirstAction   *PollAction        `bson:"firstAction,omitempty"`
+	SecondAction  *PollAction        `bson:"secondAction,omitempty"`
+	Result        *PollResult        `bson:"result,omitempty"`
+}
+
+func (p Poll) HasActions() bool {
+	return p.FirstAction != nil || p.SecondAction != nil
+}
+
+const (
+	PollActionTask   = "task"
+	PollActionTrip   = "trip"
+	PollActionLeader = "leader"
+)
+
+// PollAction is run for the winning option when the poll closes, on behalf of the poll creator
+type PollAction struct {
+	Kind   string      `json:"kind" bson:"kind"`
+	Task   *CreateTask `json:"task,omitempty" bson:"task,omitempty"`
+	Trip   *UpdateTrip `json:"trip,omitempty" bson:"trip,omitempty"`
+	Leader string      `json:"leader,omitempty" bson:"leader,omitempty"`
+}
+
+const (
+	PollResultRunning  = "running"
+	PollResultDone     = "done"
+	PollResultProposed = "proposed"
+	PollResultFailed   = "failed"
+	PollResultSkipped  = "skipped"
+)
+
+// PollResult tells what happened with the action of the winning option
+type PollResult struct {
+	Winner     string    `json:"winner,omitempty" bson:"winner,omitempty"`
+	Status     string    `json:"status" bson:"status"`
+	Message    string    `json:"message,omitempty" bson:"message,omitempty"`
+	FinishedAt time.Time `json:"finished_at" bson:"finished_at"`
+	// ClaimedAt is when a running action was started, a run that crashed is claimed again after a while
+	ClaimedAt *time.Time `json:"-" bson:"claimed_at,omitempty"`
 }
 
 type CreatePoll struct {
@@ -25,6 +64,8 @@ type CreatePoll struct {
 	FirstOption  string `json:"fstOption" validate:"required"`
 	SecondOption string `json:"sndOption" validate:"required"`
 	Duration     uint64 `json:"duration" validate:"required"`
+	FirstAction  *PollAction
+	SecondAction *PollAction
 }
 
 type PollList struct {
@@ -41,6 +82,9 @@ type PrintPollList struct {
 	SecondOption     string
 	SecondVotesCount int
 	EndTime          string
+	FirstAction      *PollAction `json:",omitempty"`
+	SecondAction     *PollAction `json:",omitempty"`
+	Result           *PollResult `json:",omitempty"`
 }
 type AddVote struct {
 	GroupID string `json:"groupID" validate:"required"`
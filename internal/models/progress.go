@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GroupProgress shows how ready the group is for the trip
+type GroupProgress struct {
+	TasksByStatus  map[string]int    `json:"tasks_by_status"`
+	ChecklistTotal int               `json:"checklist_total"`
+	ChecklistDone  int               `json:"checklist_done"`
+	ByAssignee     map[string]int    `json:"outstanding_by_assignee"`
+	Outstanding    []OutstandingItem `json:"outstanding"`
+	// DaysToDeparture is empty when the trip has no start date
+	DaysToDeparture *int `json:"days_to_departure,omitempty"`
+}
+
+type OutstandingItem struct {
+	TaskID    primitive.ObjectID `json:"task_id"`
+	TaskTitle string             `json:"task_title"`
+	TaskStart time.Time          `json:"task_start"`
+	ItemID    primitive.ObjectID `json:"item_id"`
+	Title     string             `json:"title"`
+	Assignee  string             `json:"assignee,omitempty"`
+}
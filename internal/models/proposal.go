@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ProposalPending  = "pending"
+	ProposalApproved = "approved"
+	ProposalRejected = "rejected"
+)
+
+var ProposalStatuses = []string{ProposalPending, ProposalApproved, ProposalRejected}
+
+// TaskProposal is a task suggested by a member, it becomes a real task only after review
+type TaskProposal struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GroupID    primitive.ObjectID `json:"group_id" bson:"group_id"`
+	Task       Task               `json:"task" bson:"task"`
+	Comment    string             `json:"comment,omitempty" bson:"comment,omitempty"`
+	ProposedBy string             `json:"proposed_by" bson:"proposed_by"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	Status     string             `json:"status" bson:"status"`
+	EditedBy   string             `json:"edited_by,omitempty" bson:"edited_by,omitempty"`
+	ReviewedBy string             `json:"reviewed_by,omitempty" bson:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time         `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"`
+	Reason     string             `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+type ProposalFilter struct {
+	GroupID    string
+	Status     string
+	ProposedBy string
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RevisionCreated  = "created"
+	RevisionUpdated  = "updated"
+	RevisionDeleted  = "deleted"
+	RevisionRestored = "restored"
+	RevisionUndone   = "undone"
+)
+
+// TaskRevision is a version of a task saved with every change. Before is nil when the task was created
+// and After is nil when it was deleted
+type TaskRevision struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	TaskID    primitive.ObjectID `json:"task_id" bson:"task_id"`
+	GroupID   primitive.ObjectID `json:"-" bson:"group_id"`
+	Author    string             `json:"author" bson:"author"`
+	Change    string             `json:"change" bson:"change"`
+	Summary   string             `json:"summary" bson:"summary"`
+	Before    *Task              `json:"before,omitempty" bson:"before,omitempty"`
+	After     *Task              `json:"after,omitempty" bson:"after,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
@@ -13,6 +13,66 @@ type Task struct {
 	StartTime time.Time          `bson:"start_time"`
 	Duration  int                `bson:"duration"`
 	EndTime   time.Time          `bson:"end_time"`
+	DependsOn []Dependency       `bson:"depends_on,omitempty"`
+	Location  *Location          `bson:"location,omitempty"`
+	// Recurrence makes the task a series, its StartTime is the first occurrence
+	Recurrence *Recurrence `bson:"recurrence,omitempty"`
+	// Occurrence is set only on expanded copies of a series, it is the original start of the copy
+	Occurrence *time.Time      `bson:"-"`
+	Status     string          `bson:"status,omitempty"`
+	Checklist  []ChecklistItem `bson:"checklist,omitempty"`
+}
+
+const (
+	TaskPlanned   = "planned"
+	TaskConfirmed = "confirmed"
+	TaskDone      = "done"
+	TaskCancelled = "cancelled"
+)
+
+var TaskStatuses = []string{TaskPlanned, TaskConfirmed, TaskDone, TaskCancelled}
+
+// CurrentStatus treats tasks created before statuses existed as planned
+func (t Task) CurrentStatus() string {
+	if t.Status == "" {
+		return TaskPlanned
+	}
+	return t.Status
+}
+
+type ChecklistItem struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id"`
+	Title    string             `json:"title" bson:"title"`
+	Assignee string             `json:"assignee,omitempty" bson:"assignee,omitempty"`
+	Done     bool               `json:"done" bson:"done"`
+	DoneBy   string             `json:"done_by,omitempty" bson:"done_by,omitempty"`
+	DoneAt   *time.Time         `json:"done_at,omitempty" bson:"done_at,omitempty"`
+}
+
+type Recurrence struct {
+	Rule      string               `json:"rule" bson:"rule" example:"FREQ=DAILY;INTERVAL=2"`
+	Cancelled []time.Time          `json:"cancelled,omitempty" bson:"cancelled,omitempty"`
+	Overrides []OccurrenceOverride `json:"overrides,omitempty" bson:"overrides,omitempty"`
+}
+
+// OccurrenceOverride keeps changes made to a single occurrence of a series
+type OccurrenceOverride struct {
+	Occurrence time.Time `json:"occurrence" bson:"occurrence"`
+	Title      string    `json:"title" bson:"title"`
+	StartTime  time.Time `json:"start_time" bson:"start_time"`
+	EndTime    time.Time `json:"end_time" bson:"end_time"`
+	Duration   int       `json:"duration" bson:"duration"`
+}
+
+const (
+	ScopeThisOccurrence = "this"
+	ScopeFollowing      = "following"
+)
+
+// Dependency means the task can start only Gap minutes after the other task ends
+type Dependency struct {
+	TaskID primitive.ObjectID `json:"task_id" bson:"task_id"`
+	Gap    int                `json:"gap" bson:"gap"`
 }
 
 type CreateTask struct {
@@ -20,6 +80,11 @@ type CreateTask struct {
 	Title     string    `json:"title"`
 	StartTime StartTime `json:"start_time"`
 	Duration  Duration  `json:"duration"`
+	Location  *Location `json:"location"`
+	// Recurrence is an RRULE, on update it replaces the rule of the series
+	Recurrence string `json:"recurrence"`
+	// Cascade lets an update shift dependent tasks instead of failing
+	Cascade bool `json:"cascade"`
 }
 
 type StartTime struct {
@@ -34,7 +99,7 @@ type Duration struct {
 }
 
 func (c CreateTask) IsEmptyUpdate() bool {
-	return c.Title == "" && c.StartTime.IsFullEmpty() && c.Duration.IsEmpty()
+	return c.Title == "" && c.StartTime.IsFullEmpty() && c.Duration.IsEmpty() && c.Location == nil && c.Recurrence == ""
 }
 
 func (c CreateTask) IsEmpty() bool {
@@ -55,3 +120,15 @@ func (s StartTime) IsFullEmpty() bool {
 func (d Duration) IsEmpty() bool {
 	return d.DurDays <= 0 && d.DurHours <= 0 && d.DurMinutes <= 0
 }
+
+// Location of a task, coordinates are optional and in degrees
+type Location struct {
+	Name    string   `json:"name,omitempty" bson:"name,omitempty"`
+	Address string   `json:"address,omitempty" bson:"address,omitempty"`
+	Lat     *float64 `json:"lat,omitempty" bson:"lat,omitempty"`
+	Lon     *float64 `json:"lon,omitempty" bson:"lon,omitempty"`
+}
+
+func (l *Location) HasCoordinates() bool {
+	return l != nil && l.Lat != nil && l.Lon != nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+const (
+	TripPlanning = "planning"
+	TripBooked   = "booked"
+	TripOngoing  = "ongoing"
+	TripFinished = "finished"
+)
+
+// TripStatuses are listed in the order a trip goes through them
+var TripStatuses = []string{TripPlanning, TripBooked, TripOngoing, TripFinished}
+
+const TripDateFormat = "2006-01-02"
+
+// Trip dates are calendar days in the trip timezone, EndDate is the last day of the trip
+type Trip struct {
+	Description  string     `json:"description,omitempty" bson:"description,omitempty"`
+	Destinations []string   `json:"destinations,omitempty" bson:"destinations,omitempty"`
+	StartDate    *time.Time `json:"start_date,omitempty" bson:"start_date,omitempty"`
+	EndDate      *time.Time `json:"end_date,omitempty" bson:"end_date,omitempty"`
+	CoverImage   string     `json:"cover_image,omitempty" bson:"cover_image,omitempty"`
+	Status       string     `json:"status" bson:"status"`
+	Timezone     string     `json:"timezone,omitempty" bson:"timezone,omitempty"`
+}
+
+// Location falls back to UTC when timezone is not set or unknown
+func (t Trip) Location() *time.Location {
+	if t.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Window returns the time span tasks of the trip may take, open ended sides are zero
+func (t Trip) Window() (from, to time.Time) {
+	loc := t.Location()
+	if t.StartDate != nil {
+		from = time.Date(t.StartDate.Year(), t.StartDate.Month(), t.StartDate.Day(), 0, 0, 0, 0, loc)
+	}
+	if t.EndDate != nil {
+		to = time.Date(t.EndDate.Year(), t.EndDate.Month(), t.EndDate.Day()+1, 0, 0, 0, 0, loc)
+	}
+	return from, to
+}
+
+// UpdateTrip holds only the fields that were sent, nil means "leave as is"
+type UpdateTrip struct {
+	Description  *string `json:"description,omitempty" bson:"description,omitempty" validate:"omitempty,max=2000"`
+	Destinations *string `json:"destinations,omitempty" bson:"destinations,omitempty" validate:"omitempty,max=1000"`
+	StartDate    *string `json:"start_date,omitempty" bson:"start_date,omitempty"`
+	EndDate      *string `json:"end_date,omitempty" bson:"end_date,omitempty"`
+	CoverImage   *string `json:"cover_image,omitempty" bson:"cover_image,omitempty" validate:"omitempty,url,max=300"`
+	Status       *string `json:"status,omitempty" bson:"status,omitempty"`
+	Timezone     *string `json:"timezone,omitempty" bson:"timezone,omitempty" validate:"omitempty,max=64"`
+}
+
+func (u UpdateTrip) IsEmpty() bool {
+	return u.Description == nil && u.Destinations == nil && u.StartDate == nil &&
+		u.EndDate == nil && u.CoverImage == nil && u.Status == nil && u.Timezone == nil
+}
+
+const (
+	SortByStartDate = "start_date"
+	SortByStatus    = "status"
+	SortByName      = "name"
+)
+
+// GroupFilter narrows the group list, From and To select trips overlapping that period
+type GroupFilter struct {
+	Status string
+	From   *time.Time
+	To     *time.Time
+	SortBy string
+	Desc   bool
+}
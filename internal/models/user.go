@@ -1,13 +1,72 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type User struct {
-	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Login        string             `json:"login" validate:"required,min=6" bson:"login"`
-	Email        string             `json:"email" validate:"required,min=6" bson:"email"`
-	Password     string             `json:"password,omitempty" validate:"required,min=6" bson:"-"`
-	PasswordHash string             `json:"-" bson:"hashed_password"`
+	ID                primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Login             string             `json:"login" validate:"required,min=6" bson:"login"`
+	Email             string             `json:"email" validate:"required,min=6" bson:"email"`
+	Password          string             `json:"password,omitempty" validate:"required,min=6" bson:"-"`
+	PasswordHash      string             `json:"-" bson:"hashed_password"`
+	OIDCIssuer        string             `json:"-" bson:"oidc_issuer,omitempty"`
+	OIDCSubject       string             `json:"-" bson:"oidc_subject,omitempty"`
+	EmailVerified     bool               `json:"email_verified" bson:"email_verified"`
+	PendingEmail      string             `json:"pending_email,omitempty" bson:"pending_email,omitempty"`
+	EmailTokenHash    string             `json:"-" bson:"email_token_hash,omitempty"`
+	EmailTokenExpires time.Time          `json:"-" bson:"email_token_expires,omitempty"`
+	SessionsRevokedAt time.Time          `json:"-" bson:"sessions_revoked_at,omitempty"`
+	// DeleteTokenHash confirms deleting an account without a password, the link is sent by email
+	DeleteTokenHash    string    `json:"-" bson:"delete_token_hash,omitempty"`
+	DeleteTokenExpires time.Time `json:"-" bson:"delete_token_expires,omitempty"`
+	Profile            Profile   `json:"profile" bson:"profile"`
+}
+
+type Profile struct {
+	DisplayName string `json:"display_name" bson:"display_name"`
+	AvatarURL   string `json:"avatar_url" bson:"avatar_url"`
+	Timezone    string `json:"timezone" bson:"timezone"`
+	Locale      string `json:"locale" bson:"locale"`
+	Bio         string `json:"bio" bson:"bio"`
+}
+
+// Location falls back to UTC when timezone is not set or unknown
+func (p Profile) Location() *time.Location {
+	if p.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// UpdateProfile holds only the fields that were sent, nil means "leave as is"
+type UpdateProfile struct {
+	DisplayName *string `validate:"omitempty,max=50"`
+	AvatarURL   *string `validate:"omitempty,url,max=300"`
+	Timezone    *string `validate:"omitempty,max=64"`
+	Locale      *string `validate:"omitempty,bcp47_language_tag"`
+	Bio         *string `validate:"omitempty,max=500"`
+}
+
+func (u UpdateProfile) IsEmpty() bool {
+	return u.DisplayName == nil && u.AvatarURL == nil && u.Timezone == nil && u.Locale == nil && u.Bio == nil
+}
+
+type UserSearchResult struct {
+	Login       string `json:"login"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+}
+
+type PublicProfile struct {
+	Login   string  `json:"login"`
+	Profile Profile `json:"profile"`
 }
 
 type SignUp struct {
@@ -19,3 +78,8 @@ type LoginRequest struct {
 	Option   string `json:"login" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
+
+type RevokedToken struct {
+	TokenID   string    `bson:"token_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
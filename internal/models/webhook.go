@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	WebhookTaskCreated   = "task.created"
+	WebhookTaskUpdated   = "task.updated"
+	WebhookTaskDeleted   = "task.deleted"
+	WebhookPollCreated   = "poll.created"
+	WebhookPollClosed    = "poll.closed"
+	WebhookMemberJoined  = "member.joined"
+	WebhookMemberLeft    = "member.left"
+	WebhookMemberBanned  = "member.banned"
+	WebhookMessagePosted = "message.posted"
+)
+
+// headers of a delivery, the signature is "sha256=" and hex HMAC-SHA256 of "timestamp.body"
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var WebhookEvents = []string{WebhookTaskCreated, WebhookTaskUpdated, WebhookTaskDeleted, WebhookPollCreated,
+	WebhookPollClosed, WebhookMemberJoined, WebhookMemberLeft, WebhookMemberBanned, WebhookMessagePosted}
+
+// Webhook gets the events of the group it is subscribed to, requests are signed with its secret
+type Webhook struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GroupID   string             `json:"group_id" bson:"group_id"`
+	URL       string             `json:"url" bson:"url"`
+	Events    []string           `json:"events" bson:"events"`
+	Secret    string             `json:"-" bson:"secret"`
+	CreatedBy string             `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// CreatedWebhook is the only time the secret is shown
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one webhook, every attempt to send it is kept
+type WebhookDelivery struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WebhookID string             `json:"webhook_id" bson:"webhook_id"`
+	GroupID   string             `json:"group_id" bson:"group_id"`
+	Event     string             `json:"event" bson:"event"`
+	Payload   string             `json:"payload" bson:"payload"`
+	Status    string             `json:"status" bson:"status"`
+	Attempts  []WebhookAttempt   `json:"attempts" bson:"attempts"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	// Key is the event and the webhook, an event dispatched twice finds its delivery by it
+	Key string `json:"-" bson:"key,omitempty"`
+}
+
+type WebhookAttempt struct {
+	At         time.Time `json:"at" bson:"at"`
+	StatusCode int       `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms" bson:"duration_ms"`
+}
+
+// WebhookPayload is the JSON body of every delivery
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	GroupID   string      `json:"group_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
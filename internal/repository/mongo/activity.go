@@ -0,0 +1,115 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoActivityRepo struct {
+	ActivityColl *mongo.Collection
+	PlanColl     *mongo.Collection
+}
+
+func NewMongoActivityRepo(db *mongo.Client) *MongoActivityRepo {
+	return &MongoActivityRepo{
+		ActivityColl: db.Database(dbname).Collection(activityCollection),
+		PlanColl:     db.Database(dbname).Collection(activityPlanCollection),
+	}
+}
+
+func (r *MongoActivityRepo) AddActivity(ctx context.Context, activity models.Activity) error {
+	_, err := r.ActivityColl.InsertOne(ctx, activity)
+	if err != nil {
+		return fmt.Errorf("AddActivity error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoActivityRepo) GetActivities(ctx context.Context, groupID string) ([]models.Activity, error) {
+	oid, err := convertToObjectIDs(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	activities := []models.Activity{}
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "created_at", Value: 1}})
+	cursor, err := r.ActivityColl.Find(ctx, bson.M{"group_id": oid[0]}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("GetActivities error: %v", err)
+	}
+	err = cursor.All(ctx, &activities)
+	if err != nil {
+		return nil, fmt.Errorf("GetActivities all() error: %v", err)
+	}
+	return activities, nil
+}
+
+func (r *MongoActivityRepo) GetActivity(ctx context.Context, activityID, groupID string) (*models.Activity, error) {
+	oid, err := convertToObjectIDs(activityID, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	var activity models.Activity
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"group_id": oid[1]},
+		},
+	}
+	err = r.ActivityColl.FindOne(ctx, filter).Decode(&activity)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetActivity error: %v", err)
+	}
+	return &activity, nil
+}
+
+func (r *MongoActivityRepo) DeleteActivity(ctx context.Context, activityID string) error {
+	oid, err := convertToObjectIDs(activityID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	_, err = r.ActivityColl.DeleteOne(ctx, bson.M{"_id": oid[0]})
+	if err != nil {
+		return fmt.Errorf("DeleteActivity error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoActivityRepo) SavePlan(ctx context.Context, plan models.ActivityPlan) error {
+	_, err := r.PlanColl.InsertOne(ctx, plan)
+	if err != nil {
+		return fmt.Errorf("SavePlan error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoActivityRepo) GetPlan(ctx context.Context, planID, groupID string) (*models.ActivityPlan, error) {
+	oid, err := convertToObjectIDs(planID, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	var plan models.ActivityPlan
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"group_id": oid[1]},
+			{"expires_at": bson.M{"$gt": time.Now().UTC()}},
+		},
+	}
+	err = r.PlanColl.FindOne(ctx, filter).Decode(&plan)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetPlan error: %v", err)
+	}
+	return &plan, nil
+}
@@ -0,0 +1,88 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MongoAPIKeyRepo struct {
+	APIKeyColl *mongo.Collection
+}
+
+func NewMongoAPIKeyRepo(db *mongo.Client) *MongoAPIKeyRepo {
+	return &MongoAPIKeyRepo{APIKeyColl: db.Database(dbname).Collection(apiKeyCollection)}
+}
+
+func (r *MongoAPIKeyRepo) CreateAPIKey(ctx context.Context, key models.APIKey) error {
+	_, err := r.APIKeyColl.InsertOne(ctx, key)
+	if err != nil {
+		return fmt.Errorf("CreateAPIKey error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoAPIKeyRepo) GetAPIKeys(ctx context.Context, userLogin string) ([]models.APIKey, error) {
+	cursor, err := r.APIKeyColl.Find(ctx, bson.M{"user_login": userLogin})
+	if err != nil {
+		return nil, fmt.Errorf("GetAPIKeys error: %v", err)
+	}
+	var keys []models.APIKey
+	err = cursor.All(ctx, &keys)
+	if err != nil {
+		return nil, fmt.Errorf("GetAPIKeys all() error: %v", err)
+	}
+	return keys, nil
+}
+
+func (r *MongoAPIKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.APIKeyColl.FindOne(ctx, bson.M{"key_hash": keyHash}).Decode(&key)
+	if err != nil {
+		return nil, fmt.Errorf("GetAPIKeyByHash error: %v", err)
+	}
+	return &key, nil
+}
+
+func (r *MongoAPIKeyRepo) DeleteAPIKey(ctx context.Context, keyID, userLogin string) (int64, error) {
+	oid, err := convertToObjectIDs(keyID)
+	if err != nil {
+		return 0, fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"user_login": userLogin},
+		},
+	}
+	result, err := r.APIKeyColl.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("DeleteAPIKey error: %v", err)
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *MongoAPIKeyRepo) TouchAPIKey(ctx context.Context, keyID string, usedAt time.Time) error {
+	oid, err := convertToObjectIDs(keyID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	update := bson.M{"$set": bson.M{"last_used_at": usedAt}}
+	_, err = r.APIKeyColl.UpdateOne(ctx, bson.M{"_id": oid[0]}, update)
+	if err != nil {
+		return fmt.Errorf("TouchAPIKey error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoAPIKeyRepo) DeleteUserAPIKeys(ctx context.Context, userLogin string) error {
+	_, err := r.APIKeyColl.DeleteMany(ctx, bson.M{"user_login": userLogin})
+	if err != nil {
+		return fmt.Errorf("DeleteUserAPIKeys error: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoAuditRepo only adds and reads entries, the audit log is never changed
+type MongoAuditRepo struct {
+	AuditColl *mongo.Collection
+}
+
+func NewMongoAuditRepo(db *mongo.Client) *MongoAuditRepo {
+	return &MongoAuditRepo{AuditColl: db.Database(dbname).Collection(auditCollection)}
+}
+
+// AddEntry does nothing when the entry is already written
+func (r *MongoAuditRepo) AddEntry(ctx context.Context, entry models.AuditEntry) error {
+	_, err := r.AuditColl.UpdateOne(ctx, bson.M{"_id": entry.ID}, bson.M{"$setOnInsert": entry},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("AddEntry error: %v", err)
+	}
+	return nil
+}
+
+func auditFilter(filter models.AuditFilter) bson.M {
+	conditions := []bson.M{{"group_id": filter.GroupID}}
+	if filter.Actor != "" {
+		conditions = append(conditions, bson.M{"actor": filter.Actor})
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, bson.M{"action": filter.Action})
+	}
+	if filter.From != nil {
+		conditions = append(conditions, bson.M{"created_at": bson.M{"$gte": *filter.From}})
+	}
+	if filter.To != nil {
+		conditions = append(conditions, bson.M{"created_at": bson.M{"$lte": *filter.To}})
+	}
+	return bson.M{"$and": conditions}
+}
+
+// GetEntries returns a page of entries, newest first, and the number of all entries matching the filter
+func (r *MongoAuditRepo) GetEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int64, error) {
+	query := auditFilter(filter)
+	total, err := r.AuditColl.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetEntries count error: %v", err)
+	}
+	entries := []models.AuditEntry{}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((filter.Page - 1) * filter.Limit).
+		SetLimit(filter.Limit)
+	cursor, err := r.AuditColl.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetEntries error: %v", err)
+	}
+	err = cursor.All(ctx, &entries)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetEntries all() error: %v", err)
+	}
+	return entries, total, nil
+}
@@ -4,9 +4,12 @@ import (
 	"JourneyPlanner/internal/models"
 	"context"
 	"fmt"
+	"regexp"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ChatRepo struct {
@@ -27,6 +30,15 @@ func (r *ChatRepo) InsertMessage(ctx context.Context, msg models.Message) error
 	return nil
 }
 
+// InsertMessageOnce saves the message unless a message with the same key exists, reports whether it was saved
+func (r *ChatRepo) InsertMessageOnce(ctx context.Context, msg models.Message) (bool, error) {
+	res, err := r.ChatColl.UpdateOne(ctx, bson.M{"key": msg.Key}, bson.M{"$setOnInsert": msg}, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, fmt.Errorf("InsertMessageOnce error: %v", err)
+	}
+	return res.UpsertedCount > 0, nil
+}
+
 func (r *ChatRepo) FindMessagesByChatID(ctx context.Context, groupID string) ([]models.Message, error) {
 	var messages []models.Message
 	cursor, err := r.ChatColl.Find(ctx, bson.M{"group_id": groupID})
@@ -39,3 +51,24 @@ func (r *ChatRepo) FindMessagesByChatID(ctx context.Context, groupID string) ([]
 	}
 	return messages, nil
 }
+
+// FindMentions returns messages of the groups posted after the time that mention the user as @login
+func (r *ChatRepo) FindMentions(ctx context.Context, groupIDs []string, userLogin string, since time.Time) ([]models.Message, error) {
+	filter := bson.M{
+		"$and": []bson.M{
+			{"group_id": bson.M{"$in": groupIDs}},
+			{"time": bson.M{"$gt": since}},
+			{"content": bson.M{"$regex": "@" + regexp.QuoteMeta(userLogin) + `\b`}},
+		},
+	}
+	messages := []models.Message{}
+	cursor, err := r.ChatColl.Find(ctx, filter, options.Find().SetSort(bson.M{"time": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("FindMentions error: %v", err)
+	}
+	err = cursor.All(ctx, &messages)
+	if err != nil {
+		return nil, fmt.Errorf("FindMentions all() error: %v", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,85 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoContactRepo struct {
+	ContactColl *mongo.Collection
+}
+
+func NewMongoContactRepo(db *mongo.Client) *MongoContactRepo {
+	return &MongoContactRepo{ContactColl: db.Database(dbname).Collection(contactCollection)}
+}
+
+func (r *MongoContactRepo) GetContact(ctx context.Context, firstLogin, secondLogin string) (*models.Contact, error) {
+	var contact models.Contact
+	filter := bson.M{"pair": models.ContactPair(firstLogin, secondLogin)}
+	err := r.ContactColl.FindOne(ctx, filter).Decode(&contact)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetContact error: %v", err)
+	}
+	return &contact, nil
+}
+
+func (r *MongoContactRepo) SaveContact(ctx context.Context, contact models.Contact) error {
+	contact.Pair = models.ContactPair(contact.Requester, contact.Addressee)
+	filter := bson.M{"pair": contact.Pair}
+	_, err := r.ContactColl.ReplaceOne(ctx, filter, contact, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("SaveContact error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoContactRepo) DeleteContact(ctx context.Context, firstLogin, secondLogin string) error {
+	filter := bson.M{"pair": models.ContactPair(firstLogin, secondLogin)}
+	_, err := r.ContactColl.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("DeleteContact error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoContactRepo) GetUserContacts(ctx context.Context, userLogin string) ([]models.Contact, error) {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"requester": userLogin},
+			{"addressee": userLogin},
+		},
+	}
+	cursor, err := r.ContactColl.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserContacts error: %v", err)
+	}
+	var contacts []models.Contact
+	err = cursor.All(ctx, &contacts)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserContacts all() error: %v", err)
+	}
+	return contacts, nil
+}
+
+func (r *MongoContactRepo) DeleteUserContacts(ctx context.Context, userLogin string) error {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"requester": userLogin},
+			{"addressee": userLogin},
+		},
+	}
+	_, err := r.ContactColl.DeleteMany(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("DeleteUserContacts error: %v", err)
+	}
+	return nil
+}
@@ -52,6 +52,35 @@ func (r *MongoGroupRepo) GetGroupList(ctx context.Context, userLogin string) ([]
 	return groupList, nil
 }
 
+func (r *MongoGroupRepo) FilterGroups(ctx context.Context, userLogin string, groupFilter models.GroupFilter) ([]models.Group, error) {
+	var groupList []models.Group
+	filters := []bson.M{
+		{"members": userLogin},
+		{"isActive": true},
+	}
+	if groupFilter.Status == models.TripPlanning {
+		// groups created before trips existed have no status and are still being planned
+		filters = append(filters, bson.M{"trip.status": bson.M{"$in": []interface{}{models.TripPlanning, "", nil}}})
+	} else if groupFilter.Status != "" {
+		filters = append(filters, bson.M{"trip.status": groupFilter.Status})
+	}
+	if groupFilter.From != nil {
+		filters = append(filters, bson.M{"trip.end_date": bson.M{"$gte": *groupFilter.From}})
+	}
+	if groupFilter.To != nil {
+		filters = append(filters, bson.M{"trip.start_date": bson.M{"$lte": *groupFilter.To}})
+	}
+	cursor, err := r.GroupColl.Find(ctx, bson.M{"$and": filters})
+	if err != nil {
+		return nil, fmt.Errorf("FilterGroups error: %v", err)
+	}
+	err = cursor.All(ctx, &groupList)
+	if err != nil {
+		return nil, fmt.Errorf("cursorAll: %v", err)
+	}
+	return groupList, nil
+}
+
 func (r *MongoGroupRepo) GetGroup(ctx context.Context, groupID string, userLogin ...string) (*models.Group, error) {
 	oid, err := convertToObjectIDs(groupID)
 	if err != nil {
@@ -132,7 +161,8 @@ func (r *MongoGroupRepo) LeaveGroup(ctx context.Context, groupID, userLogin stri
 	}
 	update := bson.M{
 		"$pull": bson.M{
-			"members": userLogin,
+			"members":    userLogin,
+			"moderators": userLogin,
 		},
 	}
 	_, err = r.GroupColl.UpdateOne(ctx, filter, update)
@@ -166,3 +196,83 @@ func (r *MongoGroupRepo) JoinGroup(ctx context.Context, groupID, userLogin strin
 
 	return nil
 }
+
+func (r *MongoGroupRepo) AddModerator(ctx context.Context, groupID, userLogin string) error {
+	oid, err := convertToObjectIDs(groupID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"isActive": true},
+		},
+	}
+	update := bson.M{"$addToSet": bson.M{"moderators": userLogin}}
+	_, err = r.GroupColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("AddModerator error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoGroupRepo) RemoveModerator(ctx context.Context, groupID, userLogin string) error {
+	oid, err := convertToObjectIDs(groupID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"isActive": true},
+		},
+	}
+	update := bson.M{"$pull": bson.M{"moderators": userLogin}}
+	_, err = r.GroupColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("RemoveModerator error: %v", err)
+	}
+	return nil
+}
+
+// SetReminderMinutes changes the reminder time of the group, nil returns it to the default
+func (r *MongoGroupRepo) SetReminderMinutes(ctx context.Context, groupID string, minutes *int) error {
+	oid, err := convertToObjectIDs(groupID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"isActive": true},
+		},
+	}
+	update := bson.M{"$set": bson.M{"reminder_minutes": minutes}}
+	if minutes == nil {
+		update = bson.M{"$unset": bson.M{"reminder_minutes": ""}}
+	}
+	_, err = r.GroupColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("SetReminderMinutes error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoGroupRepo) UpdateTrip(ctx context.Context, groupID string, trip models.Trip) error {
+	oid, err := convertToObjectIDs(groupID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"isActive": true},
+		},
+	}
+	update := bson.M{"$set": bson.M{"trip": trip}}
+	_, err = r.GroupColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("UpdateTrip error: %v", err)
+	}
+	return nil
+}
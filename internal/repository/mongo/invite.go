@@ -4,8 +4,10 @@ import (
 	"JourneyPlanner/internal/models"
 	"context"
 	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -67,7 +69,8 @@ func (r *MongoInviteRepo) IsAlreadyInvited(ctx context.Context, groupID, userLog
 	return false, nil
 }
 
-func (r *MongoInviteRepo) DeleteInviteByToken(ctx context.Context, token string) error {
+// DeleteInviteByToken marks the invite used and returns it, nil if it is already used
+func (r *MongoInviteRepo) DeleteInviteByToken(ctx context.Context, token string) (*models.Invitation, error) {
 	filter := bson.M{
 		"$and": []bson.M{
 			{"token": token},
@@ -75,11 +78,15 @@ func (r *MongoInviteRepo) DeleteInviteByToken(ctx context.Context, token string)
 		},
 	}
 	update := bson.M{"$set": bson.M{"isUsed": true}}
-	_, err := r.InviteColl.UpdateOne(ctx, filter, update)
+	var invite models.Invitation
+	err := r.InviteColl.FindOneAndUpdate(ctx, filter, update).Decode(&invite)
 	if err != nil {
-		return fmt.Errorf("DeleteGroup error: %v", err)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("DeleteInviteByToken error: %v", err)
 	}
-	return nil
+	return &invite, nil
 }
 
 func (r *MongoInviteRepo) DeleteInviteByID(ctx context.Context, inviteID, userLogin string) (int64, error) {
@@ -101,3 +108,36 @@ func (r *MongoInviteRepo) DeleteInviteByID(ctx context.Context, inviteID, userLo
 	}
 	return result.ModifiedCount, nil
 }
+
+func (r *MongoInviteRepo) InvalidateUserInvites(ctx context.Context, userLogin string) error {
+	filter := bson.M{
+		"$and": []bson.M{
+			{"isUsed": false},
+			{"$or": []bson.M{
+				{"receiver": userLogin},
+				{"sender": userLogin},
+			}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"isUsed": true}}
+	_, err := r.InviteColl.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("InvalidateUserInvites error: %v", err)
+	}
+	return nil
+}
+
+// PurgeInvites deletes used invites and invites created before the time, their tokens are expired by then
+func (r *MongoInviteRepo) PurgeInvites(ctx context.Context, createdBefore time.Time) (int64, error) {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"isUsed": true},
+			{"_id": bson.M{"$lt": primitive.NewObjectIDFromTimestamp(createdBefore)}},
+		},
+	}
+	result, err := r.InviteColl.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("PurgeInvites error: %v", err)
+	}
+	return result.DeletedCount, nil
+}
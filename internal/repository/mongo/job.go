@@ -0,0 +1,175 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoJobRepo struct {
+	JobColl *mongo.Collection
+}
+
+func NewMongoJobRepo(db *mongo.Client) *MongoJobRepo {
+	return &MongoJobRepo{JobColl: db.Database(dbname).Collection(jobCollection)}
+}
+
+// ScheduleJob creates the job or moves the existing one with the same key to the new time
+func (r *MongoJobRepo) ScheduleJob(ctx context.Context, job models.Job) error {
+	update := bson.M{
+		"$set": bson.M{
+			"kind":       job.Kind,
+			"payload":    job.Payload,
+			"run_at":     job.RunAt,
+			"interval":   job.Interval,
+			"status":     models.JobPending,
+			"attempts":   0,
+			"last_error": "",
+		},
+		"$setOnInsert": bson.M{"created_at": job.CreatedAt},
+	}
+	_, err := r.JobColl.UpdateOne(ctx, bson.M{"_id": job.Key}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("ScheduleJob error: %v", err)
+	}
+	return nil
+}
+
+// EnsureJob creates the job only if there is no job with the same key
+func (r *MongoJobRepo) EnsureJob(ctx context.Context, job models.Job) error {
+	update := bson.M{"$setOnInsert": bson.M{
+		"kind":       job.Kind,
+		"payload":    job.Payload,
+		"run_at":     job.RunAt,
+		"interval":   job.Interval,
+		"status":     models.JobPending,
+		"attempts":   0,
+		"created_at": job.CreatedAt,
+	}}
+	_, err := r.JobColl.UpdateOne(ctx, bson.M{"_id": job.Key}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("EnsureJob error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoJobRepo) CancelJob(ctx context.Context, key string) error {
+	_, err := r.JobColl.DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		return fmt.Errorf("CancelJob error: %v", err)
+	}
+	return nil
+}
+
+// ClaimJob takes the next due job, jobs whose run lock expired are taken again
+func (r *MongoJobRepo) ClaimJob(ctx context.Context, holder string, lockUntil time.Time) (*models.Job, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"$or": []bson.M{
+			{"status": models.JobPending, "run_at": bson.M{"$lte": now}},
+			{"status": models.JobRunning, "locked_until": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": models.JobRunning, "locked_until": lockUntil, "locked_by": holder},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "run_at", Value: 1}}).
+		SetReturnDocument(options.After)
+	var job models.Job
+	err := r.JobColl.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ClaimJob error: %v", err)
+	}
+	return &job, nil
+}
+
+// the job could be rescheduled while it was running, then it is left for the new run
+func runningJob(job models.Job) bson.M {
+	return bson.M{"_id": job.Key, "status": models.JobRunning, "locked_by": job.LockedBy}
+}
+
+func (r *MongoJobRepo) CompleteJob(ctx context.Context, job models.Job) error {
+	_, err := r.JobColl.DeleteOne(ctx, runningJob(job))
+	if err != nil {
+		return fmt.Errorf("CompleteJob error: %v", err)
+	}
+	return nil
+}
+
+// RetryJob puts the job back with its attempts, RescheduleJob starts counting attempts again
+func (r *MongoJobRepo) RetryJob(ctx context.Context, job models.Job, runAt time.Time, lastError string) error {
+	update := bson.M{"$set": bson.M{"status": models.JobPending, "run_at": runAt, "last_error": lastError}}
+	_, err := r.JobColl.UpdateOne(ctx, runningJob(job), update)
+	if err != nil {
+		return fmt.Errorf("RetryJob error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoJobRepo) RescheduleJob(ctx context.Context, job models.Job, runAt time.Time, lastError string) error {
+	update := bson.M{"$set": bson.M{"status": models.JobPending, "run_at": runAt, "attempts": 0, "last_error": lastError}}
+	_, err := r.JobColl.UpdateOne(ctx, runningJob(job), update)
+	if err != nil {
+		return fmt.Errorf("RescheduleJob error: %v", err)
+	}
+	return nil
+}
+
+// FailJob keeps the job for inspection, it is not run anymore
+func (r *MongoJobRepo) FailJob(ctx context.Context, job models.Job, lastError string) error {
+	update := bson.M{"$set": bson.M{"status": models.JobFailed, "last_error": lastError}}
+	_, err := r.JobColl.UpdateOne(ctx, runningJob(job), update)
+	if err != nil {
+		return fmt.Errorf("FailJob error: %v", err)
+	}
+	return nil
+}
+
+type MongoLeaseRepo struct {
+	LeaseColl *mongo.Collection
+}
+
+func NewMongoLeaseRepo(db *mongo.Client) *MongoLeaseRepo {
+	return &MongoLeaseRepo{LeaseColl: db.Database(dbname).Collection(leaseCollection)}
+}
+
+// AcquireLease takes a free or expired lease, or renews the one the holder has.
+// When another replica holds it the upsert hits the unique _id and false is returned
+func (r *MongoLeaseRepo) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"_id": name,
+		"$or": []bson.M{
+			{"holder": holder},
+			{"expires_at": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"holder": holder, "expires_at": now.Add(ttl)}}
+	_, err := r.LeaseColl.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("AcquireLease error: %v", err)
+	}
+	return true, nil
+}
+
+func (r *MongoLeaseRepo) ReleaseLease(ctx context.Context, name, holder string) error {
+	_, err := r.LeaseColl.DeleteOne(ctx, bson.M{"_id": name, "holder": holder})
+	if err != nil {
+		return fmt.Errorf("ReleaseLease error: %v", err)
+	}
+	return nil
+}
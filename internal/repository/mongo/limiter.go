@@ -0,0 +1,96 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoLoginLimiter struct {
+	CounterColl *mongo.Collection
+	Policy      models.LockoutPolicy
+}
+
+func NewMongoLoginLimiter(db *mongo.Client, policy models.LockoutPolicy) *MongoLoginLimiter {
+	return &MongoLoginLimiter{
+		CounterColl: db.Database(dbname).Collection(loginCounterCollection),
+		Policy:      policy,
+	}
+}
+
+func (l *MongoLoginLimiter) Attempt(ctx context.Context, key string) (time.Duration, error) {
+	now := time.Now().UTC()
+	resetBefore := now.Add(-l.Policy.ResetAfter)
+	// the check and the increment are one atomic update, so replicas and parallel requests can't pass
+	// the check before the failure is counted. A blocked key is left as is.
+	// The counter starts over when the last failure is older than ResetAfter
+	blocked := bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$locked_until", now}}, now}}
+	failures := bson.M{"$cond": bson.A{
+		bson.M{"$lt": bson.A{bson.M{"$ifNull": bson.A{"$last_failure", resetBefore}}, resetBefore}},
+		1,
+		bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failures", 0}}, 1}},
+	}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"failures":     bson.M{"$cond": bson.A{blocked, "$failures", failures}},
+			"last_failure": bson.M{"$cond": bson.A{blocked, "$last_failure", now}},
+		}}},
+		// locked_until is not changed by the first stage, so blocked is the same here
+		{{Key: "$set", Value: bson.M{
+			"locked_until": bson.M{"$cond": bson.A{blocked, "$locked_until",
+				bson.M{"$add": bson.A{now, l.blockForExpr("$failures")}}}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	var counter models.LoginCounter
+	err := l.CounterColl.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&counter)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("Attempt error: %v", err)
+	}
+	return max(counter.LockedUntil.Sub(now), 0), nil
+}
+
+// blockForExpr is models.LockoutPolicy.BlockFor as an aggregation expression, in milliseconds
+func (l *MongoLoginLimiter) blockForExpr(failures string) bson.M {
+	return bson.M{"$cond": bson.A{
+		bson.M{"$gte": bson.A{failures, l.Policy.LockoutAfter}},
+		l.Policy.LockoutFor.Milliseconds(),
+		bson.M{"$cond": bson.A{
+			bson.M{"$lt": bson.A{failures, l.Policy.FreeAttempts}},
+			0,
+			bson.M{"$min": bson.A{
+				l.Policy.MaxDelay.Milliseconds(),
+				bson.M{"$multiply": bson.A{
+					l.Policy.BaseDelay.Milliseconds(),
+					bson.M{"$pow": bson.A{2, bson.M{"$subtract": bson.A{failures, l.Policy.FreeAttempts}}}},
+				}},
+			}},
+		}},
+	}}
+}
+
+func (l *MongoLoginLimiter) Forgive(ctx context.Context, key string) error {
+	filter := bson.M{"_id": key, "failures": bson.M{"$gt": 0}}
+	_, err := l.CounterColl.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"failures": -1}})
+	if err != nil {
+		return fmt.Errorf("Forgive error: %v", err)
+	}
+	return nil
+}
+
+func (l *MongoLoginLimiter) Reset(ctx context.Context, key string) error {
+	_, err := l.CounterColl.DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		return fmt.Errorf("Reset error: %v", err)
+	}
+	return nil
+}
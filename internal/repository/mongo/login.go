@@ -0,0 +1,49 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoLoginAuditRepo struct {
+	AuditColl *mongo.Collection
+}
+
+func NewMongoLoginAuditRepo(db *mongo.Client) *MongoLoginAuditRepo {
+	return &MongoLoginAuditRepo{AuditColl: db.Database(dbname).Collection(loginAuditCollection)}
+}
+
+func (r *MongoLoginAuditRepo) AddLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error {
+	_, err := r.AuditColl.InsertOne(ctx, attempt)
+	if err != nil {
+		return fmt.Errorf("AddLoginAttempt error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoLoginAuditRepo) GetLoginAttempts(ctx context.Context, login string, limit int64) ([]models.LoginAttempt, error) {
+	opts := options.Find().SetSort(bson.M{"time": -1}).SetLimit(limit)
+	cursor, err := r.AuditColl.Find(ctx, bson.M{"login": login}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("GetLoginAttempts error: %v", err)
+	}
+	var attempts []models.LoginAttempt
+	err = cursor.All(ctx, &attempts)
+	if err != nil {
+		return nil, fmt.Errorf("GetLoginAttempts all() error: %v", err)
+	}
+	return attempts, nil
+}
+
+func (r *MongoLoginAuditRepo) DeleteLoginAttempts(ctx context.Context, login string) error {
+	_, err := r.AuditColl.DeleteMany(ctx, bson.M{"login": login})
+	if err != nil {
+		return fmt.Errorf("DeleteLoginAttempts error: %v", err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"os"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -21,6 +22,28 @@ const (
 	inviteCollection    = "invites"
 	blacklistCollection = "blacklist"
 	chatCollection      = "messages"
+
+	oidcStateCollection        = "oidc_states"
+	oidcRegistrationCollection = "oidc_registrations"
+	apiKeyCollection           = "api_keys"
+	revokedTokenCollection     = "revoked_tokens"
+	contactCollection          = "contacts"
+	loginCounterCollection     = "login_counters"
+	loginAuditCollection       = "login_audit"
+	activityCollection         = "activities"
+	activityPlanCollection     = "activity_plans"
+	proposalCollection         = "proposals"
+	jobCollection              = "jobs"
+	leaseCollection            = "leases"
+	notificationCollection     = "notifications"
+	reminderCollection         = "reminder_overrides"
+	notifySettingsCollection   = "notification_settings"
+	webhookCollection          = "webhooks"
+	webhookDeliveryCollection  = "webhook_deliveries"
+	outboxCollection           = "outbox"
+	auditCollection            = "audit_log"
+	revisionCollection         = "task_revisions"
+	deletedLoginCollection     = "deleted_logins"
 )
 
 func CreateMongoClient(ctx context.Context) *mongo.Client {
@@ -36,6 +59,42 @@ func CreateMongoClient(ctx context.Context) *mongo.Client {
 	return client
 }
 
+// CreateIndexes creates the indexes the repositories rely on: unique keys that concurrent upserts
+// can't duplicate and expiry of short-lived records. Creating an existing index is a no-op
+func CreateIndexes(ctx context.Context, client *mongo.Client) {
+	db := client.Database(dbname)
+	indexes := map[string][]mongo.IndexModel{
+		userCollection: {
+			{Keys: bson.D{{Key: "login", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		oidcStateCollection: {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		oidcRegistrationCollection: {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		apiKeyCollection: {
+			{Keys: bson.D{{Key: "key_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		contactCollection: {
+			{Keys: bson.D{{Key: "pair", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		activityPlanCollection: {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		webhookDeliveryCollection: {
+			{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		},
+	}
+	for collection, collectionIndexes := range indexes {
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, collectionIndexes)
+		if err != nil {
+			logs.Fatal("Failed to create indexes of "+collection+": ", zap.Error(err))
+		}
+	}
+}
+
 func convertToObjectIDs(ids ...string) ([]primitive.ObjectID, error) {
 	objectIDs := make([]primitive.ObjectID, 0, len(ids))
 
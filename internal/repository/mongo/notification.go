@@ -0,0 +1,200 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoNotificationRepo struct {
+	NotificationColl *mongo.Collection
+}
+
+func NewMongoNotificationRepo(db *mongo.Client) *MongoNotificationRepo {
+	return &MongoNotificationRepo{NotificationColl: db.Database(dbname).Collection(notificationCollection)}
+}
+
+// AddNotification saves the notification unless one with the same key exists,
+// it returns the saved notification or nil if it was delivered before
+func (r *MongoNotificationRepo) AddNotification(ctx context.Context, notification models.Notification) (*models.Notification, error) {
+	update := bson.M{"$setOnInsert": bson.M{
+		"user_login":    notification.UserLogin,
+		"kind":          notification.Kind,
+		"group_id":      notification.GroupID,
+		"text":          notification.Text,
+		"data":          notification.Data,
+		"read":          false,
+		"created_at":    notification.CreatedAt,
+		"hidden":        notification.Hidden,
+		"email_pending": notification.EmailPending,
+	}}
+	res, err := r.NotificationColl.UpdateOne(ctx, bson.M{"key": notification.Key}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, fmt.Errorf("AddNotification error: %v", err)
+	}
+	if res.UpsertedCount == 0 {
+		return nil, nil
+	}
+	if oid, ok := res.UpsertedID.(primitive.ObjectID); ok {
+		notification.ID = oid
+	}
+	return &notification, nil
+}
+
+// WatchNotifications calls push for every notification shown in the inbox as soon as it is saved
+// by any replica, it returns when the context is done or the change stream fails
+func (r *MongoNotificationRepo) WatchNotifications(ctx context.Context, push func(models.Notification)) error {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType":       "insert",
+		"fullDocument.hidden": bson.M{"$ne": true},
+	}}}}
+	stream, err := r.NotificationColl.Watch(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("WatchNotifications error: %v", err)
+	}
+	defer stream.Close(context.Background())
+	for stream.Next(ctx) {
+		var change struct {
+			FullDocument models.Notification `bson:"fullDocument"`
+		}
+		err = stream.Decode(&change)
+		if err != nil {
+			return fmt.Errorf("WatchNotifications decode error: %v", err)
+		}
+		push(change.FullDocument)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("WatchNotifications next error: %v", stream.Err())
+}
+
+func notificationFilter(filter models.NotificationFilter) bson.M {
+	conditions := []bson.M{{"user_login": filter.UserLogin}, {"hidden": bson.M{"$ne": true}}}
+	if filter.UnreadOnly {
+		conditions = append(conditions, bson.M{"read": bson.M{"$ne": true}})
+	}
+	return bson.M{"$and": conditions}
+}
+
+func (r *MongoNotificationRepo) GetNotifications(ctx context.Context, filter models.NotificationFilter) ([]models.Notification, int64, error) {
+	query := notificationFilter(filter)
+	total, err := r.NotificationColl.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetNotifications count error: %v", err)
+	}
+	notifications := []models.Notification{}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((filter.Page - 1) * filter.Limit).
+		SetLimit(filter.Limit)
+	cursor, err := r.NotificationColl.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetNotifications error: %v", err)
+	}
+	err = cursor.All(ctx, &notifications)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetNotifications all() error: %v", err)
+	}
+	return notifications, total, nil
+}
+
+func (r *MongoNotificationRepo) CountUnread(ctx context.Context, userLogin string) (int64, error) {
+	count, err := r.NotificationColl.CountDocuments(ctx, notificationFilter(models.NotificationFilter{
+		UserLogin:  userLogin,
+		UnreadOnly: true,
+	}))
+	if err != nil {
+		return 0, fmt.Errorf("CountUnread error: %v", err)
+	}
+	return count, nil
+}
+
+// MarkRead reports false when the user has no such notification
+func (r *MongoNotificationRepo) MarkRead(ctx context.Context, notificationID, userLogin string) (bool, error) {
+	oid, err := convertToObjectIDs(notificationID)
+	if err != nil {
+		return false, fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"user_login": userLogin},
+		},
+	}
+	res, err := r.NotificationColl.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return false, fmt.Errorf("MarkRead error: %v", err)
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (r *MongoNotificationRepo) MarkAllRead(ctx context.Context, userLogin string) (int64, error) {
+	res, err := r.NotificationColl.UpdateMany(ctx, notificationFilter(models.NotificationFilter{
+		UserLogin:  userLogin,
+		UnreadOnly: true,
+	}), bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return 0, fmt.Errorf("MarkAllRead error: %v", err)
+	}
+	return res.ModifiedCount, nil
+}
+
+func (r *MongoNotificationRepo) GetNotification(ctx context.Context, notificationID string) (*models.Notification, error) {
+	oid, err := convertToObjectIDs(notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	var notification models.Notification
+	err = r.NotificationColl.FindOne(ctx, bson.M{"_id": oid[0]}).Decode(&notification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetNotification error: %v", err)
+	}
+	return &notification, nil
+}
+
+// GetEmailPending returns notifications waiting for the digest of the user, oldest first
+func (r *MongoNotificationRepo) GetEmailPending(ctx context.Context, userLogin string) ([]models.Notification, error) {
+	filter := bson.M{
+		"$and": []bson.M{
+			{"user_login": userLogin},
+			{"email_pending": true},
+		},
+	}
+	notifications := []models.Notification{}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.NotificationColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("GetEmailPending error: %v", err)
+	}
+	err = cursor.All(ctx, &notifications)
+	if err != nil {
+		return nil, fmt.Errorf("GetEmailPending all() error: %v", err)
+	}
+	return notifications, nil
+}
+
+func (r *MongoNotificationRepo) ClearEmailPending(ctx context.Context, ids []primitive.ObjectID) error {
+	_, err := r.NotificationColl.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"$unset": bson.M{"email_pending": ""}})
+	if err != nil {
+		return fmt.Errorf("ClearEmailPending error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoNotificationRepo) DeleteUserNotifications(ctx context.Context, userLogin string) error {
+	_, err := r.NotificationColl.DeleteMany(ctx, bson.M{"user_login": userLogin})
+	if err != nil {
+		return fmt.Errorf("DeleteUserNotifications error: %v", err)
+	}
+	return nil
+}
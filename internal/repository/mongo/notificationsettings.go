@@ -0,0 +1,137 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoNotificationSettingsRepo struct {
+	SettingsColl *mongo.Collection
+}
+
+func NewMongoNotificationSettingsRepo(db *mongo.Client) *MongoNotificationSettingsRepo {
+	return &MongoNotificationSettingsRepo{SettingsColl: db.Database(dbname).Collection(notifySettingsCollection)}
+}
+
+func (r *MongoNotificationSettingsRepo) GetSettings(ctx context.Context, userLogin string) (*models.NotificationSettings, error) {
+	var settings models.NotificationSettings
+	err := r.SettingsColl.FindOne(ctx, bson.M{"_id": userLogin}).Decode(&settings)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetSettings error: %v", err)
+	}
+	return &settings, nil
+}
+
+// SetPreference replaces the preference of the user for the same kind and group
+func (r *MongoNotificationSettingsRepo) SetPreference(ctx context.Context, userLogin string, preference models.NotificationPreference) error {
+	err := r.DeletePreference(ctx, userLogin, preference.GroupID, preference.Kind)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$push": bson.M{"preferences": preference}}
+	_, err = r.SettingsColl.UpdateOne(ctx, bson.M{"_id": userLogin}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("SetPreference error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoNotificationSettingsRepo) DeletePreference(ctx context.Context, userLogin, groupID, kind string) error {
+	match := bson.M{"kind": kind, "group_id": bson.M{"$exists": false}}
+	if groupID != "" {
+		match = bson.M{"kind": kind, "group_id": groupID}
+	}
+	_, err := r.SettingsColl.UpdateOne(ctx, bson.M{"_id": userLogin}, bson.M{"$pull": bson.M{"preferences": match}})
+	if err != nil {
+		return fmt.Errorf("DeletePreference error: %v", err)
+	}
+	return nil
+}
+
+// SetDigest turns the digest on or off, the send hour is kept when hour is nil
+func (r *MongoNotificationSettingsRepo) SetDigest(ctx context.Context, userLogin string, digest bool, hour *int) error {
+	set := bson.M{"digest": digest}
+	if hour != nil {
+		set["digest_hour"] = *hour
+	}
+	_, err := r.SettingsColl.UpdateOne(ctx, bson.M{"_id": userLogin}, bson.M{"$set": set}, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("SetDigest error: %v", err)
+	}
+	return nil
+}
+
+// GetDigestUsers returns settings of users with the digest turned on,
+// whether it is time to send depends on the user's timezone and is decided by the caller
+func (r *MongoNotificationSettingsRepo) GetDigestUsers(ctx context.Context) ([]models.NotificationSettings, error) {
+	settings := []models.NotificationSettings{}
+	cursor, err := r.SettingsColl.Find(ctx, bson.M{"digest": true})
+	if err != nil {
+		return nil, fmt.Errorf("GetDigestUsers error: %v", err)
+	}
+	err = cursor.All(ctx, &settings)
+	if err != nil {
+		return nil, fmt.Errorf("GetDigestUsers all() error: %v", err)
+	}
+	return settings, nil
+}
+
+// ClaimDigest moves the last digest time and day only if they were not moved since they were read,
+// so one digest is sent even if two runs meet
+func (r *MongoNotificationSettingsRepo) ClaimDigest(ctx context.Context, userLogin string, last *time.Time, now time.Time, day string) (bool, error) {
+	filter := bson.M{"_id": userLogin, "last_digest_at": bson.M{"$exists": false}}
+	if last != nil {
+		filter = bson.M{"_id": userLogin, "last_digest_at": *last}
+	}
+	update := bson.M{"$set": bson.M{"last_digest_at": now, "last_digest_day": day}}
+	res, err := r.SettingsColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("ClaimDigest error: %v", err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// ReleaseDigest gives the claim back when the digest could not be sent
+func (r *MongoNotificationSettingsRepo) ReleaseDigest(ctx context.Context, userLogin string, last *time.Time, lastDay string, claimed time.Time) error {
+	set, unset := bson.M{}, bson.M{}
+	if last != nil {
+		set["last_digest_at"] = *last
+	} else {
+		unset["last_digest_at"] = ""
+	}
+	if lastDay != "" {
+		set["last_digest_day"] = lastDay
+	} else {
+		unset["last_digest_day"] = ""
+	}
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	_, err := r.SettingsColl.UpdateOne(ctx, bson.M{"_id": userLogin, "last_digest_at": claimed}, update)
+	if err != nil {
+		return fmt.Errorf("ReleaseDigest error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoNotificationSettingsRepo) DeleteSettings(ctx context.Context, userLogin string) error {
+	_, err := r.SettingsColl.DeleteOne(ctx, bson.M{"_id": userLogin})
+	if err != nil {
+		return fmt.Errorf("DeleteSettings error: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MongoOIDCRepo struct {
+	StateColl        *mongo.Collection
+	RegistrationColl *mongo.Collection
+}
+
+func NewMongoOIDCRepo(db *mongo.Client) *MongoOIDCRepo {
+	return &MongoOIDCRepo{
+		StateColl:        db.Database(dbname).Collection(oidcStateCollection),
+		RegistrationColl: db.Database(dbname).Collection(oidcRegistrationCollection),
+	}
+}
+
+func (r *MongoOIDCRepo) SaveState(ctx context.Context, state models.OIDCState) error {
+	_, err := r.StateColl.InsertOne(ctx, state)
+	if err != nil {
+		return fmt.Errorf("SaveState error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoOIDCRepo) PopState(ctx context.Context, state string) (*models.OIDCState, error) {
+	var oidcState models.OIDCState
+	filter := bson.M{
+		"$and": []bson.M{
+			{"state": state},
+			{"expires_at": bson.M{"$gt": time.Now().UTC()}},
+		},
+	}
+	err := r.StateColl.FindOneAndDelete(ctx, filter).Decode(&oidcState)
+	if err != nil {
+		return nil, fmt.Errorf("PopState error: %v", err)
+	}
+	return &oidcState, nil
+}
+
+func (r *MongoOIDCRepo) SaveRegistration(ctx context.Context, reg models.OIDCRegistration) error {
+	_, err := r.RegistrationColl.InsertOne(ctx, reg)
+	if err != nil {
+		return fmt.Errorf("SaveRegistration error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoOIDCRepo) GetRegistration(ctx context.Context, ticket string) (*models.OIDCRegistration, error) {
+	var reg models.OIDCRegistration
+	filter := bson.M{
+		"$and": []bson.M{
+			{"ticket": ticket},
+			{"expires_at": bson.M{"$gt": time.Now().UTC()}},
+		},
+	}
+	err := r.RegistrationColl.FindOne(ctx, filter).Decode(&reg)
+	if err != nil {
+		return nil, fmt.Errorf("GetRegistration error: %v", err)
+	}
+	return &reg, nil
+}
+
+func (r *MongoOIDCRepo) PopRegistration(ctx context.Context, ticket string) (*models.OIDCRegistration, error) {
+	var reg models.OIDCRegistration
+	filter := bson.M{
+		"$and": []bson.M{
+			{"ticket": ticket},
+			{"expires_at": bson.M{"$gt": time.Now().UTC()}},
+		},
+	}
+	err := r.RegistrationColl.FindOneAndDelete(ctx, filter).Decode(&reg)
+	if err != nil {
+		return nil, fmt.Errorf("PopRegistration error: %v", err)
+	}
+	return &reg, nil
+}
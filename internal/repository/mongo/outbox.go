@@ -0,0 +1,112 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoOutboxRepo struct {
+	OutboxColl *mongo.Collection
+	// Written is called after an event is saved outside of a transaction,
+	// events saved in a transaction are announced by the transactor after the commit
+	Written func()
+}
+
+func NewMongoOutboxRepo(db *mongo.Client) *MongoOutboxRepo {
+	return &MongoOutboxRepo{OutboxColl: db.Database(dbname).Collection(outboxCollection)}
+}
+
+// Publish saves the event to the outbox, in a transaction it is committed or rolled back with the change
+func (r *MongoOutboxRepo) Publish(ctx context.Context, event models.Event) error {
+	payload, err := bson.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("Publish error: %v", err)
+	}
+	_, err = r.OutboxColl.InsertOne(ctx, models.OutboxEvent{
+		ID:        primitive.NewObjectID(),
+		Name:      event.EventName(),
+		Payload:   payload,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("Publish error: %v", err)
+	}
+	if mongo.SessionFromContext(ctx) == nil && r.Written != nil {
+		r.Written()
+	}
+	return nil
+}
+
+// GetPendingEvents returns events not dispatched yet, oldest first
+func (r *MongoOutboxRepo) GetPendingEvents(ctx context.Context, limit int64) ([]models.OutboxEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := r.OutboxColl.Find(ctx, bson.M{"dispatched_at": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingEvents error: %v", err)
+	}
+	defer cursor.Close(ctx)
+	var events []models.OutboxEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("GetPendingEvents error: %v", err)
+	}
+	return events, nil
+}
+
+func (r *MongoOutboxRepo) MarkDispatched(ctx context.Context, eventID primitive.ObjectID, at time.Time, eventErr string) error {
+	set := bson.M{"dispatched_at": at}
+	if eventErr != "" {
+		set["error"] = eventErr
+	}
+	_, err := r.OutboxColl.UpdateOne(ctx, bson.M{"_id": eventID}, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("MarkDispatched error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoOutboxRepo) PurgeDispatched(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.OutboxColl.DeleteMany(ctx, bson.M{"dispatched_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, fmt.Errorf("PurgeDispatched error: %v", err)
+	}
+	return result.DeletedCount, nil
+}
+
+// MongoTransactor runs changes of several documents in one transaction, it needs a replica set
+type MongoTransactor struct {
+	Client *mongo.Client
+	// Committed is called after every committed transaction
+	Committed func()
+}
+
+func NewMongoTransactor(db *mongo.Client) *MongoTransactor {
+	return &MongoTransactor{Client: db}
+}
+
+// WithTransaction commits what fn does with ctx, or nothing if fn fails.
+// A call inside a running transaction joins it, so services can use each other
+func (t *MongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	err := t.Client.UseSession(ctx, func(sc mongo.SessionContext) error {
+		_, err := sc.WithTransaction(sc, func(sc mongo.SessionContext) (interface{}, error) {
+			return nil, fn(sc)
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("WithTransaction error: %v", err)
+	}
+	if t.Committed != nil {
+		t.Committed()
+	}
+	return nil
+}
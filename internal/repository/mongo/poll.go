@@ -178,3 +178,78 @@ func (r *MongoPollRepo) RemoveVote(ctx context.Context, pollID, userLogin string
 	}
 	return nil
 }
+
+// staleRunFilter matches polls without a result and polls whose action was claimed before staleBefore
+// and never finished, claims without time were made before the time was saved
+func staleRunFilter(staleBefore time.Time) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"result": bson.M{"$exists": false}},
+		{"result.status": models.PollResultRunning, "$or": []bson.M{
+			{"result.claimed_at": bson.M{"$lt": staleBefore}},
+			{"result.claimed_at": bson.M{"$exists": false}},
+		}},
+	}}
+}
+
+// GetPollsToFinalize returns closed polls whose action has not been run yet or whose run is stale
+func (r *MongoPollRepo) GetPollsToFinalize(ctx context.Context, staleBefore time.Time) ([]models.Poll, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"$and": []bson.M{
+			staleRunFilter(staleBefore),
+			{"$or": []bson.M{
+				{"firstAction": bson.M{"$exists": true}},
+				{"secondAction": bson.M{"$exists": true}},
+			}},
+			{"$or": []bson.M{
+				{"endtime": bson.M{"$lt": now}},
+				{"isEarlyClosed": true},
+			}},
+		},
+	}
+	cursor, err := r.PollColl.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("GetPollsToFinalize error: %v", err)
+	}
+	var polls []models.Poll
+	if err := cursor.All(ctx, &polls); err != nil {
+		return nil, fmt.Errorf("GetPollsToFinalize error, cursor.All(): %v", err)
+	}
+	return polls, nil
+}
+
+// ClaimPollResult marks the action as running, false means somebody else already runs it.
+// A run claimed before staleBefore that never finished is claimed again
+func (r *MongoPollRepo) ClaimPollResult(ctx context.Context, pollID string, staleBefore time.Time) (bool, error) {
+	oid, err := convertToObjectIDs(pollID)
+	if err != nil {
+		return false, fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			staleRunFilter(staleBefore),
+		},
+	}
+	now := time.Now().UTC()
+	result := models.PollResult{Status: models.PollResultRunning, FinishedAt: now, ClaimedAt: &now}
+	update := bson.M{"$set": bson.M{"result": result}}
+	res, err := r.PollColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("ClaimPollResult error: %v", err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (r *MongoPollRepo) SetPollResult(ctx context.Context, pollID string, result models.PollResult) error {
+	oid, err := convertToObjectIDs(pollID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	update := bson.M{"$set": bson.M{"result": result}}
+	_, err = r.PollColl.UpdateOne(ctx, bson.M{"_id": oid[0]}, update)
+	if err != nil {
+		return fmt.Errorf("SetPollResult error: %v", err)
+	}
+	return nil
+}
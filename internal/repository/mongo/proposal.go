@@ -0,0 +1,139 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoProposalRepo struct {
+	ProposalColl *mongo.Collection
+}
+
+func NewMongoProposalRepo(db *mongo.Client) *MongoProposalRepo {
+	return &MongoProposalRepo{ProposalColl: db.Database(dbname).Collection(proposalCollection)}
+}
+
+func (r *MongoProposalRepo) AddProposal(ctx context.Context, proposal models.TaskProposal) error {
+	_, err := r.ProposalColl.InsertOne(ctx, proposal)
+	if err != nil {
+		return fmt.Errorf("AddProposal error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoProposalRepo) GetProposals(ctx context.Context, filter models.ProposalFilter) ([]models.TaskProposal, error) {
+	oid, err := convertToObjectIDs(filter.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	conditions := []bson.M{{"group_id": oid[0]}}
+	if filter.Status != "" {
+		conditions = append(conditions, bson.M{"status": filter.Status})
+	}
+	if filter.ProposedBy != "" {
+		conditions = append(conditions, bson.M{"proposed_by": filter.ProposedBy})
+	}
+	proposals := []models.TaskProposal{}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.ProposalColl.Find(ctx, bson.M{"$and": conditions}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("GetProposals error: %v", err)
+	}
+	err = cursor.All(ctx, &proposals)
+	if err != nil {
+		return nil, fmt.Errorf("GetProposals all() error: %v", err)
+	}
+	return proposals, nil
+}
+
+func (r *MongoProposalRepo) CountPending(ctx context.Context, groupID, userLogin string) (int64, error) {
+	oid, err := convertToObjectIDs(groupID)
+	if err != nil {
+		return 0, fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"group_id": oid[0]},
+			{"proposed_by": userLogin},
+			{"status": models.ProposalPending},
+		},
+	}
+	count, err := r.ProposalColl.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("CountPending error: %v", err)
+	}
+	return count, nil
+}
+
+func (r *MongoProposalRepo) GetProposal(ctx context.Context, proposalID, groupID string) (*models.TaskProposal, error) {
+	oid, err := convertToObjectIDs(proposalID, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	var proposal models.TaskProposal
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"group_id": oid[1]},
+		},
+	}
+	err = r.ProposalColl.FindOne(ctx, filter).Decode(&proposal)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetProposal error: %v", err)
+	}
+	return &proposal, nil
+}
+
+// UpdateProposalTask changes the proposed task while the proposal is still pending
+func (r *MongoProposalRepo) UpdateProposalTask(ctx context.Context, proposalID string, task models.Task, editedBy string) (bool, error) {
+	oid, err := convertToObjectIDs(proposalID)
+	if err != nil {
+		return false, fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"status": models.ProposalPending},
+		},
+	}
+	update := bson.M{"$set": bson.M{"task": task, "edited_by": editedBy}}
+	result, err := r.ProposalColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("UpdateProposalTask error: %v", err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// ReviewProposal records the decision, only a pending proposal can be reviewed so two reviewers cant both approve it
+func (r *MongoProposalRepo) ReviewProposal(ctx context.Context, proposalID, status, reviewer, reason string) (bool, error) {
+	oid, err := convertToObjectIDs(proposalID)
+	if err != nil {
+		return false, fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"status": models.ProposalPending},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"reviewed_by": reviewer,
+		"reviewed_at": time.Now().UTC(),
+		"reason":      reason,
+	}}
+	result, err := r.ProposalColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("ReviewProposal error: %v", err)
+	}
+	return result.MatchedCount > 0, nil
+}
@@ -0,0 +1,62 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoReminderRepo struct {
+	ReminderColl *mongo.Collection
+}
+
+func NewMongoReminderRepo(db *mongo.Client) *MongoReminderRepo {
+	return &MongoReminderRepo{ReminderColl: db.Database(dbname).Collection(reminderCollection)}
+}
+
+func overrideKey(groupID, userLogin string) string {
+	return groupID + ":" + userLogin
+}
+
+func (r *MongoReminderRepo) SetOverride(ctx context.Context, override models.ReminderOverride) error {
+	update := bson.M{"$set": override}
+	_, err := r.ReminderColl.UpdateOne(ctx, bson.M{"_id": overrideKey(override.GroupID, override.UserLogin)},
+		update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("SetOverride error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoReminderRepo) DeleteOverride(ctx context.Context, groupID, userLogin string) error {
+	_, err := r.ReminderColl.DeleteOne(ctx, bson.M{"_id": overrideKey(groupID, userLogin)})
+	if err != nil {
+		return fmt.Errorf("DeleteOverride error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoReminderRepo) GetOverrides(ctx context.Context, groupID string) ([]models.ReminderOverride, error) {
+	overrides := []models.ReminderOverride{}
+	cursor, err := r.ReminderColl.Find(ctx, bson.M{"group_id": groupID})
+	if err != nil {
+		return nil, fmt.Errorf("GetOverrides error: %v", err)
+	}
+	err = cursor.All(ctx, &overrides)
+	if err != nil {
+		return nil, fmt.Errorf("GetOverrides all() error: %v", err)
+	}
+	return overrides, nil
+}
+
+func (r *MongoReminderRepo) DeleteUserOverrides(ctx context.Context, userLogin string) error {
+	_, err := r.ReminderColl.DeleteMany(ctx, bson.M{"user_login": userLogin})
+	if err != nil {
+		return fmt.Errorf("DeleteUserOverrides error: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoRevisionRepo struct {
+	RevisionColl *mongo.Collection
+}
+
+func NewMongoRevisionRepo(db *mongo.Client) *MongoRevisionRepo {
+	return &MongoRevisionRepo{RevisionColl: db.Database(dbname).Collection(revisionCollection)}
+}
+
+func (r *MongoRevisionRepo) AddRevision(ctx context.Context, revision models.TaskRevision) error {
+	_, err := r.RevisionColl.InsertOne(ctx, revision)
+	if err != nil {
+		return fmt.Errorf("AddRevision error: %v", err)
+	}
+	return nil
+}
+
+var newestRevisionsFirst = bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+
+func (r *MongoRevisionRepo) GetRevisions(ctx context.Context, groupID, taskID string) ([]models.TaskRevision, error) {
+	oid, err := convertToObjectIDs(groupID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"group_id": oid[0]},
+			{"task_id": oid[1]},
+		},
+	}
+	revisions := []models.TaskRevision{}
+	cursor, err := r.RevisionColl.Find(ctx, filter, options.Find().SetSort(newestRevisionsFirst))
+	if err != nil {
+		return nil, fmt.Errorf("GetRevisions error: %v", err)
+	}
+	err = cursor.All(ctx, &revisions)
+	if err != nil {
+		return nil, fmt.Errorf("GetRevisions all() error: %v", err)
+	}
+	return revisions, nil
+}
+
+func (r *MongoRevisionRepo) GetLastRevision(ctx context.Context, groupID, taskID string) (*models.TaskRevision, error) {
+	oid, err := convertToObjectIDs(groupID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"group_id": oid[0]},
+			{"task_id": oid[1]},
+		},
+	}
+	var revision models.TaskRevision
+	err = r.RevisionColl.FindOne(ctx, filter, options.FindOne().SetSort(newestRevisionsFirst)).Decode(&revision)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetLastRevision error: %v", err)
+	}
+	return &revision, nil
+}
+
+// GetDeletedTasks returns the last revisions of tasks of the group that are deletions made since the time
+func (r *MongoRevisionRepo) GetDeletedTasks(ctx context.Context, groupID string, since time.Time) ([]models.TaskRevision, error) {
+	oid, err := convertToObjectIDs(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"group_id": oid[0]}}},
+		{{Key: "$sort", Value: newestRevisionsFirst}},
+		{{Key: "$group", Value: bson.M{"_id": "$task_id", "last": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$last"}}},
+		{{Key: "$match", Value: bson.M{"change": models.RevisionDeleted, "created_at": bson.M{"$gte": since}}}},
+		{{Key: "$sort", Value: newestRevisionsFirst}},
+	}
+	revisions := []models.TaskRevision{}
+	cursor, err := r.RevisionColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("GetDeletedTasks error: %v", err)
+	}
+	err = cursor.All(ctx, &revisions)
+	if err != nil {
+		return nil, fmt.Errorf("GetDeletedTasks all() error: %v", err)
+	}
+	return revisions, nil
+}
+
+func (r *MongoRevisionRepo) PurgeRevisions(ctx context.Context, createdBefore time.Time) (int64, error) {
+	result, err := r.RevisionColl.DeleteMany(ctx, bson.M{"created_at": bson.M{"$lt": createdBefore}})
+	if err != nil {
+		return 0, fmt.Errorf("PurgeRevisions error: %v", err)
+	}
+	return result.DeletedCount, nil
+}
@@ -100,6 +100,12 @@ func (r *MongoTaskRepo) UpdateTask(ctx context.Context, taskID string, newTask m
 	if !newTask.EndTime.IsZero() {
 		update["end_time"] = newTask.EndTime
 	}
+	if newTask.Location != nil {
+		update["location"] = newTask.Location
+	}
+	if newTask.Recurrence != nil {
+		update["recurrence"] = newTask.Recurrence
+	}
 	updateQuery := bson.M{
 		"$set": update,
 	}
@@ -110,3 +116,93 @@ func (r *MongoTaskRepo) UpdateTask(ctx context.Context, taskID string, newTask m
 	}
 	return nil
 }
+
+func (r *MongoTaskRepo) SetDependencies(ctx context.Context, taskID string, dependencies []models.Dependency) error {
+	oid, err := convertToObjectIDs(taskID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	_, err = r.TaskColl.UpdateOne(ctx, bson.M{"_id": oid[0]}, bson.M{"$set": bson.M{"depends_on": dependencies}})
+	if err != nil {
+		return fmt.Errorf("SetDependencies error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoTaskRepo) RemoveDependenciesOn(ctx context.Context, taskID string) error {
+	oid, err := convertToObjectIDs(taskID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{"depends_on.task_id": oid[0]}
+	update := bson.M{"$pull": bson.M{"depends_on": bson.M{"task_id": oid[0]}}}
+	_, err = r.TaskColl.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("RemoveDependenciesOn error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoTaskRepo) SetTaskStatus(ctx context.Context, taskID, status string) error {
+	oid, err := convertToObjectIDs(taskID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	_, err = r.TaskColl.UpdateOne(ctx, bson.M{"_id": oid[0]}, bson.M{"$set": bson.M{"status": status}})
+	if err != nil {
+		return fmt.Errorf("SetTaskStatus error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoTaskRepo) AddChecklistItem(ctx context.Context, taskID string, item models.ChecklistItem) error {
+	oid, err := convertToObjectIDs(taskID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	_, err = r.TaskColl.UpdateOne(ctx, bson.M{"_id": oid[0]}, bson.M{"$push": bson.M{"checklist": item}})
+	if err != nil {
+		return fmt.Errorf("AddChecklistItem error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoTaskRepo) UpdateChecklistItem(ctx context.Context, taskID string, item models.ChecklistItem) error {
+	oid, err := convertToObjectIDs(taskID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"_id": oid[0]},
+			{"checklist._id": item.ID},
+		},
+	}
+	_, err = r.TaskColl.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"checklist.$": item}})
+	if err != nil {
+		return fmt.Errorf("UpdateChecklistItem error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoTaskRepo) DeleteChecklistItem(ctx context.Context, taskID, itemID string) error {
+	oid, err := convertToObjectIDs(taskID, itemID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	update := bson.M{"$pull": bson.M{"checklist": bson.M{"_id": oid[1]}}}
+	_, err = r.TaskColl.UpdateOne(ctx, bson.M{"_id": oid[0]}, update)
+	if err != nil {
+		return fmt.Errorf("DeleteChecklistItem error: %v", err)
+	}
+	return nil
+}
+
+// ReplaceTask saves the whole task, fields missing in it are removed
+func (r *MongoTaskRepo) ReplaceTask(ctx context.Context, task models.Task) error {
+	_, err := r.TaskColl.ReplaceOne(ctx, bson.M{"_id": task.ID}, task)
+	if err != nil {
+		return fmt.Errorf("ReplaceTask error: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MongoTokenRepo struct {
+	TokenColl *mongo.Collection
+}
+
+func NewMongoTokenRepo(db *mongo.Client) *MongoTokenRepo {
+	return &MongoTokenRepo{TokenColl: db.Database(dbname).Collection(revokedTokenCollection)}
+}
+
+func (r *MongoTokenRepo) RevokeToken(ctx context.Context, token models.RevokedToken) error {
+	_, err := r.TokenColl.InsertOne(ctx, token)
+	if err != nil {
+		return fmt.Errorf("RevokeToken error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoTokenRepo) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	err := r.TokenColl.FindOne(ctx, bson.M{"token_id": tokenID}).Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, fmt.Errorf("IsTokenRevoked error: %v", err)
+	}
+	return true, nil
+}
+
+// PurgeRevokedTokens deletes records of tokens that are expired anyway
+func (r *MongoTokenRepo) PurgeRevokedTokens(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result, err := r.TokenColl.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lt": expiredBefore}})
+	if err != nil {
+		return 0, fmt.Errorf("PurgeRevokedTokens error: %v", err)
+	}
+	return result.DeletedCount, nil
+}
@@ -3,18 +3,27 @@ package mongorepo
 import (
 	"JourneyPlanner/internal/models"
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoUserRepo struct {
-	UserColl *mongo.Collection
+	UserColl         *mongo.Collection
+	DeletedLoginColl *mongo.Collection
 }
 
 func NewMongoUserRepo(db *mongo.Client) *MongoUserRepo {
-	return &MongoUserRepo{UserColl: db.Database(dbname).Collection(userCollection)}
+	return &MongoUserRepo{
+		UserColl:         db.Database(dbname).Collection(userCollection),
+		DeletedLoginColl: db.Database(dbname).Collection(deletedLoginCollection),
+	}
 }
 
 func (r *MongoUserRepo) CreateUser(ctx context.Context, user models.User) error {
@@ -25,11 +34,15 @@ func (r *MongoUserRepo) CreateUser(ctx context.Context, user models.User) error
 	return nil
 }
 
+// GetUserByEmail returns nil without an error when no account has the email
 func (r *MongoUserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	filter := bson.M{"email": email}
 	err := r.UserColl.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
 		return nil, fmt.Errorf("GetUserByEmail error: %v", err)
 	}
 	return &user, nil
@@ -58,3 +71,227 @@ func (r *MongoUserRepo) GetUserByID(ctx context.Context, id string) (*models.Use
 	}
 	return &user, nil
 }
+
+func (r *MongoUserRepo) GetUserByOIDC(ctx context.Context, issuer, subject string) (*models.User, error) {
+	var user models.User
+	filter := bson.M{
+		"$and": []bson.M{
+			{"oidc_issuer": issuer},
+			{"oidc_subject": subject},
+		},
+	}
+	err := r.UserColl.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetUserByOIDC error: %v", err)
+	}
+	return &user, nil
+}
+
+func (r *MongoUserRepo) LinkOIDCIdentity(ctx context.Context, login, issuer, subject string) error {
+	filter := bson.M{"login": login}
+	update := bson.M{"$set": bson.M{
+		"oidc_issuer":    issuer,
+		"oidc_subject":   subject,
+		"email_verified": true,
+	}}
+	_, err := r.UserColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("LinkOIDCIdentity error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoUserRepo) UpdateProfile(ctx context.Context, login string, profile models.UpdateProfile) error {
+	update := bson.M{}
+	if profile.DisplayName != nil {
+		update["profile.display_name"] = *profile.DisplayName
+	}
+	if profile.AvatarURL != nil {
+		update["profile.avatar_url"] = *profile.AvatarURL
+	}
+	if profile.Timezone != nil {
+		update["profile.timezone"] = *profile.Timezone
+	}
+	if profile.Locale != nil {
+		update["profile.locale"] = *profile.Locale
+	}
+	if profile.Bio != nil {
+		update["profile.bio"] = *profile.Bio
+	}
+	_, err := r.UserColl.UpdateOne(ctx, bson.M{"login": login}, bson.M{"$set": update})
+	if err != nil {
+		return fmt.Errorf("UpdateProfile error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoUserRepo) SetPendingEmail(ctx context.Context, login, email, tokenHash string, expires time.Time) error {
+	update := bson.M{"$set": bson.M{
+		"pending_email":       email,
+		"email_token_hash":    tokenHash,
+		"email_token_expires": expires,
+	}}
+	_, err := r.UserColl.UpdateOne(ctx, bson.M{"login": login}, update)
+	if err != nil {
+		return fmt.Errorf("SetPendingEmail error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoUserRepo) GetUserByEmailToken(ctx context.Context, tokenHash string) (*models.User, error) {
+	var user models.User
+	filter := bson.M{
+		"$and": []bson.M{
+			{"email_token_hash": tokenHash},
+			{"email_token_expires": bson.M{"$gt": time.Now().UTC()}},
+		},
+	}
+	err := r.UserColl.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserByEmailToken error: %v", err)
+	}
+	return &user, nil
+}
+
+func (r *MongoUserRepo) ConfirmEmail(ctx context.Context, login, email string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"email":          email,
+			"email_verified": true,
+		},
+		"$unset": bson.M{
+			"pending_email":       "",
+			"email_token_hash":    "",
+			"email_token_expires": "",
+		},
+	}
+	_, err := r.UserColl.UpdateOne(ctx, bson.M{"login": login}, update)
+	if err != nil {
+		return fmt.Errorf("ConfirmEmail error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoUserRepo) RevokeSessions(ctx context.Context, login string, revokedAt time.Time) error {
+	update := bson.M{"$set": bson.M{"sessions_revoked_at": revokedAt}}
+	_, err := r.UserColl.UpdateOne(ctx, bson.M{"login": login}, update)
+	if err != nil {
+		return fmt.Errorf("RevokeSessions error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoUserRepo) SetDeleteToken(ctx context.Context, login, tokenHash string, expires time.Time) error {
+	update := bson.M{"$set": bson.M{
+		"delete_token_hash":    tokenHash,
+		"delete_token_expires": expires,
+	}}
+	_, err := r.UserColl.UpdateOne(ctx, bson.M{"login": login}, update)
+	if err != nil {
+		return fmt.Errorf("SetDeleteToken error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoUserRepo) GetUserByDeleteToken(ctx context.Context, tokenHash string) (*models.User, error) {
+	var user models.User
+	filter := bson.M{
+		"$and": []bson.M{
+			{"delete_token_hash": tokenHash},
+			{"delete_token_expires": bson.M{"$gt": time.Now().UTC()}},
+		},
+	}
+	err := r.UserColl.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserByDeleteToken error: %v", err)
+	}
+	return &user, nil
+}
+
+// ReserveLogin keeps the login of a deleted account, so nobody can register it again
+func (r *MongoUserRepo) ReserveLogin(ctx context.Context, login string, deletedAt time.Time) error {
+	update := bson.M{"$setOnInsert": bson.M{"deleted_at": deletedAt}}
+	_, err := r.DeletedLoginColl.UpdateOne(ctx, bson.M{"_id": login}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("ReserveLogin error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoUserRepo) IsLoginReserved(ctx context.Context, login string) (bool, error) {
+	count, err := r.DeletedLoginColl.CountDocuments(ctx, bson.M{"_id": login})
+	if err != nil {
+		return false, fmt.Errorf("IsLoginReserved error: %v", err)
+	}
+	return count > 0, nil
+}
+
+func (r *MongoUserRepo) DeleteUser(ctx context.Context, login string) error {
+	_, err := r.UserColl.DeleteOne(ctx, bson.M{"login": login})
+	if err != nil {
+		return fmt.Errorf("DeleteUser error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoUserRepo) UpdatePrivacy(ctx context.Context, login string, privacy models.Privacy) error {
+	update := bson.M{"$set": bson.M{"privacy": privacy}}
+	_, err := r.UserColl.UpdateOne(ctx, bson.M{"login": login}, update)
+	if err != nil {
+		return fmt.Errorf("UpdatePrivacy error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoUserRepo) SearchUsers(ctx context.Context, search models.UserSearch) ([]models.User, error) {
+	if search.ExcludeLogins == nil {
+		search.ExcludeLogins = []string{}
+	}
+	if search.ContactLogins == nil {
+		search.ContactLogins = []string{}
+	}
+	prefix := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(search.Prefix), Options: "i"}
+	filter := bson.M{
+		"$and": []bson.M{
+			{"$or": []bson.M{
+				{"login": prefix},
+				{"profile.display_name": prefix},
+			}},
+			{"login": bson.M{"$nin": search.ExcludeLogins}},
+			{"$or": []bson.M{
+				{"privacy.search_visibility": bson.M{"$in": []interface{}{nil, "", models.VisibilityEveryone}}},
+				{
+					"privacy.search_visibility": models.VisibilityContacts,
+					"login":                     bson.M{"$in": search.ContactLogins},
+				},
+			}},
+		},
+	}
+	opts := options.Find().SetLimit(search.Limit).SetSort(bson.M{"login": 1})
+	cursor, err := r.UserColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("SearchUsers error: %v", err)
+	}
+	var users []models.User
+	err = cursor.All(ctx, &users)
+	if err != nil {
+		return nil, fmt.Errorf("SearchUsers all() error: %v", err)
+	}
+	return users, nil
+}
+
+func (r *MongoUserRepo) GetUsersByLogins(ctx context.Context, logins []string) ([]models.User, error) {
+	cursor, err := r.UserColl.Find(ctx, bson.M{"login": bson.M{"$in": logins}})
+	if err != nil {
+		return nil, fmt.Errorf("GetUsersByLogins error: %v", err)
+	}
+	var users []models.User
+	err = cursor.All(ctx, &users)
+	if err != nil {
+		return nil, fmt.Errorf("GetUsersByLogins all() error: %v", err)
+	}
+	return users, nil
+}
@@ -0,0 +1,155 @@
+package mongorepo
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoWebhookRepo struct {
+	WebhookColl  *mongo.Collection
+	DeliveryColl *mongo.Collection
+}
+
+func NewMongoWebhookRepo(db *mongo.Client) *MongoWebhookRepo {
+	return &MongoWebhookRepo{
+		WebhookColl:  db.Database(dbname).Collection(webhookCollection),
+		DeliveryColl: db.Database(dbname).Collection(webhookDeliveryCollection),
+	}
+}
+
+func (r *MongoWebhookRepo) AddWebhook(ctx context.Context, webhook models.Webhook) error {
+	_, err := r.WebhookColl.InsertOne(ctx, webhook)
+	if err != nil {
+		return fmt.Errorf("AddWebhook error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoWebhookRepo) GetWebhooks(ctx context.Context, groupID string) ([]models.Webhook, error) {
+	webhooks := []models.Webhook{}
+	cursor, err := r.WebhookColl.Find(ctx, bson.M{"group_id": groupID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("GetWebhooks error: %v", err)
+	}
+	err = cursor.All(ctx, &webhooks)
+	if err != nil {
+		return nil, fmt.Errorf("GetWebhooks all() error: %v", err)
+	}
+	return webhooks, nil
+}
+
+// GetEventWebhooks returns webhooks of the group subscribed to the event
+func (r *MongoWebhookRepo) GetEventWebhooks(ctx context.Context, groupID, event string) ([]models.Webhook, error) {
+	filter := bson.M{
+		"$and": []bson.M{
+			{"group_id": groupID},
+			{"events": event},
+		},
+	}
+	webhooks := []models.Webhook{}
+	cursor, err := r.WebhookColl.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("GetEventWebhooks error: %v", err)
+	}
+	err = cursor.All(ctx, &webhooks)
+	if err != nil {
+		return nil, fmt.Errorf("GetEventWebhooks all() error: %v", err)
+	}
+	return webhooks, nil
+}
+
+func (r *MongoWebhookRepo) GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	oid, err := convertToObjectIDs(webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	var webhook models.Webhook
+	err = r.WebhookColl.FindOne(ctx, bson.M{"_id": oid[0]}).Decode(&webhook)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetWebhook error: %v", err)
+	}
+	return &webhook, nil
+}
+
+func (r *MongoWebhookRepo) DeleteWebhook(ctx context.Context, webhookID string) error {
+	oid, err := convertToObjectIDs(webhookID)
+	if err != nil {
+		return fmt.Errorf("InvalidID: %v", err)
+	}
+	_, err = r.WebhookColl.DeleteOne(ctx, bson.M{"_id": oid[0]})
+	if err != nil {
+		return fmt.Errorf("DeleteWebhook error: %v", err)
+	}
+	return nil
+}
+
+// AddDelivery saves the delivery unless there is one with the same key, and returns the saved one
+func (r *MongoWebhookRepo) AddDelivery(ctx context.Context, delivery models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	if delivery.Key == "" {
+		_, err := r.DeliveryColl.InsertOne(ctx, delivery)
+		if err != nil {
+			return nil, fmt.Errorf("AddDelivery error: %v", err)
+		}
+		return &delivery, nil
+	}
+	var saved models.WebhookDelivery
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.DeliveryColl.FindOneAndUpdate(ctx, bson.M{"key": delivery.Key}, bson.M{"$setOnInsert": delivery}, opts).Decode(&saved)
+	if err != nil {
+		return nil, fmt.Errorf("AddDelivery error: %v", err)
+	}
+	return &saved, nil
+}
+
+func (r *MongoWebhookRepo) GetDelivery(ctx context.Context, deliveryID string) (*models.WebhookDelivery, error) {
+	oid, err := convertToObjectIDs(deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidID: %v", err)
+	}
+	var delivery models.WebhookDelivery
+	err = r.DeliveryColl.FindOne(ctx, bson.M{"_id": oid[0]}).Decode(&delivery)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetDelivery error: %v", err)
+	}
+	return &delivery, nil
+}
+
+// AddDeliveryAttempt logs the attempt and sets the status the delivery has after it
+func (r *MongoWebhookRepo) AddDeliveryAttempt(ctx context.Context, deliveryID primitive.ObjectID, attempt models.WebhookAttempt, status string) error {
+	update := bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$set":  bson.M{"status": status},
+	}
+	_, err := r.DeliveryColl.UpdateOne(ctx, bson.M{"_id": deliveryID}, update)
+	if err != nil {
+		return fmt.Errorf("AddDeliveryAttempt error: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoWebhookRepo) GetDeliveries(ctx context.Context, webhookID string, limit int64) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := r.DeliveryColl.Find(ctx, bson.M{"webhook_id": webhookID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("GetDeliveries error: %v", err)
+	}
+	err = cursor.All(ctx, &deliveries)
+	if err != nil {
+		return nil, fmt.Errorf("GetDeliveries all() error: %v", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	clockFormat          = "15:04"
+	maxActivitiesInGroup = 200
+	// a plan can be accepted as it was shown within this time, later it has to be made again
+	activityPlanTTL = time.Hour
+)
+
+type ActivityRepository interface {
+	AddActivity(ctx context.Context, activity models.Activity) error
+	GetActivities(ctx context.Context, groupID string) ([]models.Activity, error)
+	GetActivity(ctx context.Context, activityID, groupID string) (*models.Activity, error)
+	DeleteActivity(ctx context.Context, activityID string) error
+	SavePlan(ctx context.Context, plan models.ActivityPlan) error
+	GetPlan(ctx context.Context, planID, groupID string) (*models.ActivityPlan, error)
+}
+
+// TaskCreator turns accepted activities into tasks with all the usual checks
+type TaskCreator interface {
+	CreateTask(ctx context.Context, taskInfo models.CreateTask, userLogin string) error
+}
+
+type ActivitySrv struct {
+	Activity ActivityRepository
+	Group    GroupRepository
+	Task     TaskRepository
+	Tasks    TaskCreator
+	Speeds   TravelSpeeds
+	Tx       Transactor
+}
+
+func NewActivitySrv(activityRepo ActivityRepository, groupRepo GroupRepository,
+	taskRepo TaskRepository, tasks TaskCreator, speeds TravelSpeeds, tx Transactor) *ActivitySrv {
+	return &ActivitySrv{Activity: activityRepo, Group: groupRepo, Task: taskRepo, Tasks: tasks, Speeds: speeds, Tx: tx}
+}
+
+func (s *ActivitySrv) AddActivity(ctx context.Context, userLogin string, info models.CreateActivity) error {
+	group, err := s.Group.GetGroup(ctx, info.GroupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	duration := calculateDuration(info.Duration)
+	if duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+	if err := validateCoordinates(info.Location); err != nil {
+		return err
+	}
+	activity := models.Activity{
+		GroupID:   group.ID,
+		Title:     info.Title,
+		Duration:  duration,
+		Priority:  info.Priority,
+		Location:  info.Location,
+		AddedBy:   userLogin,
+		CreatedAt: time.Now().UTC(),
+	}
+	if activity.NotBefore, err = parseTripDate(info.NotBefore); err != nil {
+		return err
+	}
+	if activity.NotAfter, err = parseTripDate(info.NotAfter); err != nil {
+		return err
+	}
+	if activity.NotBefore != nil && activity.NotAfter != nil && activity.NotAfter.Before(*activity.NotBefore) {
+		return errors.New("not_after cant be earlier than not_before")
+	}
+	if info.Opens != "" || info.Closes != "" {
+		opens, err := time.Parse(clockFormat, info.Opens)
+		if err != nil {
+			return errors.New("invalid opening time, use HH:MM")
+		}
+		closes, err := time.Parse(clockFormat, info.Closes)
+		if err != nil {
+			return errors.New("invalid closing time, use HH:MM")
+		}
+		if !closes.After(opens) {
+			return errors.New("place must close after it opens")
+		}
+		if closes.Sub(opens) < time.Duration(duration)*time.Minute {
+			return errors.New("activity does not fit into opening hours")
+		}
+		activity.OpeningHours = &models.OpeningHours{Opens: info.Opens, Closes: info.Closes}
+	}
+	activities, err := s.Activity.GetActivities(ctx, info.GroupID)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if len(activities) >= maxActivitiesInGroup {
+		return fmt.Errorf("wishlist can have at most %d activities", maxActivitiesInGroup)
+	}
+	err = s.Activity.AddActivity(ctx, activity)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+func (s *ActivitySrv) GetActivities(ctx context.Context, groupID, userLogin string) ([]models.Activity, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	activities, err := s.Activity.GetActivities(ctx, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return activities, nil
+}
+
+func (s *ActivitySrv) DeleteActivity(ctx context.Context, activityID, groupID, userLogin string) error {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	activity, err := s.Activity.GetActivity(ctx, activityID, groupID)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if activity == nil {
+		return errors.New("activity was not found")
+	}
+	if group.LeaderLogin != userLogin && activity.AddedBy != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	err = s.Activity.DeleteActivity(ctx, activityID)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+func (s *ActivitySrv) PlanActivities(ctx context.Context, userLogin string, opts models.PlanOptions) (*models.ActivityPlan, error) {
+	group, err := s.Group.GetGroup(ctx, opts.GroupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	return s.plan(ctx, group, userLogin, opts)
+}
+
+// AcceptPlan creates tasks for a plan shown before, or only for the given activities of it. The tasks are
+// created as they were shown, all of them or none: if the time of one was taken since, a new plan has to be made
+func (s *ActivitySrv) AcceptPlan(ctx context.Context, userLogin, groupID, planID string,
+	activityIDs []string) (*models.AcceptedPlan, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return nil, errors.New("you have no permissions to do this")
+	}
+	plan, err := s.Activity.GetPlan(ctx, planID, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	if plan == nil {
+		return nil, errors.New("plan is expired or was not found, make a new plan")
+	}
+	for _, activityID := range activityIDs {
+		if !slices.ContainsFunc(plan.Planned, func(item models.PlannedActivity) bool {
+			return item.ActivityID.Hex() == activityID
+		}) {
+			return nil, fmt.Errorf("activity %s is not in the plan", activityID)
+		}
+	}
+	accepted := &models.AcceptedPlan{Created: []models.PlannedActivity{}}
+	for _, item := range plan.Planned {
+		if len(activityIDs) == 0 || slices.Contains(activityIDs, item.ActivityID.Hex()) {
+			accepted.Created = append(accepted.Created, item)
+		}
+	}
+	if len(accepted.Created) == 0 {
+		return nil, errors.New("plan has no activities to accept")
+	}
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		for _, item := range accepted.Created {
+			activity, err := s.Activity.GetActivity(ctx, item.ActivityID.Hex(), groupID)
+			if err != nil {
+				logs.Error(err)
+				return errors.New("System error")
+			}
+			if activity == nil {
+				return fmt.Errorf("%s was removed from the wishlist, make a new plan", item.Title)
+			}
+			err = s.Tasks.CreateTask(ctx, models.CreateTask{
+				GroupID:   groupID,
+				Title:     item.Title,
+				StartTime: taskStartInput(item.StartTime, group.Trip),
+				Duration:  models.Duration{DurMinutes: int(item.EndTime.Sub(item.StartTime) / time.Minute)},
+				Location:  item.Location,
+			}, userLogin)
+			if err != nil {
+				return fmt.Errorf("%s cant be planned anymore: %v, make a new plan", item.Title, err)
+			}
+			err = s.Activity.DeleteActivity(ctx, item.ActivityID.Hex())
+			if err != nil {
+				logs.Error(err)
+				return errors.New("System error")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return accepted, nil
+}
+
+// plan builds the plan and saves it, so the leader can accept exactly what was shown
+func (s *ActivitySrv) plan(ctx context.Context, group *models.Group, userLogin string, opts models.PlanOptions) (*models.ActivityPlan, error) {
+	if opts.Buffer < 0 || opts.Buffer > maxSlotBuffer {
+		return nil, errors.New("buffer must be between 0 and 720 minutes")
+	}
+	mode := opts.Mode
+	if mode == "" {
+		mode = defaultTravelMode
+	}
+	speed, ok := s.Speeds[mode]
+	if !ok {
+		return nil, fmt.Errorf("unknown travel mode: %s", mode)
+	}
+	from, to := group.Trip.Window()
+	if from.IsZero() || to.IsZero() {
+		return nil, errors.New("set the trip dates first")
+	}
+	if now := time.Now().UTC(); from.Before(now) {
+		from = now
+	}
+	if !to.After(from) {
+		return nil, errors.New("trip is already over")
+	}
+	groupID := group.ID.Hex()
+	activities, err := s.Activity.GetActivities(ctx, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	tasks, err := s.Task.GetTaskList(ctx, userLogin, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	planned, unscheduled := scheduleActivities(activities, expandTasks(tasks, group.Trip), from, to, group.Trip.Location(),
+		opts.QuietHours, time.Duration(opts.Buffer)*time.Minute, speed)
+	plan := models.ActivityPlan{
+		ID:          primitive.NewObjectID(),
+		GroupID:     group.ID,
+		TravelMode:  mode,
+		Planned:     planned,
+		Unscheduled: unscheduled,
+		ExpiresAt:   time.Now().UTC().Add(activityPlanTTL),
+	}
+	err = s.Activity.SavePlan(ctx, plan)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return &plan, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	maxAPIKeysPerUser = 20
+	apiKeyPrefixLen   = 8
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey) error
+	GetAPIKeys(ctx context.Context, userLogin string) ([]models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, keyID, userLogin string) (int64, error)
+	TouchAPIKey(ctx context.Context, keyID string, usedAt time.Time) error
+	DeleteUserAPIKeys(ctx context.Context, userLogin string) error
+}
+
+type APIKeySrv struct {
+	APIKey APIKeyRepository
+}
+
+func NewAPIKeySrv(apiKeyRepo APIKeyRepository) *APIKeySrv {
+	return &APIKeySrv{APIKey: apiKeyRepo}
+}
+
+func (s *APIKeySrv) CreateAPIKey(ctx context.Context, userLogin string,
+	keyInfo models.CreateAPIKey) (*models.CreatedAPIKey, error) {
+	for _, scope := range keyInfo.Scopes {
+		if !slices.Contains(models.APIKeyScopes, scope) {
+			return nil, fmt.Errorf("unknown scope: %v", scope)
+		}
+	}
+	keys, err := s.APIKey.GetAPIKeys(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	if len(keys) >= maxAPIKeysPerUser {
+		return nil, fmt.Errorf("you can have at most %d api keys", maxAPIKeysPerUser)
+	}
+	secret, err := randomURLString()
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("unfortunately we were unable to process your request, please try again later")
+	}
+	scopes := slices.Clone(keyInfo.Scopes)
+	slices.Sort(scopes)
+	rawKey := models.APIKeyPrefix + secret
+	now := time.Now().UTC()
+	key := models.APIKey{
+		UserLogin: userLogin,
+		Name:      keyInfo.Name,
+		Prefix:    rawKey[:len(models.APIKeyPrefix)+apiKeyPrefixLen],
+		KeyHash:   hashAPIKey(rawKey),
+		Scopes:    slices.Compact(scopes),
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Duration(keyInfo.ExpiresInDays) * HoursInDay * time.Hour),
+	}
+	err = s.APIKey.CreateAPIKey(ctx, key)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return &models.CreatedAPIKey{Key: rawKey, APIKey: key}, nil
+}
+
+func (s *APIKeySrv) GetAPIKeys(ctx context.Context, userLogin string) ([]models.APIKey, error) {
+	keys, err := s.APIKey.GetAPIKeys(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return keys, nil
+}
+
+func (s *APIKeySrv) DeleteAPIKey(ctx context.Context, keyID, userLogin string) error {
+	deleted, err := s.APIKey.DeleteAPIKey(ctx, keyID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if deleted == 0 {
+		return errors.New("api key wasn't found")
+	}
+	return nil
+}
+
+func (s *APIKeySrv) ValidateAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, models.APIKeyPrefix) {
+		return nil, errors.New("invalid api key format")
+	}
+	key, err := s.APIKey.GetAPIKeyByHash(ctx, hashAPIKey(rawKey))
+	if err != nil {
+		return nil, fmt.Errorf("ValidateAPIKey error: %v", err)
+	}
+	now := time.Now().UTC()
+	if now.After(key.ExpiresAt) {
+		return nil, errors.New("api key expired")
+	}
+	err = s.APIKey.TouchAPIKey(ctx, key.ID.Hex(), now)
+	if err != nil {
+		logs.Error(err)
+	}
+	return key, nil
+}
+
+// keys and one-time tokens are long random strings,
+// so a plain sha256 is enough and lets us look them up by hash
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
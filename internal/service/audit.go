@@ -0,0 +1,329 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 100
+	// maxAuditExport is how many of the newest entries an export has at most
+	maxAuditExport = 10000
+)
+
+type AuditRepository interface {
+	AddEntry(ctx context.Context, entry models.AuditEntry) error
+	GetEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int64, error)
+}
+
+// AuditSrv writes the audit log of groups from domain events, only leaders can read it
+type AuditSrv struct {
+	Audit  AuditRepository
+	Group  GroupRepository
+	Events EventPublisher
+}
+
+// NewAuditSrv wraps the events publisher, services publish their events through the audit service
+// so the entry of an event is saved with it
+func NewAuditSrv(auditRepo AuditRepository, groupRepo GroupRepository, events EventPublisher) *AuditSrv {
+	return &AuditSrv{Audit: auditRepo, Group: groupRepo, Events: events}
+}
+
+// Publish saves the event and its audit entry with the ctx of the change, so the entry is committed
+// or rolled back together with the change and the event, the same as the revisions of tasks
+func (s *AuditSrv) Publish(ctx context.Context, event models.Event) error {
+	if err := s.Events.Publish(ctx, event); err != nil {
+		return err
+	}
+	entry, ok := auditEntry(event)
+	if !ok {
+		return nil
+	}
+	entry.ID = primitive.NewObjectID()
+	entry.CreatedAt = time.Now().UTC()
+	entry.Action = event.EventName()
+	return s.Audit.AddEntry(ctx, entry)
+}
+
+// auditEntry describes the events that change members, tasks or polls of a group, other events have no entry
+func auditEntry(event models.Event) (models.AuditEntry, bool) {
+	switch event := event.(type) {
+	case models.MemberInvited:
+		return models.AuditEntry{GroupID: event.Group.ID.Hex(), Actor: event.Actor,
+			TargetType: models.AuditTargetMember, TargetID: event.Login, Target: event.Login,
+			Summary: fmt.Sprintf("%s invited %s", event.Actor, event.Login),
+		}, true
+	case models.MemberJoined:
+		summary := fmt.Sprintf("%s joined the group", event.Login)
+		if event.InvitedBy != "" {
+			summary = fmt.Sprintf("%s joined the group with the invite of %s", event.Login, event.InvitedBy)
+		}
+		return models.AuditEntry{GroupID: event.Group.ID.Hex(), Actor: event.Login,
+			TargetType: models.AuditTargetMember, TargetID: event.Login, Target: event.Login, Summary: summary,
+		}, true
+	case models.MemberLeft:
+		summary := fmt.Sprintf("%s left the group", event.Login)
+		if event.NewLeader != "" {
+			summary += fmt.Sprintf(", %s is the new leader", event.NewLeader)
+		}
+		return models.AuditEntry{GroupID: event.Group.ID.Hex(), Actor: event.Login,
+			TargetType: models.AuditTargetMember, TargetID: event.Login, Target: event.Login, Summary: summary,
+		}, true
+	case models.MemberBanned:
+		return models.AuditEntry{GroupID: event.Group.ID.Hex(), Actor: event.Actor,
+			TargetType: models.AuditTargetMember, TargetID: event.Login, Target: event.Login,
+			Summary: fmt.Sprintf("%s banned %s", event.Actor, event.Login),
+		}, true
+	case models.MemberUnbanned:
+		return models.AuditEntry{GroupID: event.Group.ID.Hex(), Actor: event.Actor,
+			TargetType: models.AuditTargetMember, TargetID: event.Login, Target: event.Login,
+			Summary: fmt.Sprintf("%s unbanned %s", event.Actor, event.Login),
+		}, true
+	case models.LeaderChanged:
+		summary := fmt.Sprintf("%s gave the leader role to %s", event.Actor, event.Leader)
+		if event.ActorLeft {
+			summary = fmt.Sprintf("%s left the group, %s got the leader role", event.Actor, event.Leader)
+		}
+		return models.AuditEntry{GroupID: event.Group.ID.Hex(), Actor: event.Actor,
+			TargetType: models.AuditTargetMember, TargetID: event.Leader, Target: event.Leader, Summary: summary,
+		}, true
+	case models.TaskCreated:
+		return taskChangeEntry(event.TaskChange), true
+	case models.TaskUpdated:
+		return taskChangeEntry(event.TaskChange), true
+	case models.TaskDeleted:
+		return taskChangeEntry(event.TaskChange), true
+	case models.PollCreated:
+		return models.AuditEntry{GroupID: event.Group.ID.Hex(), Actor: event.Actor,
+			TargetType: models.AuditTargetPoll, TargetID: event.Poll.ID.Hex(), Target: event.Poll.Title,
+			Summary: fmt.Sprintf("%s started the poll %q", event.Actor, event.Poll.Title),
+		}, true
+	case models.PollClosed:
+		summary := fmt.Sprintf("the poll %q is over", event.Poll.Title)
+		if event.ClosedBy != "" {
+			summary = fmt.Sprintf("%s closed the poll %q", event.ClosedBy, event.Poll.Title)
+		}
+		if event.Result.Winner != "" {
+			summary += fmt.Sprintf(", %s won", event.Result.Winner)
+		} else {
+			summary += ", votes are tied"
+		}
+		return models.AuditEntry{GroupID: event.Poll.GroupID.Hex(), Actor: event.ClosedBy,
+			TargetType: models.AuditTargetPoll, TargetID: event.Poll.ID.Hex(), Target: event.Poll.Title, Summary: summary,
+		}, true
+	case models.PollDeleted:
+		return models.AuditEntry{GroupID: event.Poll.GroupID.Hex(), Actor: event.Actor,
+			TargetType: models.AuditTargetPoll, TargetID: event.Poll.ID.Hex(), Target: event.Poll.Title,
+			Summary: fmt.Sprintf("%s deleted the poll %q", event.Actor, event.Poll.Title),
+		}, true
+	}
+	return models.AuditEntry{}, false
+}
+
+func taskChangeEntry(change models.TaskChange) models.AuditEntry {
+	return models.AuditEntry{GroupID: change.Group.ID.Hex(), Actor: change.Actor,
+		TargetType: models.AuditTargetTask, TargetID: change.TaskID.Hex(), Target: change.Title,
+		Summary: change.Summary,
+		Changes: taskChanges(change.Before, change.After),
+	}
+}
+
+// taskChanges lists the saved fields of the task that differ, a field only in the new task has no before value
+// and a field only in the old one has no after value
+func taskChanges(before, after *models.Task) []models.AuditChange {
+	beforeFields, beforeValues := taskFields(before)
+	afterFields, afterValues := taskFields(after)
+	var changes []models.AuditChange
+	for _, field := range append(afterFields, beforeFields...) {
+		if slices.ContainsFunc(changes, func(change models.AuditChange) bool { return change.Field == field }) {
+			continue
+		}
+		oldValue, hadValue := beforeValues[field]
+		newValue, hasValue := afterValues[field]
+		if hadValue && hasValue && oldValue.Equal(newValue) {
+			continue
+		}
+		change := models.AuditChange{Field: field}
+		if hadValue {
+			change.Before = auditValue(oldValue)
+		}
+		if hasValue {
+			change.After = auditValue(newValue)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// taskFields returns the fields of the task as they are saved, in their order, without the ids
+func taskFields(task *models.Task) ([]string, map[string]bson.RawValue) {
+	if task == nil {
+		return nil, nil
+	}
+	data, err := bson.Marshal(task)
+	if err != nil {
+		logs.Error(err)
+		return nil, nil
+	}
+	elements, err := bson.Raw(data).Elements()
+	if err != nil {
+		logs.Error(err)
+		return nil, nil
+	}
+	fields := make([]string, 0, len(elements))
+	values := make(map[string]bson.RawValue, len(elements))
+	for _, element := range elements {
+		if element.Key() == "_id" || element.Key() == "group_id" {
+			continue
+		}
+		fields = append(fields, element.Key())
+		values[element.Key()] = element.Value()
+	}
+	return fields, values
+}
+
+// auditValue writes strings and times as they are and everything else as JSON
+func auditValue(value bson.RawValue) string {
+	switch value.Type {
+	case bsontype.String:
+		return value.StringValue()
+	case bsontype.DateTime:
+		return value.Time().UTC().Format(time.RFC3339)
+	}
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "value", Value: value}}, false, false)
+	if err != nil {
+		logs.Error(err)
+		return value.String()
+	}
+	var wrapped struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		logs.Error(err)
+		return value.String()
+	}
+	return string(wrapped.Value)
+}
+
+func (s *AuditSrv) checkAuditFilter(ctx context.Context, userLogin string, filter models.AuditFilter) error {
+	group, err := s.Group.GetGroup(ctx, filter.GroupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	if filter.Action != "" && !slices.Contains(models.AuditActions, filter.Action) {
+		return fmt.Errorf("action must be one of: %s", strings.Join(models.AuditActions, ", "))
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// GetAuditLog returns entries of the audit log of the group, newest first. Only the leader can read it
+func (s *AuditSrv) GetAuditLog(ctx context.Context, userLogin string, filter models.AuditFilter) (*models.AuditPage, error) {
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Page < 1 {
+		return nil, errors.New("page must be positive")
+	}
+	if filter.Limit < 1 || filter.Limit > maxAuditLimit {
+		return nil, errors.New("limit must be from 1 to 100")
+	}
+	if err := s.checkAuditFilter(ctx, userLogin, filter); err != nil {
+		return nil, err
+	}
+	entries, total, err := s.Audit.GetEntries(ctx, filter)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return &models.AuditPage{Entries: entries, Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
+
+// ExportAuditLog exports the entries matching the filter as CSV or JSON, the newest maxAuditExport of them
+func (s *AuditSrv) ExportAuditLog(ctx context.Context, userLogin, format string,
+	filter models.AuditFilter) (*models.AuditExport, error) {
+	if format == "" {
+		format = models.ExportCSV
+	}
+	if format != models.ExportCSV && format != models.ExportJSON {
+		return nil, errors.New("format must be csv or json")
+	}
+	if err := s.checkAuditFilter(ctx, userLogin, filter); err != nil {
+		return nil, err
+	}
+	filter.Page, filter.Limit = 1, maxAuditExport
+	entries, _, err := s.Audit.GetEntries(ctx, filter)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	export := &models.AuditExport{FileName: fmt.Sprintf("audit-%s.%s", filter.GroupID, format)}
+	if format == models.ExportJSON {
+		export.ContentType = "application/json"
+		export.Data, err = json.MarshalIndent(entries, "", "  ")
+	} else {
+		export.ContentType = "text/csv"
+		export.Data, err = auditCSV(entries)
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return export, nil
+}
+
+// csvCell keeps spreadsheets from running a cell written by a user as a formula
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func auditCSV(entries []models.AuditEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	err := writer.Write([]string{"time", "actor", "action", "target_type", "target_id", "target", "summary", "changes"})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		changes := make([]string, 0, len(entry.Changes))
+		for _, change := range entry.Changes {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", change.Field, change.Before, change.After))
+		}
+		err := writer.Write([]string{entry.CreatedAt.UTC().Format(time.RFC3339), csvCell(entry.Actor), entry.Action,
+			entry.TargetType, csvCell(entry.TargetID), csvCell(entry.Target), csvCell(entry.Summary),
+			csvCell(strings.Join(changes, "; "))})
+		if err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
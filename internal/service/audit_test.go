@@ -0,0 +1,55 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAuditCSVEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{name: "plain", target: "museum", want: "museum"},
+		{name: "formula", target: "=HYPERLINK(\"http://evil\")", want: "'=HYPERLINK(\"http://evil\")"},
+		{name: "plus", target: "+1+1", want: "'+1+1"},
+		{name: "minus", target: "-1+1", want: "'-1+1"},
+		{name: "at", target: "@SUM(A1)", want: "'@SUM(A1)"},
+		{name: "tab", target: "\t=1", want: "'\t=1"},
+		{name: "inside", target: "a=1", want: "a=1"},
+		{name: "empty", target: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := auditCSV([]models.AuditEntry{{CreatedAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+				Actor: "alice", Action: models.TaskCreated{}.EventName(), Target: tt.target}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := records[1][5]; got != tt.want {
+				t.Errorf("target cell = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditPollDeletedUsesPollGroup(t *testing.T) {
+	poll := models.Poll{ID: primitive.NewObjectID(), GroupID: primitive.NewObjectID(), Title: "where to eat"}
+	entry, ok := auditEntry(models.PollDeleted{Group: models.Group{ID: primitive.NewObjectID()}, Actor: "alice", Poll: poll})
+	if !ok {
+		t.Fatal("poll deleted is not audited")
+	}
+	if entry.GroupID != poll.GroupID.Hex() {
+		t.Errorf("group = %s, want the group of the poll %s", entry.GroupID, poll.GroupID.Hex())
+	}
+}
@@ -17,20 +17,36 @@ func SetLogger(l *zap.Logger) {
 
 type ChatRepository interface {
 	InsertMessage(ctx context.Context, msg models.Message) error
+	InsertMessageOnce(ctx context.Context, msg models.Message) (bool, error)
 	FindMessagesByChatID(ctx context.Context, groupID string) ([]models.Message, error)
 }
 
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type ChatSrv struct {
-	repo ChatRepository
+	repo   ChatRepository
+	events EventPublisher
+	tx     Transactor
 }
 
-func NewChatService(repo ChatRepository) *ChatSrv {
-	return &ChatSrv{repo: repo}
+func NewChatService(repo ChatRepository, events EventPublisher, tx Transactor) *ChatSrv {
+	return &ChatSrv{repo: repo, events: events, tx: tx}
 }
 
 func (s *ChatSrv) SaveMessage(ctx context.Context, msg models.Message) error {
 	msg.Time = time.Now().UTC()
-	err := s.repo.InsertMessage(ctx, msg)
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.InsertMessage(ctx, msg); err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, models.MessagePosted{Message: msg})
+	})
 	if err != nil {
 		logs.Error(err)
 		return errors.New("failed save message")
@@ -38,6 +54,20 @@ func (s *ChatSrv) SaveMessage(ctx context.Context, msg models.Message) error {
 	return nil
 }
 
+// PostSystemMessage saves a message of the application to the group chat,
+// it reports false if a message with the same key was already posted
+func (s *ChatSrv) PostSystemMessage(ctx context.Context, key string, msg models.Message) (bool, error) {
+	msg.User = models.SystemUser
+	msg.Key = key
+	msg.Time = time.Now().UTC()
+	posted, err := s.repo.InsertMessageOnce(ctx, msg)
+	if err != nil {
+		logs.Error(err)
+		return false, errors.New("failed save message")
+	}
+	return posted, nil
+}
+
 func (s *ChatSrv) GetChatHistory(ctx context.Context, groupID string) ([]models.Message, error) {
 	messages, err := s.repo.FindMessagesByChatID(ctx, groupID)
 	if err != nil {
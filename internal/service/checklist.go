@@ -0,0 +1,264 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxChecklistItems     = 50
+	maxChecklistItemTitle = 200
+)
+
+func (s *TaskSrv) SetTaskStatus(ctx context.Context, groupID, taskID, userLogin, status string) error {
+	if !slices.Contains(models.TaskStatuses, status) {
+		return errors.New("unknown task status")
+	}
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	tasks, err := s.Task.GetTaskList(ctx, userLogin, groupID)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	index := slices.IndexFunc(tasks, func(task models.Task) bool { return task.ID.Hex() == taskID })
+	if index < 0 {
+		return errors.New("task was not found")
+	}
+	before := tasks[index]
+	// a cancelled task frees its time, so bringing it back needs the time to be free again
+	if tasks[index].CurrentStatus() == models.TaskCancelled && status != models.TaskCancelled {
+		tasks[index].Status = status
+		if err := findOverlap(tasks, []primitive.ObjectID{tasks[index].ID}, group.Trip); err != nil {
+			return err
+		}
+	}
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.SetTaskStatus(ctx, taskID, status)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.publishTaskChange(ctx, group, userLogin, &before, before.ID, taskUpdated,
+			fmt.Sprintf("%s marked the task %s as %s", userLogin, tasks[index].Title, status))
+	})
+	if err != nil {
+		return err
+	}
+	s.Reminders.RescheduleReminders(ctx, groupID, taskID)
+	return nil
+}
+
+func (s *TaskSrv) AddChecklistItem(ctx context.Context, groupID, taskID, userLogin, title, assignee string) error {
+	group, task, err := s.getGroupTask(ctx, groupID, taskID, userLogin)
+	if err != nil {
+		return err
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	if title == "" || len(title) > maxChecklistItemTitle {
+		return fmt.Errorf("item title must be 1-%d characters long", maxChecklistItemTitle)
+	}
+	if assignee != "" && !slices.Contains(group.Members, assignee) {
+		return errors.New("assignee is not a member of the group")
+	}
+	if len(task.Checklist) >= maxChecklistItems {
+		return fmt.Errorf("checklist can have at most %d items", maxChecklistItems)
+	}
+	item := models.ChecklistItem{
+		ID:       primitive.NewObjectID(),
+		Title:    title,
+		Assignee: assignee,
+	}
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.AddChecklistItem(ctx, taskID, item)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.recordTaskChange(ctx, group, userLogin, task,
+			fmt.Sprintf("%s added the item %s to the checklist of %s", userLogin, title, task.Title))
+	})
+}
+
+// AssignChecklistItem gives the item to a member, empty assignee leaves it to anybody
+func (s *TaskSrv) AssignChecklistItem(ctx context.Context, groupID, taskID, itemID, userLogin, assignee string) error {
+	group, task, err := s.getGroupTask(ctx, groupID, taskID, userLogin)
+	if err != nil {
+		return err
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	if assignee != "" && !slices.Contains(group.Members, assignee) {
+		return errors.New("assignee is not a member of the group")
+	}
+	item, err := findChecklistItem(task, itemID)
+	if err != nil {
+		return err
+	}
+	item.Assignee = assignee
+	text := fmt.Sprintf("%s assigned the item %s of %s to %s", userLogin, item.Title, task.Title, assignee)
+	if assignee == "" {
+		text = fmt.Sprintf("%s left the item %s of %s to anybody", userLogin, item.Title, task.Title)
+	}
+	return s.saveChecklistItem(ctx, group, task, userLogin, *item, text)
+}
+
+// CheckChecklistItem can be done by the assignee or the leader, items without assignee by anybody
+func (s *TaskSrv) CheckChecklistItem(ctx context.Context, groupID, taskID, itemID, userLogin string, done bool) error {
+	group, task, err := s.getGroupTask(ctx, groupID, taskID, userLogin)
+	if err != nil {
+		return err
+	}
+	item, err := findChecklistItem(task, itemID)
+	if err != nil {
+		return err
+	}
+	if item.Assignee != "" && item.Assignee != userLogin && group.LeaderLogin != userLogin {
+		return errors.New("this item is assigned to another member")
+	}
+	item.Done = done
+	item.DoneBy, item.DoneAt = "", nil
+	text := fmt.Sprintf("%s unchecked the item %s of %s", userLogin, item.Title, task.Title)
+	if done {
+		now := time.Now().UTC()
+		item.DoneBy, item.DoneAt = userLogin, &now
+		text = fmt.Sprintf("%s checked the item %s of %s", userLogin, item.Title, task.Title)
+	}
+	return s.saveChecklistItem(ctx, group, task, userLogin, *item, text)
+}
+
+func (s *TaskSrv) DeleteChecklistItem(ctx context.Context, groupID, taskID, itemID, userLogin string) error {
+	group, task, err := s.getGroupTask(ctx, groupID, taskID, userLogin)
+	if err != nil {
+		return err
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	item, err := findChecklistItem(task, itemID)
+	if err != nil {
+		return err
+	}
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.DeleteChecklistItem(ctx, taskID, itemID)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.recordTaskChange(ctx, group, userLogin, task,
+			fmt.Sprintf("%s removed the item %s from the checklist of %s", userLogin, item.Title, task.Title))
+	})
+}
+
+// GetProgress summarizes task statuses and checklist items still to do, cancelled tasks are left out
+func (s *TaskSrv) GetProgress(ctx context.Context, groupID, userLogin string) (*models.GroupProgress, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	tasks, err := s.Task.GetTaskList(ctx, userLogin, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	progress := &models.GroupProgress{
+		TasksByStatus: map[string]int{},
+		ByAssignee:    map[string]int{},
+		Outstanding:   []models.OutstandingItem{},
+	}
+	for _, status := range models.TaskStatuses {
+		progress.TasksByStatus[status] = 0
+	}
+	for _, task := range tasks {
+		progress.TasksByStatus[task.CurrentStatus()]++
+		if task.CurrentStatus() == models.TaskCancelled {
+			continue
+		}
+		for _, item := range task.Checklist {
+			progress.ChecklistTotal++
+			if item.Done {
+				progress.ChecklistDone++
+				continue
+			}
+			progress.ByAssignee[item.Assignee]++
+			progress.Outstanding = append(progress.Outstanding, models.OutstandingItem{
+				TaskID:    task.ID,
+				TaskTitle: task.Title,
+				TaskStart: task.StartTime,
+				ItemID:    item.ID,
+				Title:     item.Title,
+				Assignee:  item.Assignee,
+			})
+		}
+	}
+	sort.SliceStable(progress.Outstanding, func(i, j int) bool {
+		return progress.Outstanding[i].TaskStart.Before(progress.Outstanding[j].TaskStart)
+	})
+	if from, _ := group.Trip.Window(); !from.IsZero() {
+		days := int(time.Until(from).Hours() / HoursInDay)
+		progress.DaysToDeparture = &days
+	}
+	return progress, nil
+}
+
+func (s *TaskSrv) getGroupTask(ctx context.Context, groupID, taskID, userLogin string) (*models.Group, *models.Task, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, nil, errors.New("group is not found, or you are not a member of it")
+	}
+	task, err := s.Task.GetTaskById(ctx, taskID, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, nil, errors.New("task was not found")
+	}
+	return group, task, nil
+}
+
+func (s *TaskSrv) saveChecklistItem(ctx context.Context, group *models.Group, task *models.Task, userLogin string,
+	item models.ChecklistItem, text string) error {
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.UpdateChecklistItem(ctx, task.ID.Hex(), item)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.recordTaskChange(ctx, group, userLogin, task, text)
+	})
+}
+
+// findChecklistItem returns a copy of the item, so the task stays as it is saved
+func findChecklistItem(task *models.Task, itemID string) (*models.ChecklistItem, error) {
+	for _, item := range task.Checklist {
+		if item.ID.Hex() == itemID {
+			return &item, nil
+		}
+	}
+	return nil, errors.New("checklist item was not found")
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	minSearchPrefix     = 2
+	defaultSearchLimit  = 20
+	maxSearchLimit      = 50
+	maxInviteSuggestion = 30
+)
+
+type ContactRepository interface {
+	GetContact(ctx context.Context, firstLogin, secondLogin string) (*models.Contact, error)
+	SaveContact(ctx context.Context, contact models.Contact) error
+	DeleteContact(ctx context.Context, firstLogin, secondLogin string) error
+	GetUserContacts(ctx context.Context, userLogin string) ([]models.Contact, error)
+	DeleteUserContacts(ctx context.Context, userLogin string) error
+}
+
+type ContactSrv struct {
+	Contact   ContactRepository
+	User      UserRepository
+	Group     GroupRepository
+	BlackList BlackListRepository
+}
+
+func NewContactSrv(contactRepo ContactRepository, userRepo UserRepository,
+	groupRepo GroupRepository, blackList BlackListRepository) *ContactSrv {
+	return &ContactSrv{Contact: contactRepo, User: userRepo, Group: groupRepo, BlackList: blackList}
+}
+
+func (s *ContactSrv) SendRequest(ctx context.Context, userLogin, otherLogin string) error {
+	if userLogin == otherLogin {
+		return errors.New("you cant add yourself")
+	}
+	if _, err := s.User.GetUserByLogin(ctx, otherLogin); err != nil {
+		return errors.New("user not found")
+	}
+	contact, err := s.Contact.GetContact(ctx, userLogin, otherLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	now := time.Now().UTC()
+	if contact == nil {
+		contact = &models.Contact{
+			Requester: userLogin,
+			Addressee: otherLogin,
+			BlockedBy: []string{},
+			CreatedAt: now,
+		}
+	} else {
+		switch contact.Status {
+		case models.ContactBlocked:
+			return errors.New("you cant send friend request to this user")
+		case models.ContactAccepted:
+			return errors.New("you are already friends")
+		case models.ContactPending:
+			if contact.Requester == userLogin {
+				return errors.New("friend request is already sent")
+			}
+			// both want to be friends, so the counter request is an accept
+			contact.Status = models.ContactAccepted
+			contact.UpdatedAt = now
+			return s.saveContact(ctx, *contact)
+		case models.ContactDeclined:
+			contact.Requester = userLogin
+			contact.Addressee = otherLogin
+		}
+	}
+	contact.Status = models.ContactPending
+	contact.UpdatedAt = now
+	return s.saveContact(ctx, *contact)
+}
+
+func (s *ContactSrv) AcceptRequest(ctx context.Context, userLogin, otherLogin string) error {
+	return s.answerRequest(ctx, userLogin, otherLogin, models.ContactAccepted)
+}
+
+func (s *ContactSrv) DeclineRequest(ctx context.Context, userLogin, otherLogin string) error {
+	return s.answerRequest(ctx, userLogin, otherLogin, models.ContactDeclined)
+}
+
+func (s *ContactSrv) answerRequest(ctx context.Context, userLogin, otherLogin, status string) error {
+	contact, err := s.Contact.GetContact(ctx, userLogin, otherLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if contact == nil || contact.Status != models.ContactPending || contact.Addressee != userLogin {
+		return errors.New("friend request wasn't found")
+	}
+	contact.Status = status
+	contact.UpdatedAt = time.Now().UTC()
+	return s.saveContact(ctx, *contact)
+}
+
+func (s *ContactSrv) RemoveContact(ctx context.Context, userLogin, otherLogin string) error {
+	contact, err := s.Contact.GetContact(ctx, userLogin, otherLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if contact == nil || contact.Status == models.ContactBlocked || contact.Status == models.ContactDeclined {
+		return errors.New("contact wasn't found")
+	}
+	if contact.Status == models.ContactPending && contact.Requester != userLogin {
+		return errors.New("use decline for incoming friend requests")
+	}
+	err = s.Contact.DeleteContact(ctx, userLogin, otherLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+func (s *ContactSrv) BlockUser(ctx context.Context, userLogin, otherLogin string) error {
+	if userLogin == otherLogin {
+		return errors.New("you cant block yourself")
+	}
+	if _, err := s.User.GetUserByLogin(ctx, otherLogin); err != nil {
+		return errors.New("user not found")
+	}
+	contact, err := s.Contact.GetContact(ctx, userLogin, otherLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	now := time.Now().UTC()
+	if contact == nil {
+		contact = &models.Contact{
+			Requester: userLogin,
+			Addressee: otherLogin,
+			BlockedBy: []string{},
+			CreatedAt: now,
+		}
+	}
+	if slices.Contains(contact.BlockedBy, userLogin) {
+		return errors.New("user is already blocked")
+	}
+	contact.BlockedBy = append(contact.BlockedBy, userLogin)
+	contact.Status = models.ContactBlocked
+	contact.UpdatedAt = now
+	return s.saveContact(ctx, *contact)
+}
+
+func (s *ContactSrv) UnblockUser(ctx context.Context, userLogin, otherLogin string) error {
+	contact, err := s.Contact.GetContact(ctx, userLogin, otherLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if contact == nil || !slices.Contains(contact.BlockedBy, userLogin) {
+		return errors.New("user is not blocked")
+	}
+	contact.BlockedBy = slices.DeleteFunc(contact.BlockedBy, func(login string) bool { return login == userLogin })
+	if len(contact.BlockedBy) > 0 {
+		contact.UpdatedAt = time.Now().UTC()
+		return s.saveContact(ctx, *contact)
+	}
+	// unblocking does not restore the friendship, users start from scratch
+	err = s.Contact.DeleteContact(ctx, userLogin, otherLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+func (s *ContactSrv) saveContact(ctx context.Context, contact models.Contact) error {
+	err := s.Contact.SaveContact(ctx, contact)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+func (s *ContactSrv) GetContactList(ctx context.Context, userLogin string) (*models.ContactList, error) {
+	contacts, err := s.Contact.GetUserContacts(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	list := &models.ContactList{
+		Friends:  []string{},
+		Incoming: []string{},
+		Outgoing: []string{},
+		Blocked:  []string{},
+	}
+	for _, contact := range contacts {
+		other := contact.Other(userLogin)
+		switch contact.Status {
+		case models.ContactAccepted:
+			list.Friends = append(list.Friends, other)
+		case models.ContactPending:
+			if contact.Addressee == userLogin {
+				list.Incoming = append(list.Incoming, other)
+			} else {
+				list.Outgoing = append(list.Outgoing, other)
+			}
+		case models.ContactBlocked:
+			if slices.Contains(contact.BlockedBy, userLogin) {
+				list.Blocked = append(list.Blocked, other)
+			}
+		}
+	}
+	return list, nil
+}
+
+// relations returns accepted contacts and users that are blocked in either direction
+func (s *ContactSrv) relations(ctx context.Context, userLogin string) (friends, blocked []string, err error) {
+	contacts, err := s.Contact.GetUserContacts(ctx, userLogin)
+	if err != nil {
+		return nil, nil, err
+	}
+	friends = []string{}
+	blocked = []string{}
+	for _, contact := range contacts {
+		switch contact.Status {
+		case models.ContactAccepted:
+			friends = append(friends, contact.Other(userLogin))
+		case models.ContactBlocked:
+			blocked = append(blocked, contact.Other(userLogin))
+		}
+	}
+	return friends, blocked, nil
+}
+
+func (s *ContactSrv) SearchUsers(ctx context.Context, userLogin, prefix string, limit int) ([]models.UserSearchResult, error) {
+	prefix = strings.TrimSpace(prefix)
+	if len([]rune(prefix)) < minSearchPrefix {
+		return nil, errors.New("search query is too short")
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+	friends, blocked, err := s.relations(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	users, err := s.User.SearchUsers(ctx, models.UserSearch{
+		Prefix:        prefix,
+		ExcludeLogins: append(blocked, userLogin),
+		ContactLogins: friends,
+		Limit:         int64(limit),
+	})
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	results := make([]models.UserSearchResult, 0, len(users))
+	for _, user := range users {
+		results = append(results, models.UserSearchResult{
+			Login:       user.Login,
+			DisplayName: user.Profile.DisplayName,
+			AvatarURL:   user.Profile.AvatarURL,
+		})
+	}
+	return results, nil
+}
+
+func (s *ContactSrv) UpdatePrivacy(ctx context.Context, userLogin string, privacy models.Privacy) error {
+	switch privacy.SearchVisibility {
+	case models.VisibilityEveryone, models.VisibilityContacts, models.VisibilityNobody:
+	default:
+		return errors.New("unknown search visibility")
+	}
+	err := s.User.UpdatePrivacy(ctx, userLogin, privacy)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+// GetInviteSuggestions offers contacts and people the user travelled with in other groups
+func (s *ContactSrv) GetInviteSuggestions(ctx context.Context, groupID, userLogin string) ([]models.InviteSuggestion, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	blacklist, err := s.BlackList.GetBlacklist(ctx, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to get blacklist of group")
+	}
+	friends, blocked, err := s.relations(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	excluded := make(map[string]bool)
+	for _, login := range group.Members {
+		excluded[login] = true
+	}
+	for _, login := range blacklist.Blacklist {
+		excluded[login] = true
+	}
+	for _, login := range blocked {
+		excluded[login] = true
+	}
+
+	suggestions := make(map[string]*models.InviteSuggestion)
+	for _, login := range friends {
+		if !excluded[login] {
+			suggestions[login] = &models.InviteSuggestion{Login: login, IsContact: true}
+		}
+	}
+	groups, err := s.Group.GetGroupList(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to get groups")
+	}
+	for _, other := range groups {
+		if other.ID == group.ID {
+			continue
+		}
+		for _, login := range other.Members {
+			if excluded[login] {
+				continue
+			}
+			if _, ok := suggestions[login]; !ok {
+				suggestions[login] = &models.InviteSuggestion{Login: login}
+			}
+			suggestions[login].SharedGroups++
+		}
+	}
+
+	result := make([]models.InviteSuggestion, 0, len(suggestions))
+	logins := make([]string, 0, len(suggestions))
+	for login, suggestion := range suggestions {
+		result = append(result, *suggestion)
+		logins = append(logins, login)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].IsContact != result[j].IsContact {
+			return result[i].IsContact
+		}
+		if result[i].SharedGroups != result[j].SharedGroups {
+			return result[i].SharedGroups > result[j].SharedGroups
+		}
+		return result[i].Login < result[j].Login
+	})
+	if len(result) > maxInviteSuggestion {
+		result = result[:maxInviteSuggestion]
+	}
+	if len(logins) > 0 {
+		users, err := s.User.GetUsersByLogins(ctx, logins)
+		if err != nil {
+			logs.Error(err)
+			return result, nil
+		}
+		names := make(map[string]string, len(users))
+		for _, user := range users {
+			names[user.Login] = user.Profile.DisplayName
+		}
+		for i := range result {
+			result[i].DisplayName = names[result[i].Login]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxDependencyGap = 7 * HoursInDay * MinutesInHour
+
+// AddDependency makes the task start only after the other one ends, plus the gap in minutes
+func (s *TaskSrv) AddDependency(ctx context.Context, groupID, taskID, dependsOnID, userLogin string, gap int) error {
+	if gap < 0 || gap > maxDependencyGap {
+		return errors.New("gap must be between 0 and 7 days")
+	}
+	if taskID == dependsOnID {
+		return errors.New("task cant depend on itself")
+	}
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	tasks, err := s.Task.GetTaskList(ctx, userLogin, groupID)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	byID := make(map[string]models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID.Hex()] = task
+	}
+	task, ok := byID[taskID]
+	if !ok {
+		return errors.New("task was not found")
+	}
+	dependsOn, ok := byID[dependsOnID]
+	if !ok {
+		return errors.New("task it depends on was not found")
+	}
+	if task.Recurrence != nil || dependsOn.Recurrence != nil {
+		return errors.New("recurring tasks cant have dependencies")
+	}
+	if dependsOnPath(tasks, dependsOn.ID, task.ID) {
+		return fmt.Errorf("%s already depends on %s, this would make a cycle", dependsOn.Title, task.Title)
+	}
+	if task.StartTime.Before(dependsOn.EndTime.Add(time.Duration(gap) * time.Minute)) {
+		return fmt.Errorf("%s starts too early, move it first", task.Title)
+	}
+	dependencies := []models.Dependency{}
+	for _, dependency := range task.DependsOn {
+		if dependency.TaskID != dependsOn.ID {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	dependencies = append(dependencies, models.Dependency{TaskID: dependsOn.ID, Gap: gap})
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.SetDependencies(ctx, taskID, dependencies)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.recordTaskChange(ctx, group, userLogin, &task,
+			fmt.Sprintf("%s made the task %s depend on %s", userLogin, task.Title, dependsOn.Title))
+	})
+}
+
+func (s *TaskSrv) RemoveDependency(ctx context.Context, groupID, taskID, dependsOnID, userLogin string) error {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	task, err := s.Task.GetTaskById(ctx, taskID, groupID)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("task was not found")
+	}
+	dependencies := []models.Dependency{}
+	for _, dependency := range task.DependsOn {
+		if dependency.TaskID.Hex() != dependsOnID {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	if len(dependencies) == len(task.DependsOn) {
+		return errors.New("task does not depend on it")
+	}
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.SetDependencies(ctx, taskID, dependencies)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.recordTaskChange(ctx, group, userLogin, task,
+			fmt.Sprintf("%s removed a dependency of the task %s", userLogin, task.Title))
+	})
+}
+
+// dependsOnPath reports whether from depends on to directly or through other tasks
+func dependsOnPath(tasks []models.Task, from, to primitive.ObjectID) bool {
+	dependencies := make(map[primitive.ObjectID][]models.Dependency, len(tasks))
+	for _, task := range tasks {
+		dependencies[task.ID] = task.DependsOn
+	}
+	visited := map[primitive.ObjectID]bool{}
+	stack := []primitive.ObjectID{from}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == to {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		for _, dependency := range dependencies[current] {
+			stack = append(stack, dependency.TaskID)
+		}
+	}
+	return false
+}
+
+// shiftDependents checks the dependencies of the moved task and finds tasks that have to move later
+// with it. Without cascade any broken dependency is an error. Dependent tasks only move forward,
+// so moving a task earlier never touches the rest of the plan.
+func shiftDependents(tasks []models.Task, moved models.Task, cascade bool) ([]models.Task, error) {
+	byID := make(map[primitive.ObjectID]models.Task, len(tasks))
+	dependents := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, task := range tasks {
+		byID[task.ID] = task
+		for _, dependency := range task.DependsOn {
+			dependents[dependency.TaskID] = append(dependents[dependency.TaskID], task.ID)
+		}
+	}
+	byID[moved.ID] = moved
+	earliestStart := func(task models.Task) (time.Time, string) {
+		var earliest time.Time
+		var blocker string
+		for _, dependency := range task.DependsOn {
+			other, ok := byID[dependency.TaskID]
+			if !ok {
+				continue
+			}
+			if start := other.EndTime.Add(time.Duration(dependency.Gap) * time.Minute); start.After(earliest) {
+				earliest, blocker = start, other.Title
+			}
+		}
+		return earliest, blocker
+	}
+	if earliest, blocker := earliestStart(moved); moved.StartTime.Before(earliest) {
+		return nil, fmt.Errorf("task must start after %s, earliest start is %s",
+			blocker, earliest.Format(time.RFC3339))
+	}
+
+	shifted := map[primitive.ObjectID]bool{}
+	queue := []primitive.ObjectID{moved.ID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependentID := range dependents[current] {
+			dependent := byID[dependentID]
+			earliest, _ := earliestStart(dependent)
+			if !dependent.StartTime.Before(earliest) {
+				continue
+			}
+			if !cascade {
+				return nil, fmt.Errorf("moving the task breaks dependency of %s, send cascade=true to shift dependent tasks",
+					dependent.Title)
+			}
+			delay := earliest.Sub(dependent.StartTime)
+			dependent.StartTime = dependent.StartTime.Add(delay)
+			dependent.EndTime = dependent.EndTime.Add(delay)
+			byID[dependentID] = dependent
+			shifted[dependentID] = true
+			queue = append(queue, dependentID)
+		}
+	}
+	result := make([]models.Task, 0, len(shifted))
+	for id := range shifted {
+		result = append(result, byID[id])
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartTime.Before(result[j].StartTime) })
+	return result, nil
+}
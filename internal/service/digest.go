@@ -0,0 +1,199 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"JourneyPlanner/pkg/mail"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const digestLookahead = HoursInDay * time.Hour
+
+// MentionFinder finds chat messages that mention the user as @login
+type MentionFinder interface {
+	FindMentions(ctx context.Context, groupIDs []string, userLogin string, since time.Time) ([]models.Message, error)
+}
+
+// DigestSrv sends the daily email of users who chose a digest instead of emails at once
+type DigestSrv struct {
+	Settings     NotificationSettingsRepository
+	Notification NotificationRepository
+	User         UserRepository
+	Group        GroupRepository
+	Task         TaskRepository
+	Poll         PollRepository
+	Chat         MentionFinder
+	Mailer       mail.Mailer
+}
+
+func NewDigestSrv(settingsRepo NotificationSettingsRepository, notificationRepo NotificationRepository,
+	userRepo UserRepository, groupRepo GroupRepository, taskRepo TaskRepository, pollRepo PollRepository,
+	chat MentionFinder, mailer mail.Mailer) *DigestSrv {
+	return &DigestSrv{Settings: settingsRepo, Notification: notificationRepo, User: userRepo, Group: groupRepo,
+		Task: taskRepo, Poll: pollRepo, Chat: chat, Mailer: mailer}
+}
+
+// SendDigests is a periodic job run every hour, a digest is due once the user's local hour reaches the chosen one
+// and no digest was sent on that local day, every user is claimed before the digest is built so it is sent once
+func (s *DigestSrv) SendDigests(ctx context.Context) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	enabled, err := s.Settings.GetDigestUsers(ctx)
+	if err != nil || len(enabled) == 0 {
+		return err
+	}
+	logins := make([]string, 0, len(enabled))
+	for _, settings := range enabled {
+		logins = append(logins, settings.UserLogin)
+	}
+	users, err := s.User.GetUsersByLogins(ctx, logins)
+	if err != nil {
+		return err
+	}
+	byLogin := make(map[string]models.User, len(users))
+	for _, user := range users {
+		byLogin[user.Login] = user
+	}
+	var failed error
+	for _, settings := range enabled {
+		user, ok := byLogin[settings.UserLogin]
+		if !ok {
+			continue
+		}
+		local := now.In(user.Profile.Location())
+		day := local.Format(models.TripDateFormat)
+		if local.Hour() < settings.DigestSendHour() || settings.LastDigestDay == day {
+			continue
+		}
+		claimed, err := s.Settings.ClaimDigest(ctx, settings.UserLogin, settings.LastDigestAt, now, day)
+		if err != nil {
+			failed = err
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.sendDigest(ctx, settings, user, now); err != nil {
+			failed = err
+			err = s.Settings.ReleaseDigest(ctx, settings.UserLogin, settings.LastDigestAt, settings.LastDigestDay, now)
+			if err != nil {
+				logs.Error(err)
+			}
+		}
+	}
+	return failed
+}
+
+func (s *DigestSrv) sendDigest(ctx context.Context, settings models.NotificationSettings, user models.User, now time.Time) error {
+	if user.Email == "" {
+		return nil
+	}
+	since := now.Add(-digestLookahead)
+	if settings.LastDigestAt != nil {
+		since = *settings.LastDigestAt
+	}
+	body, pending, err := s.buildDigest(ctx, settings.UserLogin, since, now)
+	if err != nil {
+		return err
+	}
+	if body == "" {
+		return nil
+	}
+	err = s.Mailer.Send(ctx, user.Email, "Journey Planner: your daily digest", body)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	return s.Notification.ClearEmailPending(ctx, pending)
+}
+
+// buildDigest lists tasks of the next day, polls closing within a day, mentions since the last digest
+// and notifications waiting for the digest, it returns empty body when there is nothing to tell
+func (s *DigestSrv) buildDigest(ctx context.Context, userLogin string, since, now time.Time) (string, []primitive.ObjectID, error) {
+	groups, err := s.Group.GetGroupList(ctx, userLogin)
+	if err != nil {
+		return "", nil, err
+	}
+	until := now.Add(digestLookahead)
+	var tasks, polls, mentions, events []string
+	groupIDs := make([]string, 0, len(groups))
+	groupNames := make(map[string]string, len(groups))
+	for _, group := range groups {
+		groupID := group.ID.Hex()
+		groupIDs = append(groupIDs, groupID)
+		groupNames[groupID] = group.Name
+		loc := group.Trip.Location()
+
+		groupTasks, err := s.Task.GetTaskList(ctx, userLogin, groupID)
+		if err != nil {
+			return "", nil, err
+		}
+		upcoming := slices.DeleteFunc(expandTasks(groupTasks, group.Trip), func(task models.Task) bool {
+			return task.CurrentStatus() == models.TaskCancelled || task.StartTime.Before(now) || !task.StartTime.Before(until)
+		})
+		slices.SortFunc(upcoming, func(a, b models.Task) int { return a.StartTime.Compare(b.StartTime) })
+		for _, task := range upcoming {
+			tasks = append(tasks, fmt.Sprintf("%s, %s: %s", task.StartTime.In(loc).Format("2006-01-02 15:04"), group.Name, task.Title))
+		}
+
+		openPolls, _, err := s.Poll.GetPollList(ctx, groupID)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, poll := range openPolls {
+			if poll.IsEarlyClosed || poll.EndTime.Before(now) || !poll.EndTime.Before(until) {
+				continue
+			}
+			line := fmt.Sprintf("%s: %q closes at %s", group.Name, poll.Title, poll.EndTime.In(loc).Format("2006-01-02 15:04"))
+			if !slices.Contains(poll.Votes1, userLogin) && !slices.Contains(poll.Votes2, userLogin) {
+				line += ", you have not voted"
+			}
+			polls = append(polls, line)
+		}
+	}
+	if len(groupIDs) > 0 {
+		messages, err := s.Chat.FindMentions(ctx, groupIDs, userLogin, since)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, msg := range messages {
+			mentions = append(mentions, fmt.Sprintf("%s, %s: %s", groupNames[msg.GroupID], msg.User, msg.Content))
+		}
+	}
+	pending, err := s.Notification.GetEmailPending(ctx, userLogin)
+	if err != nil {
+		return "", nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(pending))
+	for _, notification := range pending {
+		events = append(events, notification.Text)
+		ids = append(ids, notification.ID)
+	}
+
+	var body strings.Builder
+	for _, section := range []struct {
+		title string
+		lines []string
+	}{
+		{"Upcoming tasks", tasks},
+		{"Polls closing soon", polls},
+		{"You were mentioned", mentions},
+		{"What happened", events},
+	} {
+		if len(section.lines) == 0 {
+			continue
+		}
+		body.WriteString(section.title + ":\n")
+		for _, line := range section.lines {
+			body.WriteString("- " + line + "\n")
+		}
+		body.WriteString("\n")
+	}
+	return body.String(), ids, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventPublisher saves events to the outbox, the error has to fail the change the event describes
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+// publishEvent saves the event with the change, its error has to be returned to fail the change
+func publishEvent(ctx context.Context, events EventPublisher, event models.Event) error {
+	if err := events.Publish(ctx, event); err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+type dispatchedEventKey struct{}
+
+// dispatchedEvent is what subscribers know about the outbox record of the event being dispatched
+type dispatchedEvent struct {
+	ID primitive.ObjectID
+}
+
+func withEvent(ctx context.Context, outboxEvent models.OutboxEvent) context.Context {
+	return context.WithValue(ctx, dispatchedEventKey{}, dispatchedEvent{ID: outboxEvent.ID})
+}
+
+// eventKey identifies the event being dispatched, subscribers use it to skip an event the relay sends again
+func eventKey(ctx context.Context) string {
+	event, ok := ctx.Value(dispatchedEventKey{}).(dispatchedEvent)
+	if !ok {
+		return ""
+	}
+	return "event:" + event.ID.Hex()
+}
+
+// EventBus delivers the events of the outbox in process. Subscribers run one by one in the goroutine of the relay,
+// and a panic in one of them does not reach the relay or the others
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]func(ctx context.Context, event models.Event)
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: make(map[string][]func(ctx context.Context, event models.Event))}
+}
+
+// Subscribe calls the handler for every published event of type E
+func Subscribe[E models.Event](bus *EventBus, handler func(ctx context.Context, event E)) {
+	var event E
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.handlers[event.EventName()] = append(bus.handlers[event.EventName()], func(ctx context.Context, event models.Event) {
+		handler(ctx, event.(E))
+	})
+}
+
+func (b *EventBus) Publish(ctx context.Context, event models.Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.EventName()]
+	b.mu.RUnlock()
+	for _, handler := range handlers {
+		b.call(ctx, event, handler)
+	}
+}
+
+func (b *EventBus) call(ctx context.Context, event models.Event, handler func(ctx context.Context, event models.Event)) {
+	defer func() {
+		if r := recover(); r != nil {
+			logs.Errorf("%s subscriber panicked: %v", event.EventName(), r)
+		}
+	}()
+	handler(ctx, event)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type gpxFile struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Namespace string        `xml:"xmlns,attr"`
+	Name      string        `xml:"metadata>name"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Route     *gpxRoute     `xml:"rte,omitempty"`
+}
+
+type gpxRoute struct {
+	Name   string        `xml:"name"`
+	Points []gpxWaypoint `xml:"rtept"`
+}
+
+type gpxWaypoint struct {
+	Lat         float64 `xml:"lat,attr"`
+	Lon         float64 `xml:"lon,attr"`
+	Time        string  `xml:"time,omitempty"`
+	Name        string  `xml:"name"`
+	Description string  `xml:"desc,omitempty"`
+}
+
+// ExportRoute exports tasks with coordinates in chronological order as GeoJSON or GPX
+func (s *TaskSrv) ExportRoute(ctx context.Context, userLogin, format string,
+	filter models.ItineraryFilter) (*models.RouteExport, error) {
+	if format == "" {
+		format = models.ExportGeoJSON
+	}
+	if format != models.ExportGeoJSON && format != models.ExportGPX {
+		return nil, errors.New("format must be geojson or gpx")
+	}
+	group, err := s.Group.GetGroup(ctx, filter.GroupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	tasks, err := s.Task.GetTaskList(ctx, userLogin, filter.GroupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	tasks = expandTasks(tasks, group.Trip)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].StartTime.Before(tasks[j].StartTime) })
+	loc := group.Trip.Location()
+	var from, to time.Time
+	if filter.From != "" || filter.To != "" {
+		first, last, err := itineraryRange(group.Trip, tasks, filter.From, filter.To, loc)
+		if err != nil {
+			return nil, err
+		}
+		from, to = first, last.AddDate(0, 0, 1)
+	}
+	var route []models.Task
+	for _, task := range tasks {
+		if !task.Location.HasCoordinates() {
+			continue
+		}
+		if !from.IsZero() && (!task.EndTime.After(from) || !task.StartTime.Before(to)) {
+			continue
+		}
+		route = append(route, task)
+	}
+
+	export := &models.RouteExport{FileName: fmt.Sprintf("%s.%s", group.ID.Hex(), format)}
+	switch format {
+	case models.ExportGPX:
+		export.ContentType = "application/gpx+xml"
+		export.Data, err = encodeGPX(group.Name, route, loc)
+	default:
+		export.ContentType = "application/geo+json"
+		export.Data, err = encodeGeoJSON(group.Name, route, loc)
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return export, nil
+}
+
+// GeoJSON wants longitude first
+func encodeGeoJSON(name string, route []models.Task, loc *time.Location) ([]byte, error) {
+	collection := geoJSONCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	line := make([][]float64, 0, len(route))
+	for i, task := range route {
+		point := []float64{*task.Location.Lon, *task.Location.Lat}
+		line = append(line, point)
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:     "Feature",
+			Geometry: geoJSONGeometry{Type: "Point", Coordinates: point},
+			Properties: map[string]interface{}{
+				"order":      i + 1,
+				"task_id":    task.ID.Hex(),
+				"title":      task.Title,
+				"start_time": task.StartTime.In(loc).Format(time.RFC3339),
+				"end_time":   task.EndTime.In(loc).Format(time.RFC3339),
+				"duration":   task.Duration,
+				"recurring":  task.Recurrence != nil,
+				"place":      task.Location.Name,
+				"address":    task.Location.Address,
+			},
+		})
+	}
+	if len(line) > 1 {
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: line},
+			Properties: map[string]interface{}{"name": name},
+		})
+	}
+	return json.Marshal(collection)
+}
+
+func encodeGPX(name string, route []models.Task, loc *time.Location) ([]byte, error) {
+	file := gpxFile{
+		Version:   "1.1",
+		Creator:   "JourneyPlanner",
+		Namespace: "http://www.topografix.com/GPX/1/1",
+		Name:      name,
+	}
+	for _, task := range route {
+		description := []string{task.StartTime.In(loc).Format("2006-01-02 15:04") + " - " + task.EndTime.In(loc).Format("15:04")}
+		for _, part := range []string{task.Location.Name, task.Location.Address} {
+			if part != "" {
+				description = append(description, part)
+			}
+		}
+		file.Waypoints = append(file.Waypoints, gpxWaypoint{
+			Lat:         *task.Location.Lat,
+			Lon:         *task.Location.Lon,
+			Time:        task.StartTime.UTC().Format(time.RFC3339),
+			Name:        task.Title,
+			Description: strings.Join(description, ", "),
+		})
+	}
+	if len(file.Waypoints) > 1 {
+		file.Route = &gpxRoute{Name: name, Points: file.Waypoints}
+	}
+	data, err := xml.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"slices"
+)
+
+// the fakes embed the repository interfaces, so a test only writes the methods the code under test calls
+
+type fakeTx struct{}
+
+func (fakeTx) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type fakeEvents struct {
+	events []models.Event
+}
+
+func (f *fakeEvents) Publish(_ context.Context, event models.Event) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+type fakeUsers struct {
+	UserRepository
+	users map[string]models.User
+}
+
+func (f *fakeUsers) GetUserByLogin(_ context.Context, login string) (*models.User, error) {
+	user, ok := f.users[login]
+	if !ok {
+		return nil, errors.New("user is not found")
+	}
+	return &user, nil
+}
+
+type fakeGroups struct {
+	GroupRepository
+	groups map[string]*models.Group
+}
+
+func (f *fakeGroups) GetGroup(_ context.Context, groupID string, userLogin ...string) (*models.Group, error) {
+	group, ok := f.groups[groupID]
+	if !ok || (len(userLogin) > 0 && !slices.Contains(group.Members, userLogin[0])) {
+		return nil, nil
+	}
+	copied := *group
+	copied.Members = slices.Clone(group.Members)
+	return &copied, nil
+}
+
+func (f *fakeGroups) JoinGroup(_ context.Context, groupID, userLogin string) error {
+	f.groups[groupID].Members = append(f.groups[groupID].Members, userLogin)
+	return nil
+}
+
+func (f *fakeGroups) LeaveGroup(_ context.Context, groupID, userLogin string) error {
+	group := f.groups[groupID]
+	group.Members = slices.DeleteFunc(group.Members, func(member string) bool { return member == userLogin })
+	return nil
+}
+
+type fakeInvites struct {
+	InviteRepository
+	invites map[string]models.Invitation
+}
+
+func (f *fakeInvites) DeleteInviteByToken(_ context.Context, token string) (*models.Invitation, error) {
+	invite, ok := f.invites[token]
+	if !ok {
+		return nil, nil
+	}
+	delete(f.invites, token)
+	return &invite, nil
+}
+
+type fakeBlacklist struct {
+	BlackListRepository
+}
+
+func (fakeBlacklist) GetBlacklist(_ context.Context, groupID string) (*models.BlackList, error) {
+	return &models.BlackList{}, nil
+}
+
+type fakeTasks struct {
+	TaskRepository
+	tasks   map[string]models.Task
+	updates map[string]models.Task
+}
+
+func (f *fakeTasks) GetTaskById(_ context.Context, taskID, groupID string) (*models.Task, error) {
+	task, ok := f.tasks[taskID]
+	if !ok {
+		return nil, errors.New("task is not found")
+	}
+	return &task, nil
+}
+
+func (f *fakeTasks) GetTaskList(_ context.Context, userLogin, groupID string) ([]models.Task, error) {
+	tasks := make([]models.Task, 0, len(f.tasks))
+	for _, task := range f.tasks {
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (f *fakeTasks) UpdateTask(_ context.Context, taskID string, newTask models.Task) error {
+	if f.updates == nil {
+		f.updates = make(map[string]models.Task)
+	}
+	f.updates[taskID] = newTask
+	return nil
+}
+
+type fakeRevisions struct {
+	TaskRevisionRepository
+}
+
+func (fakeRevisions) AddRevision(context.Context, models.TaskRevision) error {
+	return nil
+}
+
+type fakeReminders struct{}
+
+func (fakeReminders) RescheduleReminders(context.Context, string, string) {}
+
+func (fakeReminders) CancelReminders(context.Context, string) {}
+
+type fakePolls struct {
+	PollRepository
+	polls   map[string]models.Poll
+	changed []string
+}
+
+func (f *fakePolls) GetPollById(_ context.Context, pollID string) (*models.Poll, error) {
+	poll, ok := f.polls[pollID]
+	if !ok {
+		return nil, errors.New("poll is not found")
+	}
+	return &poll, nil
+}
+
+func (f *fakePolls) DeletePoll(_ context.Context, pollID string) error {
+	f.changed = append(f.changed, "delete "+pollID)
+	return nil
+}
+
+func (f *fakePolls) ClosePoll(_ context.Context, pollID string) error {
+	f.changed = append(f.changed, "close "+pollID)
+	return nil
+}
+
+func (f *fakePolls) RemoveVote(_ context.Context, pollID, userLogin string) error {
+	f.changed = append(f.changed, "unvote "+pollID)
+	return nil
+}
+
+func (f *fakePolls) AddVote(_ context.Context, pollID, voteOption, userLogin string) error {
+	f.changed = append(f.changed, "vote "+pollID)
+	return nil
+}
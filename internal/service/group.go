@@ -7,6 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/url"
+	"slices"
+	"strings"
 
 	"os"
 	"time"
@@ -24,6 +27,11 @@ func SetLogger(l *zap.Logger) {
 type GroupRepository interface {
 	CreateGroup(ctx context.Context, group models.Group) (string, error)
 	GetGroupList(ctx context.Context, userLogin string) ([]models.Group, error)
+	FilterGroups(ctx context.Context, userLogin string, filter models.GroupFilter) ([]models.Group, error)
+	UpdateTrip(ctx context.Context, groupID string, trip models.Trip) error
+	AddModerator(ctx context.Context, groupID, userLogin string) error
+	RemoveModerator(ctx context.Context, groupID, userLogin string) error
+	SetReminderMinutes(ctx context.Context, groupID string, minutes *int) error
 	GetGroup(ctx context.Context, groupID string, userLogin ...string) (*models.Group, error)
 	ChangeGroupLeader(ctx context.Context, groupID, userLogin string) error
 	DeleteGroup(ctx context.Context, groupID string) error
@@ -34,8 +42,10 @@ type InviteRepository interface {
 	AddInvitation(ctx context.Context, invite models.Invitation) error
 	GetInvites(ctx context.Context, userLogin string) ([]models.Invitation, error)
 	DeleteInviteByID(ctx context.Context, inviteID, userLogin string) (int64, error)
-	DeleteInviteByToken(ctx context.Context, token string) error
+	DeleteInviteByToken(ctx context.Context, token string) (*models.Invitation, error)
 	IsAlreadyInvited(ctx context.Context, groupID, userLogin string) (bool, error)
+	InvalidateUserInvites(ctx context.Context, userLogin string) error
+	PurgeInvites(ctx context.Context, createdBefore time.Time) (int64, error)
 }
 type BlackListRepository interface {
 	CreateBlacklist(ctx context.Context, groupID string) error
@@ -49,17 +59,19 @@ type WebSockerConn interface {
 }
 
 type GroupSrv struct {
-	Group                GroupRepository
-	User                 UserRepository
-	Invite               InviteRepository
-	BlackList            BlackListRepository
-	NotifyUserDisconnect func(userLogin string, groupID string)
+	Group     GroupRepository
+	User      UserRepository
+	Invite    InviteRepository
+	BlackList BlackListRepository
+	Task      TaskRepository
+	Events    EventPublisher
+	Tx        Transactor
 }
 
-func NewGroupSrv(groupRepo GroupRepository, userRepo UserRepository,
-	inviteRepo InviteRepository, blackList BlackListRepository) *GroupSrv {
+func NewGroupSrv(groupRepo GroupRepository, userRepo UserRepository, inviteRepo InviteRepository,
+	blackList BlackListRepository, taskRepo TaskRepository, events EventPublisher, tx Transactor) *GroupSrv {
 	return &GroupSrv{Group: groupRepo, User: userRepo,
-		Invite: inviteRepo, BlackList: blackList}
+		Invite: inviteRepo, BlackList: blackList, Task: taskRepo, Events: events, Tx: tx}
 }
 
 func (s *GroupSrv) CreateGroup(ctx context.Context, groupName, userLogin string) error {
@@ -68,22 +80,34 @@ func (s *GroupSrv) CreateGroup(ctx context.Context, groupName, userLogin string)
 		LeaderLogin: userLogin,
 		Members:     []string{userLogin},
 		IsActive:    true,
+		Trip:        models.Trip{Status: models.TripPlanning},
 	}
-	groupOID, err := s.Group.CreateGroup(ctx, group)
-	if err != nil {
-		logs.Error(err)
-		return fmt.Errorf("createGroup error: %v", err)
-	}
-	err = s.BlackList.CreateBlacklist(ctx, groupOID)
-	if err != nil {
-		logs.Error(err)
-		return fmt.Errorf("create blacklist error: %v", err)
-	}
-	return nil
+	// a group without its blacklist could not be joined, so they are created together
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		groupOID, err := s.Group.CreateGroup(ctx, group)
+		if err != nil {
+			logs.Error(err)
+			return fmt.Errorf("createGroup error: %v", err)
+		}
+		err = s.BlackList.CreateBlacklist(ctx, groupOID)
+		if err != nil {
+			logs.Error(err)
+			return fmt.Errorf("create blacklist error: %v", err)
+		}
+		return nil
+	})
 }
 
-func (s *GroupSrv) GetGroupList(ctx context.Context, userLogin string) ([]models.GroupList, error) {
-	groups, err := s.Group.GetGroupList(ctx, userLogin)
+func (s *GroupSrv) GetGroupList(ctx context.Context, userLogin string, filter models.GroupFilter) ([]models.GroupList, error) {
+	if filter.Status != "" && !slices.Contains(models.TripStatuses, filter.Status) {
+		return nil, errors.New("unknown trip status")
+	}
+	switch filter.SortBy {
+	case "", models.SortByStartDate, models.SortByStatus, models.SortByName:
+	default:
+		return nil, errors.New("groups can be sorted only by start_date, status or name")
+	}
+	groups, err := s.Group.FilterGroups(ctx, userLogin, filter)
 	if err != nil {
 		logs.Error(err)
 		return nil, errors.New("failed to get groups")
@@ -92,6 +116,10 @@ func (s *GroupSrv) GetGroupList(ctx context.Context, userLogin string) ([]models
 	if len(groups) == 0 {
 		return nil, errors.New("your grouplist is empty")
 	}
+	for i := range groups {
+		withTripDefaults(&groups[i])
+	}
+	sortGroups(groups, filter)
 
 	var groupsList []models.GroupList
 	for _, group := range groups {
@@ -99,11 +127,55 @@ func (s *GroupSrv) GetGroupList(ctx context.Context, userLogin string) ([]models
 			ID:           group.ID,
 			Name:         group.Name,
 			MembersCount: len(group.Members),
+			Status:       group.Trip.Status,
+			Destinations: group.Trip.Destinations,
+			StartDate:    group.Trip.StartDate,
+			EndDate:      group.Trip.EndDate,
 		})
 	}
 	return groupsList, nil
 }
 
+// groups without dates always go last, whatever the order is
+func sortGroups(groups []models.Group, filter models.GroupFilter) {
+	compareDates := func(a, b *time.Time) int {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return 1
+		case b == nil:
+			return -1
+		}
+		return a.Compare(*b)
+	}
+	direction := 1
+	if filter.Desc {
+		direction = -1
+	}
+	slices.SortStableFunc(groups, func(a, b models.Group) int {
+		switch filter.SortBy {
+		case models.SortByName:
+			return direction * strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		case models.SortByStatus:
+			diff := slices.Index(models.TripStatuses, a.Trip.Status) - slices.Index(models.TripStatuses, b.Trip.Status)
+			if diff != 0 {
+				return direction * diff
+			}
+		}
+		if a.Trip.StartDate == nil || b.Trip.StartDate == nil {
+			return compareDates(a.Trip.StartDate, b.Trip.StartDate)
+		}
+		return direction * compareDates(a.Trip.StartDate, b.Trip.StartDate)
+	})
+}
+
+func withTripDefaults(group *models.Group) {
+	if group.Trip.Status == "" {
+		group.Trip.Status = models.TripPlanning
+	}
+}
+
 func (s *GroupSrv) GetGroupByID(ctx context.Context, groupID, userLogin string) (*models.Group, error) {
 	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
 	if err != nil {
@@ -113,9 +185,126 @@ func (s *GroupSrv) GetGroupByID(ctx context.Context, groupID, userLogin string)
 	if group == nil {
 		return nil, errors.New("group is not found, or you are not a member of it")
 	}
+	withTripDefaults(group)
 	return group, nil
 }
 
+func (s *GroupSrv) UpdateTrip(ctx context.Context, groupID, userLogin string, update models.UpdateTrip) error {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	withTripDefaults(group)
+	trip := group.Trip
+	if update.Description != nil {
+		trip.Description = *update.Description
+	}
+	if update.Destinations != nil {
+		trip.Destinations = nil
+		for _, destination := range strings.Split(*update.Destinations, ",") {
+			destination = strings.TrimSpace(destination)
+			if destination != "" && !slices.Contains(trip.Destinations, destination) {
+				trip.Destinations = append(trip.Destinations, destination)
+			}
+		}
+	}
+	if update.StartDate != nil {
+		trip.StartDate, err = parseTripDate(*update.StartDate)
+		if err != nil {
+			return err
+		}
+	}
+	if update.EndDate != nil {
+		trip.EndDate, err = parseTripDate(*update.EndDate)
+		if err != nil {
+			return err
+		}
+	}
+	if trip.StartDate != nil && trip.EndDate != nil && trip.EndDate.Before(*trip.StartDate) {
+		return errors.New("trip cant end before it starts")
+	}
+	if update.CoverImage != nil {
+		if *update.CoverImage != "" {
+			cover, err := url.Parse(*update.CoverImage)
+			if err != nil || (cover.Scheme != "https" && cover.Scheme != "http") {
+				return errors.New("cover image must be http or https link")
+			}
+		}
+		trip.CoverImage = *update.CoverImage
+	}
+	if update.Status != nil {
+		if !slices.Contains(models.TripStatuses, *update.Status) {
+			return errors.New("unknown trip status")
+		}
+		trip.Status = *update.Status
+	}
+	if update.Timezone != nil {
+		if *update.Timezone != "" {
+			if _, err := time.LoadLocation(*update.Timezone); err != nil {
+				return errors.New("unknown timezone")
+			}
+		}
+		trip.Timezone = *update.Timezone
+	}
+	if update.StartDate != nil || update.EndDate != nil || update.Timezone != nil {
+		if err := s.checkTasksInTrip(ctx, group, trip); err != nil {
+			return err
+		}
+	}
+	err = s.Group.UpdateTrip(ctx, groupID, trip)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+// checkTasksInTrip rejects new trip dates that leave tasks of the group outside of the trip,
+// such tasks would silently disappear from the itinerary and free slots
+func (s *GroupSrv) checkTasksInTrip(ctx context.Context, group *models.Group, trip models.Trip) error {
+	tasks, err := s.Task.GetTaskList(ctx, group.LeaderLogin, group.ID.Hex())
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	var outside []string
+	for _, task := range tasks {
+		if err := checkTripWindow(trip, task); err != nil {
+			outside = append(outside, task.Title)
+			continue
+		}
+		if task.Recurrence != nil {
+			if _, err := newRecurrence(task.Recurrence.Rule, trip); err != nil {
+				outside = append(outside, task.Title)
+			}
+		}
+	}
+	if len(outside) > 0 {
+		return fmt.Errorf("tasks %s are outside of the new trip dates, move or delete them first",
+			strings.Join(outside, ", "))
+	}
+	return nil
+}
+
+// empty value clears the date
+func parseTripDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(models.TripDateFormat, value)
+	if err != nil {
+		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+	}
+	return &date, nil
+}
+
 func (s *GroupSrv) BanMember(ctx context.Context, groupID, memberLogin, userLogin string) error {
 	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
 	if err != nil {
@@ -138,18 +327,19 @@ func (s *GroupSrv) BanMember(ctx context.Context, groupID, memberLogin, userLogi
 	if !isOkay {
 		return errors.New("Member is not found")
 	}
-	err = s.Group.LeaveGroup(ctx, groupID, memberLogin)
-	if err != nil {
-		logs.Error(err)
-		return errors.New("failed to ban user")
-	}
-	err = s.BlackList.BanUser(ctx, groupID, memberLogin)
-	if err != nil {
-		logs.Error(err)
-		return errors.New("failed to ban user")
-	}
-	s.NotifyUserDisconnect(userLogin, groupID)
-	return nil
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Group.LeaveGroup(ctx, groupID, memberLogin)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("failed to ban user")
+		}
+		err = s.BlackList.BanUser(ctx, groupID, memberLogin)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("failed to ban user")
+		}
+		return publishEvent(ctx, s.Events, models.MemberBanned{Group: *group, Login: memberLogin, Actor: userLogin})
+	})
 }
 func (s *GroupSrv) UnbanMember(ctx context.Context, groupID, memberLogin, userLogin string) error {
 	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
@@ -178,12 +368,14 @@ func (s *GroupSrv) UnbanMember(ctx context.Context, groupID, memberLogin, userLo
 	if !isOkay {
 		return errors.New("this user is not banned in this group")
 	}
-	err = s.BlackList.UnbanUser(ctx, groupID, memberLogin)
-	if err != nil {
-		logs.Error(err)
-		return errors.New("failed to unban user")
-	}
-	return nil
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.BlackList.UnbanUser(ctx, groupID, memberLogin)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("failed to unban user")
+		}
+		return publishEvent(ctx, s.Events, models.MemberUnbanned{Group: *group, Login: memberLogin, Actor: userLogin})
+	})
 }
 
 func (s *GroupSrv) GetBlacklist(ctx context.Context, groupID, userLogin string) (*models.BlackList, error) {
@@ -223,23 +415,32 @@ func (s *GroupSrv) LeaveGroup(ctx context.Context, groupID, userLogin string) er
 		}
 		return nil
 	} else {
+		newLeader := ""
 		if group.LeaderLogin == userLogin {
-			newLeader := s.getRandomLeader(group.Members, userLogin)
-			err := s.Group.ChangeGroupLeader(ctx, groupID, newLeader)
+			newLeader = s.getRandomLeader(group.Members, userLogin)
+		}
+		// the leader leaves only together with passing the role on
+		return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+			if newLeader != "" {
+				err := s.Group.ChangeGroupLeader(ctx, groupID, newLeader)
+				if err != nil {
+					logs.Error(err)
+					return errors.New("failed to change group leader")
+				}
+			}
+
+			err := s.Group.LeaveGroup(ctx, groupID, userLogin)
 			if err != nil {
 				logs.Error(err)
-				return errors.New("failed to change group leader")
+				return errors.New("failed to leave group, please try later")
 			}
-		}
-
-		err := s.Group.LeaveGroup(ctx, groupID, userLogin)
-		if err != nil {
-			logs.Error(err)
-			return errors.New("failed to leave group, please try later")
-		}
+			err = publishEvent(ctx, s.Events, models.MemberLeft{Group: *group, Login: userLogin, NewLeader: newLeader})
+			if err != nil || newLeader == "" {
+				return err
+			}
+			return publishEvent(ctx, s.Events, models.LeaderChanged{Group: *group, Leader: newLeader, Actor: userLogin, ActorLeft: true})
+		})
 	}
-	s.NotifyUserDisconnect(userLogin, groupID)
-	return nil
 }
 
 func (s *GroupSrv) getRandomLeader(members []string, userLogin string) string {
@@ -282,10 +483,43 @@ func (s *GroupSrv) GiveLeaderRole(ctx context.Context, groupID, userLogin, membe
 	if !isRealMember {
 		return errors.New("no such member")
 	}
-	err = s.Group.ChangeGroupLeader(ctx, groupID, memberLogin)
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Group.ChangeGroupLeader(ctx, groupID, memberLogin)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("failed to change leader")
+		}
+		return publishEvent(ctx, s.Events, models.LeaderChanged{Group: *group, Leader: memberLogin, Actor: userLogin})
+	})
+}
+
+// SetModerator lets the leader give or take the moderator role, moderators review task proposals
+func (s *GroupSrv) SetModerator(ctx context.Context, groupID, userLogin, memberLogin string, moderator bool) error {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to get group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	if !slices.Contains(group.Members, memberLogin) {
+		return errors.New("no such member")
+	}
+	if memberLogin == group.LeaderLogin {
+		return errors.New("leader already has all the permissions")
+	}
+	if moderator {
+		err = s.Group.AddModerator(ctx, groupID, memberLogin)
+	} else {
+		err = s.Group.RemoveModerator(ctx, groupID, memberLogin)
+	}
 	if err != nil {
 		logs.Error(err)
-		return errors.New("failed to change leader")
+		return errors.New("failed to change moderators")
 	}
 	return nil
 }
@@ -369,17 +603,31 @@ func (s *GroupSrv) InviteUser(ctx context.Context, groupID, userLogin, invitedUs
 		Token:     inviteToken,
 		IsUsed:    false,
 	}
-	err = s.Invite.AddInvitation(ctx, invite)
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Invite.AddInvitation(ctx, invite)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("Failed to send invitation")
+		}
+		return publishEvent(ctx, s.Events, models.MemberInvited{Group: *group, Login: invitedUser, Actor: userLogin})
+	})
+}
+
+const inviteTTL = HoursInDay * time.Hour
+
+// PurgeInvites removes used invites and invites with expired tokens
+func (s *GroupSrv) PurgeInvites(ctx context.Context) error {
+	deleted, err := s.Invite.PurgeInvites(ctx, time.Now().UTC().Add(-inviteTTL))
 	if err != nil {
-		logs.Error(err)
-		return errors.New("Failed to send invitation")
+		return err
 	}
+	logs.Infof("purged %d invites", deleted)
 	return nil
 }
 
 func (s *GroupSrv) GetInviteToken(invitedUser, groupID string) (string, error) {
 	secretKey := os.Getenv("SECRET_KEY")
-	expirationTime := time.Now().UTC().Add(HoursInDay * time.Hour)
+	expirationTime := time.Now().UTC().Add(inviteTTL)
 
 	claims := &models.InvitationToken{
 		UserLogin: invitedUser,
@@ -437,17 +685,25 @@ func (s *GroupSrv) JoinGroup(ctx context.Context, token string) error {
 		return errors.New("You have been banned from this group")
 	}
 
-	err = s.Group.JoinGroup(ctx, inviteDetails.GroupID, inviteDetails.UserLogin)
-	if err != nil {
-		logs.Error(err)
-		return fmt.Errorf("JoinGroup error: %v", err)
-	}
-
-	err = s.Invite.DeleteInviteByToken(ctx, token)
-	if err != nil {
-		logs.Error(err)
-	}
-	return nil
+	// the invite is used up together with joining, a used, declined or cancelled invite does not let in
+	// even while its token is not expired
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		invite, err := s.Invite.DeleteInviteByToken(ctx, token)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		if invite == nil {
+			return errors.New("invite is no longer valid")
+		}
+		err = s.Group.JoinGroup(ctx, inviteDetails.GroupID, inviteDetails.UserLogin)
+		if err != nil {
+			logs.Error(err)
+			return fmt.Errorf("JoinGroup error: %v", err)
+		}
+		return publishEvent(ctx, s.Events, models.MemberJoined{Group: *group, Login: inviteDetails.UserLogin,
+			InvitedBy: invite.Sender})
+	})
 }
 
 func (s *GroupSrv) ValidateInvitationToken(tokenString string) (*models.InvitationToken, error) {
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func TestJoinGroupUsesUpTheInvite(t *testing.T) {
+	SetLogger(zap.NewNop())
+	t.Setenv("SECRET_KEY", "test-secret")
+	groupID := primitive.NewObjectID()
+	tests := []struct {
+		name string
+		// before runs after the invite is sent and before joining with its token
+		before  func(t *testing.T, s *GroupSrv, token string)
+		wantErr string
+	}{
+		{name: "valid invite", before: func(*testing.T, *GroupSrv, string) {}},
+		{name: "reused invite", before: func(t *testing.T, s *GroupSrv, token string) {
+			if err := s.JoinGroup(context.Background(), token); err != nil {
+				t.Fatal(err)
+			}
+			s.Group.LeaveGroup(context.Background(), groupID.Hex(), "bob123")
+		}, wantErr: "invite is no longer valid"},
+		{name: "declined invite", before: func(t *testing.T, s *GroupSrv, token string) {
+			s.Invite.DeleteInviteByToken(context.Background(), token)
+		}, wantErr: "invite is no longer valid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := &fakeGroups{groups: map[string]*models.Group{
+				groupID.Hex(): {ID: groupID, Name: "trip", LeaderLogin: "alice123", Members: []string{"alice123"}},
+			}}
+			events := &fakeEvents{}
+			s := NewGroupSrv(groups, &fakeUsers{users: map[string]models.User{"bob123": {Login: "bob123"}}},
+				&fakeInvites{invites: map[string]models.Invitation{}}, fakeBlacklist{}, nil, events, fakeTx{})
+			token, err := s.GetInviteToken("bob123", groupID.Hex())
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Invite.(*fakeInvites).invites[token] = models.Invitation{Sender: "alice123", Receiver: "bob123", Token: token}
+			tt.before(t, s, token)
+			events.events = nil
+
+			err = s.JoinGroup(context.Background(), token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(events.events) != 1 || events.events[0].(models.MemberJoined).InvitedBy != "alice123" {
+					t.Errorf("events = %v, want one MemberJoined invited by alice123", events.events)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("JoinGroup() error = %v, want %q", err, tt.wantErr)
+			}
+			if slices.Contains(groups.groups[groupID.Hex()].Members, "bob123") {
+				t.Error("bob123 joined with an invite that is no longer valid")
+			}
+			if len(events.events) != 0 {
+				t.Errorf("events = %v, want none", events.events)
+			}
+		})
+	}
+}
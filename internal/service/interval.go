@@ -0,0 +1,71 @@
+package service
+
+import (
+	"sort"
+	"time"
+)
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+func (i interval) minutes() int {
+	return int(i.end.Sub(i.start) / time.Minute)
+}
+
+// mergeIntervals sorts intervals and joins the ones that overlap or touch
+func mergeIntervals(intervals []interval) []interval {
+	if len(intervals) == 0 {
+		return nil
+	}
+	sorted := make([]interval, len(intervals))
+	copy(sorted, intervals)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start.Before(sorted[j].start) })
+	merged := []interval{sorted[0]}
+	for _, next := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if next.start.After(last.end) {
+			merged = append(merged, next)
+			continue
+		}
+		if next.end.After(last.end) {
+			last.end = next.end
+		}
+	}
+	return merged
+}
+
+// clipIntervals keeps only the parts of intervals that lie within [from, to)
+func clipIntervals(intervals []interval, from, to time.Time) []interval {
+	var clipped []interval
+	for _, i := range intervals {
+		if !i.end.After(from) || !i.start.Before(to) {
+			continue
+		}
+		if i.start.Before(from) {
+			i.start = from
+		}
+		if i.end.After(to) {
+			i.end = to
+		}
+		clipped = append(clipped, i)
+	}
+	return clipped
+}
+
+// freeIntervals returns the holes between merged busy intervals within [from, to)
+func freeIntervals(busy []interval, from, to time.Time) []interval {
+	var free []interval
+	cursor := from
+	for _, b := range clipIntervals(mergeIntervals(busy), from, to) {
+		if b.start.After(cursor) {
+			free = append(free, interval{start: cursor, end: b.start})
+		}
+		cursor = b.end
+	}
+	if to.After(cursor) {
+		free = append(free, interval{start: cursor, end: to})
+	}
+	return free
+}
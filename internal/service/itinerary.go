@@ -0,0 +1,163 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const maxItineraryDays = 366
+
+func (s *TaskSrv) GetItinerary(ctx context.Context, userLogin string, filter models.ItineraryFilter) (*models.Itinerary, error) {
+	mode := filter.Mode
+	if mode == "" {
+		mode = defaultTravelMode
+	}
+	speed, ok := s.Speeds[mode]
+	if !ok {
+		return nil, fmt.Errorf("unknown travel mode: %s", mode)
+	}
+	group, err := s.Group.GetGroup(ctx, filter.GroupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	tasks, err := s.Task.GetTaskList(ctx, userLogin, filter.GroupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	tasks = expandTasks(tasks, group.Trip)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].StartTime.Before(tasks[j].StartTime) })
+
+	loc := group.Trip.Location()
+	itinerary := &models.Itinerary{
+		GroupID:    group.ID,
+		Timezone:   loc.String(),
+		TravelMode: mode,
+		Days:       []models.ItineraryDay{},
+	}
+	first, last, err := itineraryRange(group.Trip, tasks, filter.From, filter.To, loc)
+	if err != nil {
+		return nil, err
+	}
+	if first.IsZero() {
+		return itinerary, nil
+	}
+	travels := travelBetween(tasks, mode, speed)
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		itinerary.Days = append(itinerary.Days, buildItineraryDay(tasks, travels, day, day.AddDate(0, 0, 1), loc))
+	}
+	return itinerary, nil
+}
+
+// itineraryRange returns local midnights of the first and the last shown day,
+// missing bounds are taken from the trip dates and then from the tasks themselves
+func itineraryRange(trip models.Trip, tasks []models.Task, fromDate, toDate string,
+	loc *time.Location) (first, last time.Time, err error) {
+	parse := func(value string) (time.Time, error) {
+		date, err := time.ParseInLocation(models.TripDateFormat, value, loc)
+		if err != nil {
+			return time.Time{}, errors.New("invalid date format, use YYYY-MM-DD")
+		}
+		return date, nil
+	}
+	tripFrom, tripTo := trip.Window()
+	switch {
+	case fromDate != "":
+		if first, err = parse(fromDate); err != nil {
+			return first, last, err
+		}
+	case !tripFrom.IsZero():
+		first = tripFrom
+	case len(tasks) > 0:
+		first = localMidnight(tasks[0].StartTime, loc)
+	}
+	switch {
+	case toDate != "":
+		if last, err = parse(toDate); err != nil {
+			return first, last, err
+		}
+	case !tripTo.IsZero():
+		last = tripTo.AddDate(0, 0, -1)
+	case len(tasks) > 0:
+		lastEnd := tasks[0].EndTime
+		for _, task := range tasks {
+			if task.EndTime.After(lastEnd) {
+				lastEnd = task.EndTime
+			}
+		}
+		// a task that ends exactly at midnight does not touch the next day
+		last = localMidnight(lastEnd.Add(-time.Nanosecond), loc)
+	}
+	if first.IsZero() || last.IsZero() {
+		return time.Time{}, time.Time{}, nil
+	}
+	if last.Before(first) {
+		return first, last, errors.New("date range ends before it starts")
+	}
+	if last.Sub(first) > maxItineraryDays*HoursInDay*time.Hour {
+		return first, last, fmt.Errorf("itinerary can show at most %d days", maxItineraryDays)
+	}
+	return first, last, nil
+}
+
+func localMidnight(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+func buildItineraryDay(tasks []models.Task, travels map[string]*models.Travel,
+	dayStart, dayEnd time.Time, loc *time.Location) models.ItineraryDay {
+	day := models.ItineraryDay{
+		Date:  dayStart.Format(models.TripDateFormat),
+		Items: []models.ItineraryItem{},
+		Gaps:  []models.Gap{},
+	}
+	var busy []interval
+	for _, task := range tasks {
+		if !task.EndTime.After(dayStart) || !task.StartTime.Before(dayEnd) {
+			continue
+		}
+		item := models.ItineraryItem{
+			TaskID:             task.ID,
+			Title:              task.Title,
+			Status:             task.CurrentStatus(),
+			StartTime:          task.StartTime.In(loc),
+			EndTime:            task.EndTime.In(loc),
+			Duration:           task.Duration,
+			SpansMidnight:      !localMidnight(task.StartTime, loc).Equal(localMidnight(task.EndTime.Add(-time.Nanosecond), loc)),
+			ContinuesFromPrior: task.StartTime.Before(dayStart),
+			Location:           task.Location,
+			Occurrence:         task.Occurrence,
+		}
+		if !item.ContinuesFromPrior {
+			item.Travel = travels[taskKey(task)]
+			if item.Travel != nil && item.Travel.Impossible {
+				day.ImpossibleTransitions++
+			}
+		}
+		day.Items = append(day.Items, item)
+		if item.Status == models.TaskCancelled {
+			continue
+		}
+		busy = append(busy, interval{start: task.StartTime.In(loc), end: task.EndTime.In(loc)})
+	}
+	busy = clipIntervals(mergeIntervals(busy), dayStart, dayEnd)
+	for i, b := range busy {
+		day.BusyMinutes += b.minutes()
+		if i > 0 {
+			gap := interval{start: busy[i-1].end, end: b.start}
+			day.Gaps = append(day.Gaps, models.Gap{StartTime: gap.start, EndTime: gap.end, Minutes: gap.minutes()})
+		}
+	}
+	// days are not always 24 hours long when clocks change
+	day.FreeMinutes = interval{start: dayStart, end: dayEnd}.minutes() - day.BusyMinutes
+	return day
+}
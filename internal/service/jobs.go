@@ -0,0 +1,264 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	schedulerLease = "job_scheduler"
+	// the lease outlives a few ticks, so a busy leader keeps it and a dead one is replaced quickly
+	schedulerLeaseTTL = 30 * time.Second
+	schedulerTick     = 5 * time.Second
+	// a job not finished in this time is considered crashed and is run again
+	jobLockTime = 5 * time.Minute
+	// a job is stopped before its lock runs out, so it is never run twice at the same time
+	jobTimeout     = jobLockTime - time.Minute
+	maxJobsPerTick = 100
+	// saving the outcome of a job gets its own time, it is saved also when the job was stopped by shutdown
+	jobSaveTimeout = 10 * time.Second
+)
+
+var DefaultRetryPolicy = models.RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   30 * time.Second,
+	MaxDelay:    30 * time.Minute,
+}
+
+type JobRepository interface {
+	ScheduleJob(ctx context.Context, job models.Job) error
+	EnsureJob(ctx context.Context, job models.Job) error
+	CancelJob(ctx context.Context, key string) error
+	ClaimJob(ctx context.Context, holder string, lockUntil time.Time) (*models.Job, error)
+	CompleteJob(ctx context.Context, job models.Job) error
+	RetryJob(ctx context.Context, job models.Job, runAt time.Time, lastError string) error
+	RescheduleJob(ctx context.Context, job models.Job, runAt time.Time, lastError string) error
+	FailJob(ctx context.Context, job models.Job, lastError string) error
+}
+
+type LeaseRepository interface {
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// JobQueue persists work to be done later by the scheduler
+type JobQueue interface {
+	Schedule(ctx context.Context, kind, key string, runAt time.Time, payload map[string]string) error
+	Cancel(ctx context.Context, key string) error
+}
+
+type JobHandler func(ctx context.Context, job models.Job) error
+
+// JobScheduler runs persisted jobs. Every replica can schedule jobs, only the holder of the lease runs them
+type JobScheduler struct {
+	Jobs     JobRepository
+	Lease    LeaseRepository
+	Retry    models.RetryPolicy
+	Instance string
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+	periodic map[string]time.Duration
+	isLeader bool
+}
+
+func NewJobScheduler(jobRepo JobRepository, leaseRepo LeaseRepository, retry models.RetryPolicy) *JobScheduler {
+	return &JobScheduler{
+		Jobs:     jobRepo,
+		Lease:    leaseRepo,
+		Retry:    retry,
+		Instance: newInstanceID(),
+		handlers: make(map[string]JobHandler),
+		periodic: make(map[string]time.Duration),
+	}
+}
+
+func newInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "replica"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		logs.Error(err)
+	}
+	return host + "-" + hex.EncodeToString(suffix)
+}
+
+// Register sets the handler of one-off jobs of the kind
+func (s *JobScheduler) Register(kind string, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = handler
+}
+
+// Every runs the handler periodically, the job is keyed by its kind so there is one per cluster
+func (s *JobScheduler) Every(kind string, interval time.Duration, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = handler
+	s.periodic[kind] = interval
+}
+
+func (s *JobScheduler) Schedule(ctx context.Context, kind, key string, runAt time.Time, payload map[string]string) error {
+	return s.Jobs.ScheduleJob(ctx, models.Job{
+		Key:       key,
+		Kind:      kind,
+		Payload:   payload,
+		RunAt:     runAt.UTC(),
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (s *JobScheduler) Cancel(ctx context.Context, key string) error {
+	return s.Jobs.CancelJob(ctx, key)
+}
+
+// Run works until ctx is done, the lease is given away on exit so another replica takes over at once
+func (s *JobScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+	defer func() {
+		if err := s.Lease.ReleaseLease(context.Background(), schedulerLease, s.Instance); err != nil {
+			logs.Error(err)
+		}
+	}()
+	for {
+		s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *JobScheduler) tick(ctx context.Context) {
+	leader, err := s.Lease.AcquireLease(ctx, schedulerLease, s.Instance, schedulerLeaseTTL)
+	if err != nil {
+		logs.Error(err)
+		return
+	}
+	wasLeader := s.isLeader
+	s.isLeader = leader
+	if !leader {
+		return
+	}
+	if !wasLeader {
+		logs.Infof("%s runs background jobs now", s.Instance)
+		s.ensurePeriodic(ctx)
+	}
+	// a job can run longer than the lease lives, so the lease is renewed while jobs run
+	stop := keepLease(ctx, s.Lease, schedulerLease, s.Instance, schedulerLeaseTTL)
+	defer stop()
+	deadline := time.Now().Add(schedulerLeaseTTL / 2)
+	for i := 0; i < maxJobsPerTick && time.Now().Before(deadline) && ctx.Err() == nil; i++ {
+		job, err := s.Jobs.ClaimJob(ctx, s.Instance, time.Now().UTC().Add(jobLockTime))
+		if err != nil {
+			logs.Error(err)
+			return
+		}
+		if job == nil {
+			return
+		}
+		s.runJob(ctx, *job)
+	}
+}
+
+func (s *JobScheduler) ensurePeriodic(ctx context.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now().UTC()
+	for kind, interval := range s.periodic {
+		err := s.Jobs.EnsureJob(ctx, models.Job{
+			Key:       kind,
+			Kind:      kind,
+			RunAt:     now,
+			Interval:  interval,
+			CreatedAt: now,
+		})
+		if err != nil {
+			logs.Error(err)
+		}
+	}
+}
+
+// keepLease renews the lease in the background until stop is called, stop waits for the renewal to end
+func keepLease(ctx context.Context, leases LeaseRepository, name, holder string, ttl time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			held, err := leases.AcquireLease(ctx, name, holder, ttl)
+			if err != nil && ctx.Err() == nil {
+				logs.Error(err)
+			}
+			if err == nil && !held {
+				logs.Errorf("%s lost the %s lease", holder, name)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (s *JobScheduler) runJob(ctx context.Context, job models.Job) {
+	s.mu.RLock()
+	handler, ok := s.handlers[job.Kind]
+	s.mu.RUnlock()
+	var err error
+	if ok {
+		jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+		err = callJobHandler(jobCtx, handler, job)
+		cancel()
+	} else {
+		err = fmt.Errorf("no handler for %s jobs", job.Kind)
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jobSaveTimeout)
+	defer cancel()
+	now := time.Now().UTC()
+	switch {
+	case err == nil && job.Interval > 0:
+		err = s.Jobs.RescheduleJob(ctx, job, now.Add(job.Interval), "")
+	case err == nil:
+		err = s.Jobs.CompleteJob(ctx, job)
+	case job.Attempts < s.Retry.MaxAttempts:
+		logs.Errorf("job %s failed, attempt %d: %v", job.Key, job.Attempts, err)
+		err = s.Jobs.RetryJob(ctx, job, now.Add(s.Retry.Delay(job.Attempts)), err.Error())
+	case job.Interval > 0:
+		logs.Errorf("periodic job %s failed %d times, waiting for next run: %v", job.Key, job.Attempts, err)
+		err = s.Jobs.RescheduleJob(ctx, job, now.Add(job.Interval), err.Error())
+	default:
+		logs.Errorf("job %s failed, giving up: %v", job.Key, err)
+		err = s.Jobs.FailJob(ctx, job, err.Error())
+	}
+	if err != nil {
+		logs.Error(err)
+	}
+}
+
+// callJobHandler turns a panic of the handler into a failed attempt, the scheduler keeps running
+func callJobHandler(ctx context.Context, handler JobHandler, job models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
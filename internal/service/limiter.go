@@ -0,0 +1,105 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LoginLimiter counts sign in attempts per key (account or ip).
+// MemoryLoginLimiter works for a single node, mongorepo.MongoLoginLimiter is shared between replicas.
+type LoginLimiter interface {
+	// Attempt reserves an attempt before the password is checked and counts it as failed, so parallel
+	// guesses can't all pass before a failure is counted. It returns for how long the key is blocked,
+	// a blocked attempt is not counted
+	Attempt(ctx context.Context, key string) (time.Duration, error)
+	// Forgive takes back an attempt that succeeded or was not made
+	Forgive(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
+
+var (
+	AccountLockoutPolicy = models.LockoutPolicy{
+		FreeAttempts: 3,
+		BaseDelay:    time.Second,
+		MaxDelay:     5 * time.Minute,
+		LockoutAfter: 10,
+		LockoutFor:   30 * time.Minute,
+		ResetAfter:   time.Hour,
+	}
+	IPLockoutPolicy = models.LockoutPolicy{
+		FreeAttempts: 10,
+		BaseDelay:    time.Second,
+		MaxDelay:     5 * time.Minute,
+		LockoutAfter: 50,
+		LockoutFor:   time.Hour,
+		ResetAfter:   time.Hour,
+	}
+)
+
+// LoginLockedError is returned when there were too many failed attempts
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed attempts, try again in %v", e.RetryAfter.Round(time.Second))
+}
+
+type MemoryLoginLimiter struct {
+	Policy   models.LockoutPolicy
+	mu       sync.Mutex
+	counters map[string]*models.LoginCounter
+}
+
+func NewMemoryLoginLimiter(policy models.LockoutPolicy) *MemoryLoginLimiter {
+	return &MemoryLoginLimiter{Policy: policy, counters: make(map[string]*models.LoginCounter)}
+}
+
+func (l *MemoryLoginLimiter) Attempt(_ context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now().UTC()
+	l.cleanup(now)
+	counter, ok := l.counters[key]
+	if !ok {
+		counter = &models.LoginCounter{Key: key}
+		l.counters[key] = counter
+	}
+	if counter.LockedUntil.After(now) {
+		return counter.LockedUntil.Sub(now), nil
+	}
+	if now.Sub(counter.LastFailure) > l.Policy.ResetAfter {
+		counter.Failures = 0
+	}
+	counter.Failures++
+	counter.LastFailure = now
+	counter.LockedUntil = now.Add(l.Policy.BlockFor(counter.Failures))
+	return 0, nil
+}
+
+func (l *MemoryLoginLimiter) Forgive(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if counter, ok := l.counters[key]; ok && counter.Failures > 0 {
+		counter.Failures--
+	}
+	return nil
+}
+
+func (l *MemoryLoginLimiter) Reset(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.counters, key)
+	return nil
+}
+
+func (l *MemoryLoginLimiter) cleanup(now time.Time) {
+	for key, counter := range l.counters {
+		if counter.IsExpired(now, l.Policy) {
+			delete(l.counters, key)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoryLoginLimiter(t *testing.T) {
+	tests := []struct {
+		name        string
+		attempts    int
+		afterEach   func(l *MemoryLoginLimiter, key string)
+		wantAllowed int
+	}{
+		{name: "free attempts then blocked", attempts: 6, wantAllowed: 3},
+		{name: "forgiven attempts are not counted", attempts: 6, wantAllowed: 6,
+			afterEach: func(l *MemoryLoginLimiter, key string) { l.Forgive(context.Background(), key) }},
+		{name: "reset starts over", attempts: 6, wantAllowed: 6,
+			afterEach: func(l *MemoryLoginLimiter, key string) { l.Reset(context.Background(), key) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewMemoryLoginLimiter(AccountLockoutPolicy)
+			allowed := 0
+			for i := 0; i < tt.attempts; i++ {
+				blocked, err := limiter.Attempt(context.Background(), "account:alice")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if blocked == 0 {
+					allowed++
+					if tt.afterEach != nil {
+						tt.afterEach(limiter, "account:alice")
+					}
+				}
+			}
+			if allowed != tt.wantAllowed {
+				t.Errorf("allowed %d attempts, want %d", allowed, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestMemoryLoginLimiterKeysAreSeparate(t *testing.T) {
+	limiter := NewMemoryLoginLimiter(AccountLockoutPolicy)
+	for i := 0; i < AccountLockoutPolicy.FreeAttempts; i++ {
+		limiter.Attempt(context.Background(), "account:alice")
+	}
+	if blocked, _ := limiter.Attempt(context.Background(), "account:alice"); blocked == 0 {
+		t.Error("alice is not blocked")
+	}
+	if blocked, _ := limiter.Attempt(context.Background(), "account:bob"); blocked != 0 {
+		t.Errorf("bob is blocked for %v", blocked)
+	}
+}
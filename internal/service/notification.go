@@ -0,0 +1,435 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"JourneyPlanner/pkg/mail"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultNotificationLimit = 20
+	maxNotificationLimit     = 100
+	// pushRetryInterval is the pause before watching new notifications again after the change stream failed
+	pushRetryInterval = 5 * time.Second
+)
+
+type NotificationRepository interface {
+	AddNotification(ctx context.Context, notification models.Notification) (*models.Notification, error)
+	GetNotifications(ctx context.Context, filter models.NotificationFilter) ([]models.Notification, int64, error)
+	CountUnread(ctx context.Context, userLogin string) (int64, error)
+	MarkRead(ctx context.Context, notificationID, userLogin string) (bool, error)
+	MarkAllRead(ctx context.Context, userLogin string) (int64, error)
+	GetNotification(ctx context.Context, notificationID string) (*models.Notification, error)
+	GetEmailPending(ctx context.Context, userLogin string) ([]models.Notification, error)
+	ClearEmailPending(ctx context.Context, ids []primitive.ObjectID) error
+	DeleteUserNotifications(ctx context.Context, userLogin string) error
+	WatchNotifications(ctx context.Context, push func(models.Notification)) error
+}
+
+type NotificationSettingsRepository interface {
+	GetSettings(ctx context.Context, userLogin string) (*models.NotificationSettings, error)
+	SetPreference(ctx context.Context, userLogin string, preference models.NotificationPreference) error
+	DeletePreference(ctx context.Context, userLogin, groupID, kind string) error
+	SetDigest(ctx context.Context, userLogin string, digest bool, hour *int) error
+	GetDigestUsers(ctx context.Context) ([]models.NotificationSettings, error)
+	ClaimDigest(ctx context.Context, userLogin string, last *time.Time, now time.Time, day string) (bool, error)
+	ReleaseDigest(ctx context.Context, userLogin string, last *time.Time, lastDay string, claimed time.Time) error
+	DeleteSettings(ctx context.Context, userLogin string) error
+}
+
+// Notifier delivers a notification to the inbox of every user, the key of the notification
+// makes delivering it twice a no-op, notifications without a key are always delivered
+type Notifier interface {
+	Notify(ctx context.Context, notification models.Notification, userLogins ...string) error
+}
+
+// NotificationSrv is the personal inbox of the user, it also sends the notifications the user wants by email
+type NotificationSrv struct {
+	Notification NotificationRepository
+	Settings     NotificationSettingsRepository
+	Group        GroupRepository
+	User         UserRepository
+	Mailer       mail.Mailer
+	Jobs         JobQueue
+	// Push sends a new notification to the personal connections of the user on this replica
+	Push func(userLogin string, notification models.Notification)
+}
+
+func NewNotificationSrv(notificationRepo NotificationRepository, settingsRepo NotificationSettingsRepository,
+	groupRepo GroupRepository, userRepo UserRepository, mailer mail.Mailer, jobs JobQueue) *NotificationSrv {
+	return &NotificationSrv{Notification: notificationRepo, Settings: settingsRepo, Group: groupRepo,
+		User: userRepo, Mailer: mailer, Jobs: jobs}
+}
+
+func (s *NotificationSrv) getSettings(ctx context.Context, userLogin string) (*models.NotificationSettings, error) {
+	settings, err := s.Settings.GetSettings(ctx, userLogin)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &models.NotificationSettings{UserLogin: userLogin}
+	}
+	if settings.Preferences == nil {
+		settings.Preferences = []models.NotificationPreference{}
+	}
+	return settings, nil
+}
+
+// Notify delivers the notification the way every user chose for its kind and group.
+// Emails are sent by a job, or wait for the daily digest if the user wants one
+func (s *NotificationSrv) Notify(ctx context.Context, notification models.Notification, userLogins ...string) error {
+	key := notification.Key
+	if key == "" {
+		key = primitive.NewObjectID().Hex()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now().UTC()
+	}
+	var failed error
+	for _, userLogin := range userLogins {
+		settings, err := s.getSettings(ctx, userLogin)
+		if err != nil {
+			failed = err
+			continue
+		}
+		delivery := settings.Delivery(notification.GroupID, notification.Kind)
+		if delivery == models.DeliveryNone {
+			continue
+		}
+		byEmail := delivery == models.DeliveryEmail || delivery == models.DeliveryBoth
+		notification.Key = key + ":" + userLogin
+		notification.UserLogin = userLogin
+		notification.Hidden = delivery == models.DeliveryEmail
+		notification.EmailPending = byEmail && settings.Digest
+		saved, err := s.Notification.AddNotification(ctx, notification)
+		if err != nil {
+			failed = err
+			continue
+		}
+		if saved == nil {
+			continue
+		}
+		if byEmail && !settings.Digest {
+			err = s.Jobs.Schedule(ctx, models.JobNotificationEmail, notificationEmailJobKey(saved.ID.Hex()),
+				time.Now(), map[string]string{"notification_id": saved.ID.Hex()})
+			if err != nil {
+				failed = err
+			}
+		}
+	}
+	return failed
+}
+
+// RunPush pushes every new notification to the connections of its user on this replica,
+// whichever replica saved it. It runs until the context is done
+func (s *NotificationSrv) RunPush(ctx context.Context) {
+	for {
+		err := s.Notification.WatchNotifications(ctx, func(notification models.Notification) {
+			if s.Push != nil {
+				s.Push(notification.UserLogin, notification)
+			}
+		})
+		if err != nil {
+			logs.Error(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pushRetryInterval):
+		}
+	}
+}
+
+func notificationEmailJobKey(notificationID string) string {
+	return models.JobNotificationEmail + ":" + notificationID
+}
+
+// SendNotificationEmail is the job that emails one notification
+func (s *NotificationSrv) SendNotificationEmail(ctx context.Context, job models.Job) error {
+	notification, err := s.Notification.GetNotification(ctx, job.Payload["notification_id"])
+	if err != nil {
+		return err
+	}
+	if notification == nil {
+		return nil
+	}
+	// a deleted user is simply not found
+	users, err := s.User.GetUsersByLogins(ctx, []string{notification.UserLogin})
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 || users[0].Email == "" {
+		return nil
+	}
+	return s.Mailer.Send(ctx, users[0].Email, "Journey Planner: "+notification.Text, notification.Text)
+}
+
+func (s *NotificationSrv) GetNotificationSettings(ctx context.Context, userLogin string) (*models.NotificationSettings, error) {
+	settings, err := s.getSettings(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return settings, nil
+}
+
+// SetPreference chooses how notifications of the kind are delivered, in one group or in all of them
+func (s *NotificationSrv) SetPreference(ctx context.Context, userLogin string, preference models.NotificationPreference) error {
+	if !slices.Contains(models.NotificationKinds, preference.Kind) {
+		return errors.New("unknown notification kind")
+	}
+	if !slices.Contains(models.Deliveries, preference.Delivery) {
+		return errors.New("delivery must be in_app, email, both or none")
+	}
+	if err := s.checkMember(ctx, preference.GroupID, userLogin); err != nil {
+		return err
+	}
+	err := s.Settings.SetPreference(ctx, userLogin, preference)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+// ResetPreference removes the preference, the one for all groups or the in-app default applies again
+func (s *NotificationSrv) ResetPreference(ctx context.Context, userLogin, groupID, kind string) error {
+	if !slices.Contains(models.NotificationKinds, kind) {
+		return errors.New("unknown notification kind")
+	}
+	err := s.Settings.DeletePreference(ctx, userLogin, groupID, kind)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+// SetDigest turns the daily digest on or off and sets the local hour it is sent at when hour is not nil,
+// emails waiting for the digest are sent at once when it is turned off
+func (s *NotificationSrv) SetDigest(ctx context.Context, userLogin string, digest bool, hour *int) error {
+	if hour != nil && (*hour < 0 || *hour >= HoursInDay) {
+		return errors.New("digest hour must be from 0 to 23")
+	}
+	err := s.Settings.SetDigest(ctx, userLogin, digest, hour)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if digest {
+		return nil
+	}
+	pending, err := s.Notification.GetEmailPending(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	ids := make([]primitive.ObjectID, 0, len(pending))
+	for _, notification := range pending {
+		err = s.Jobs.Schedule(ctx, models.JobNotificationEmail, notificationEmailJobKey(notification.ID.Hex()),
+			time.Now(), map[string]string{"notification_id": notification.ID.Hex()})
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		ids = append(ids, notification.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := s.Notification.ClearEmailPending(ctx, ids); err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+func (s *NotificationSrv) checkMember(ctx context.Context, groupID, userLogin string) error {
+	if groupID == "" {
+		return nil
+	}
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	return nil
+}
+
+// notifyMembers tells every member of the group except the one who made the change
+func notifyMembers(ctx context.Context, notifier Notifier, group *models.Group, actor string, notification models.Notification) {
+	members := slices.DeleteFunc(slices.Clone(group.Members), func(member string) bool { return member == actor })
+	notification.GroupID = group.ID.Hex()
+	if err := notifier.Notify(ctx, notification, members...); err != nil {
+		logs.Error(err)
+	}
+}
+
+// Subscribe creates the notifications of the group events, they are keyed by the event so a repeated one is skipped
+func (s *NotificationSrv) Subscribe(bus *EventBus) {
+	Subscribe(bus, func(ctx context.Context, event models.MemberBanned) {
+		err := s.Notify(ctx, models.Notification{
+			Key:     eventKey(ctx),
+			Kind:    models.NotificationBan,
+			GroupID: event.Group.ID.Hex(),
+			Text:    fmt.Sprintf("You were banned from the group %s", event.Group.Name),
+		}, event.Login)
+		if err != nil {
+			logs.Error(err)
+		}
+	})
+	Subscribe(bus, func(ctx context.Context, event models.MemberInvited) {
+		err := s.Notify(ctx, models.Notification{
+			Key:     eventKey(ctx),
+			Kind:    models.NotificationInvite,
+			GroupID: event.Group.ID.Hex(),
+			Text:    fmt.Sprintf("%s invited you to the group %s", event.Actor, event.Group.Name),
+		}, event.Login)
+		if err != nil {
+			logs.Error(err)
+		}
+	})
+	Subscribe(bus, func(ctx context.Context, event models.LeaderChanged) {
+		text := fmt.Sprintf("%s is the new leader of the group %s", event.Leader, event.Group.Name)
+		if event.ActorLeft {
+			text = fmt.Sprintf("%s left the group %s, %s is the new leader", event.Actor, event.Group.Name, event.Leader)
+		}
+		notifyMembers(ctx, s, &event.Group, event.Actor, models.Notification{
+			Key:  eventKey(ctx),
+			Kind: models.NotificationLeaderChanged,
+			Text: text,
+			Data: map[string]string{"leader": event.Leader},
+		})
+	})
+	Subscribe(bus, func(ctx context.Context, event models.TaskCreated) {
+		s.notifyTaskChange(ctx, event.TaskChange, taskCreated)
+	})
+	Subscribe(bus, func(ctx context.Context, event models.TaskUpdated) {
+		s.notifyTaskChange(ctx, event.TaskChange, taskUpdated)
+	})
+	Subscribe(bus, func(ctx context.Context, event models.TaskDeleted) {
+		s.notifyTaskChange(ctx, event.TaskChange, taskDeleted)
+	})
+	Subscribe(bus, func(ctx context.Context, event models.PollCreated) {
+		notifyMembers(ctx, s, &event.Group, event.Actor, models.Notification{
+			Key:  eventKey(ctx),
+			Kind: models.NotificationPollCreated,
+			Text: fmt.Sprintf("%s started the poll %q: %s or %s",
+				event.Actor, event.Poll.Title, event.Poll.FirstOption, event.Poll.SecondOption),
+			Data: map[string]string{"poll_id": event.Poll.ID.Hex()},
+		})
+	})
+	Subscribe(bus, s.notifyPollResult)
+	Subscribe(bus, func(ctx context.Context, event models.TaskProposalRejected) {
+		if event.Proposal.ProposedBy == event.Actor {
+			return
+		}
+		err := s.Notify(ctx, models.Notification{
+			Key:     eventKey(ctx),
+			Kind:    models.NotificationProposalRejected,
+			GroupID: event.Group.ID.Hex(),
+			Text: fmt.Sprintf("%s rejected your proposal %s in the group %s: %s",
+				event.Actor, event.Proposal.Task.Title, event.Group.Name, event.Reason),
+			Data: map[string]string{"proposal_id": event.Proposal.ID.Hex()},
+		}, event.Proposal.ProposedBy)
+		if err != nil {
+			logs.Error(err)
+		}
+	})
+}
+
+func (s *NotificationSrv) notifyTaskChange(ctx context.Context, change models.TaskChange, kind string) {
+	notifyMembers(ctx, s, &change.Group, change.Actor, models.Notification{
+		Key:  eventKey(ctx),
+		Kind: models.NotificationTaskChanged,
+		Text: change.Summary,
+		Data: map[string]string{"task_id": change.TaskID.Hex(), "change": kind},
+	})
+}
+
+func (s *NotificationSrv) notifyPollResult(ctx context.Context, event models.PollClosed) {
+	poll, result := event.Poll, event.Result
+	group, err := s.Group.GetGroup(ctx, poll.GroupID.Hex())
+	if err != nil {
+		logs.Error(err)
+		return
+	}
+	if group == nil {
+		return
+	}
+	text := fmt.Sprintf("Poll %q is over: votes are tied", poll.Title)
+	if result.Winner != "" {
+		text = fmt.Sprintf("Poll %q is over: %s won", poll.Title, result.Winner)
+	}
+	if poll.HasActions() && result.Message != "" {
+		text += ", " + result.Message
+	}
+	notifyMembers(ctx, s, group, "", models.Notification{
+		Key:  "poll_result:" + poll.ID.Hex(),
+		Kind: models.NotificationPollResult,
+		Text: text,
+		Data: map[string]string{"poll_id": poll.ID.Hex(), "winner": result.Winner, "status": result.Status},
+	})
+}
+
+func (s *NotificationSrv) GetNotifications(ctx context.Context, filter models.NotificationFilter) (*models.NotificationPage, error) {
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultNotificationLimit
+	}
+	if filter.Page < 1 {
+		return nil, errors.New("page must be positive")
+	}
+	if filter.Limit < 1 || filter.Limit > maxNotificationLimit {
+		return nil, errors.New("limit must be from 1 to 100")
+	}
+	notifications, total, err := s.Notification.GetNotifications(ctx, filter)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	unread, err := s.Notification.CountUnread(ctx, filter.UserLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return &models.NotificationPage{
+		Notifications: notifications,
+		Page:          filter.Page,
+		Limit:         filter.Limit,
+		Total:         total,
+		Unread:        unread,
+	}, nil
+}
+
+func (s *NotificationSrv) MarkRead(ctx context.Context, notificationID, userLogin string) error {
+	found, err := s.Notification.MarkRead(ctx, notificationID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if !found {
+		return errors.New("notification is not found")
+	}
+	return nil
+}
+
+func (s *NotificationSrv) MarkAllRead(ctx context.Context, userLogin string) (int64, error) {
+	count, err := s.Notification.MarkAllRead(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return 0, errors.New("System error")
+	}
+	return count, nil
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UpdateOccurrence edits one occurrence of a recurring task, or splits the series
+// so that this and all following occurrences get the changes
+func (s *TaskSrv) UpdateOccurrence(ctx context.Context, taskID, userLogin, occurrence, scope string,
+	update models.CreateTask) error {
+	if scope != models.ScopeThisOccurrence && scope != models.ScopeFollowing {
+		return errors.New("scope must be this or following")
+	}
+	group, task, tasks, err := s.getSeries(ctx, update.GroupID, taskID, userLogin)
+	if err != nil {
+		return err
+	}
+	current, err := findOccurrence(*task, group.Trip, occurrence)
+	if err != nil {
+		return err
+	}
+	startTime := current.StartTime
+	if !update.StartTime.IsFullEmpty() {
+		startTime, err = parseTaskStart(update.StartTime, group.Trip)
+		if err != nil {
+			return fmt.Errorf("invalid date or time format: %v", err)
+		}
+		if startTime.Before(time.Now().UTC()) {
+			return errors.New("you cant add tasks to past time")
+		}
+	}
+	duration := current.Duration
+	if !update.Duration.IsEmpty() {
+		duration = calculateDuration(update.Duration)
+	}
+	title := current.Title
+	if update.Title != "" {
+		title = update.Title
+	}
+	changed := models.Task{Title: title, StartTime: startTime, Duration: duration,
+		EndTime: startTime.Add(time.Duration(duration) * time.Minute)}
+	if current.Occurrence.Equal(task.StartTime) && scope == models.ScopeFollowing {
+		_, err := s.UpdateTask(ctx, taskID, userLogin, update)
+		return err
+	}
+
+	if scope == models.ScopeThisOccurrence {
+		if update.Location != nil || update.Recurrence != "" {
+			return errors.New("only title, start and duration can be changed for one occurrence")
+		}
+		if err := checkTripWindow(group.Trip, changed); err != nil {
+			return err
+		}
+		recurrence := *task.Recurrence
+		recurrence.Overrides = slices.DeleteFunc(slices.Clone(recurrence.Overrides), func(o models.OccurrenceOverride) bool {
+			return o.Occurrence.Equal(*current.Occurrence)
+		})
+		recurrence.Overrides = append(recurrence.Overrides, models.OccurrenceOverride{
+			Occurrence: *current.Occurrence,
+			Title:      changed.Title,
+			StartTime:  changed.StartTime,
+			EndTime:    changed.EndTime,
+			Duration:   changed.Duration,
+		})
+		series := *task
+		series.Recurrence = &recurrence
+		if err := findOverlap(replaceTask(tasks, series), []primitive.ObjectID{series.ID}, group.Trip); err != nil {
+			return err
+		}
+		err := inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+			if err := s.saveRecurrence(ctx, taskID, &recurrence); err != nil {
+				return err
+			}
+			return s.publishTaskChange(ctx, group, userLogin, task, task.ID, taskUpdated,
+				fmt.Sprintf("%s changed the task %s on %s",
+					userLogin, task.Title, current.Occurrence.In(group.Trip.Location()).Format(models.TripDateFormat)))
+		})
+		if err != nil {
+			return err
+		}
+		s.Reminders.RescheduleReminders(ctx, update.GroupID, taskID)
+		return nil
+	}
+
+	earlier, following, err := splitSeries(*task, *current.Occurrence, group.Trip)
+	if err != nil {
+		return err
+	}
+	// the new series keeps the series defaults for everything that was not sent
+	following.ID = primitive.NewObjectID()
+	if update.Title != "" {
+		following.Title = update.Title
+	}
+	if !update.Duration.IsEmpty() {
+		following.Duration = duration
+	}
+	following.StartTime = current.Occurrence.UTC()
+	followingRule := following.Recurrence.Rule
+	if update.Recurrence != "" {
+		recurrence, err := newRecurrence(update.Recurrence, group.Trip)
+		if err != nil {
+			return err
+		}
+		recurrence.Cancelled = following.Recurrence.Cancelled
+		recurrence.Overrides = following.Recurrence.Overrides
+		following.Recurrence = recurrence
+	}
+	if !update.StartTime.IsFullEmpty() {
+		following.Recurrence, err = shiftRecurrence(following.Recurrence, followingRule,
+			following.StartTime, startTime, group.Trip)
+		if err != nil {
+			return err
+		}
+		following.StartTime = startTime
+	}
+	following.EndTime = following.StartTime.Add(time.Duration(following.Duration) * time.Minute)
+	if err := checkTripWindow(group.Trip, following); err != nil {
+		return err
+	}
+	if update.Location != nil {
+		if err := validateCoordinates(update.Location); err != nil {
+			return err
+		}
+		following.Location = update.Location
+	}
+	planned := append(replaceTask(tasks, earlier), following)
+	if err := findOverlap(planned, []primitive.ObjectID{earlier.ID, following.ID}, group.Trip); err != nil {
+		return err
+	}
+	// the series is split in one go, otherwise the following occurrences could be lost
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		if err := s.saveRecurrence(ctx, taskID, earlier.Recurrence); err != nil {
+			return err
+		}
+		err := s.recordTaskChange(ctx, group, userLogin, task, fmt.Sprintf("%s ended the task %s before %s",
+			userLogin, task.Title, current.Occurrence.In(group.Trip.Location()).Format(models.TripDateFormat)))
+		if err != nil {
+			return err
+		}
+		err = s.Task.AddTask(ctx, following, update.GroupID)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		// the following part is a new task, it has no earlier version of its own
+		return s.publishTaskChange(ctx, group, userLogin, nil, following.ID, taskUpdated,
+			fmt.Sprintf("%s changed the task %s starting from %s",
+				userLogin, task.Title, current.Occurrence.In(group.Trip.Location()).Format(models.TripDateFormat)))
+	})
+	if err != nil {
+		return err
+	}
+	s.Reminders.RescheduleReminders(ctx, update.GroupID, taskID)
+	s.Reminders.RescheduleReminders(ctx, update.GroupID, following.ID.Hex())
+	return nil
+}
+
+// CancelOccurrence skips one occurrence of a recurring task, the rest of the series stays
+func (s *TaskSrv) CancelOccurrence(ctx context.Context, groupID, taskID, userLogin, occurrence string) error {
+	group, task, _, err := s.getSeries(ctx, groupID, taskID, userLogin)
+	if err != nil {
+		return err
+	}
+	current, err := findOccurrence(*task, group.Trip, occurrence)
+	if err != nil {
+		return err
+	}
+	recurrence := *task.Recurrence
+	recurrence.Cancelled = append(slices.Clone(recurrence.Cancelled), *current.Occurrence)
+	recurrence.Overrides = slices.DeleteFunc(slices.Clone(recurrence.Overrides), func(o models.OccurrenceOverride) bool {
+		return o.Occurrence.Equal(*current.Occurrence)
+	})
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		if err := s.saveRecurrence(ctx, taskID, &recurrence); err != nil {
+			return err
+		}
+		return s.publishTaskChange(ctx, group, userLogin, task, task.ID, taskUpdated,
+			fmt.Sprintf("%s cancelled the task %s on %s",
+				userLogin, task.Title, current.Occurrence.In(group.Trip.Location()).Format(models.TripDateFormat)))
+	})
+	if err != nil {
+		return err
+	}
+	s.Reminders.RescheduleReminders(ctx, groupID, taskID)
+	return nil
+}
+
+func (s *TaskSrv) getSeries(ctx context.Context, groupID, taskID, userLogin string) (*models.Group, *models.Task, []models.Task, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, nil, nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, nil, nil, errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return nil, nil, nil, errors.New("you have no permissions to do this")
+	}
+	tasks, err := s.Task.GetTaskList(ctx, userLogin, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, nil, nil, errors.New("System error")
+	}
+	for i := range tasks {
+		if tasks[i].ID.Hex() == taskID {
+			if tasks[i].Recurrence == nil {
+				return nil, nil, nil, errors.New("task is not recurring")
+			}
+			return group, &tasks[i], tasks, nil
+		}
+	}
+	return nil, nil, nil, errors.New("task was not found")
+}
+
+func (s *TaskSrv) saveRecurrence(ctx context.Context, taskID string, recurrence *models.Recurrence) error {
+	err := s.Task.UpdateTask(ctx, taskID, models.Task{Recurrence: recurrence})
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+// findOccurrence accepts the original start of an occurrence as RFC 3339 time
+func findOccurrence(task models.Task, trip models.Trip, occurrence string) (*models.Task, error) {
+	start, err := time.Parse(time.RFC3339, occurrence)
+	if err != nil {
+		return nil, errors.New("occurrence must be its original start time in RFC 3339 format")
+	}
+	_, end := trip.Window()
+	for _, candidate := range expandTask(task, trip.Location(), end) {
+		if candidate.Occurrence.Equal(start) {
+			if candidate.StartTime.Before(time.Now().UTC()) {
+				return nil, errors.New("you cant change occurrences in the past")
+			}
+			return &candidate, nil
+		}
+	}
+	return nil, errors.New("occurrence was not found")
+}
+
+// splitSeries ends the series the day before the occurrence and starts a copy of it from the occurrence
+func splitSeries(task models.Task, occurrence time.Time, trip models.Trip) (earlier, following models.Task, err error) {
+	rule, err := parseRRule(task.Recurrence.Rule)
+	if err != nil {
+		return earlier, following, fmt.Errorf("invalid recurrence: %v", err)
+	}
+	loc := trip.Location()
+	index := 0
+	for _, start := range rule.occurrences(task.StartTime, loc, occurrence) {
+		if start.Before(occurrence) {
+			index++
+		}
+	}
+	earlierRule, followingRule := *rule, *rule
+	earlierRule.count = 0
+	day := occurrence.In(loc).AddDate(0, 0, -1)
+	earlierRule.until = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	if rule.count > 0 {
+		followingRule.count = rule.count - index
+	}
+
+	earlier, following = task, task
+	// the new series has its own checklist, items are found by id within the task
+	following.Checklist = make([]models.ChecklistItem, len(task.Checklist))
+	for i, item := range task.Checklist {
+		item.ID = primitive.NewObjectID()
+		following.Checklist[i] = item
+	}
+	earlier.Recurrence = &models.Recurrence{Rule: earlierRule.String()}
+	following.Recurrence = &models.Recurrence{Rule: followingRule.String()}
+	for _, cancelled := range task.Recurrence.Cancelled {
+		if cancelled.Before(occurrence) {
+			earlier.Recurrence.Cancelled = append(earlier.Recurrence.Cancelled, cancelled)
+		} else {
+			following.Recurrence.Cancelled = append(following.Recurrence.Cancelled, cancelled)
+		}
+	}
+	for _, override := range task.Recurrence.Overrides {
+		if override.Occurrence.Before(occurrence) {
+			earlier.Recurrence.Overrides = append(earlier.Recurrence.Overrides, override)
+		} else if !override.Occurrence.Equal(occurrence) {
+			following.Recurrence.Overrides = append(following.Recurrence.Overrides, override)
+		}
+	}
+	return earlier, following, nil
+}
+
+// shiftRecurrence moves cancelled and edited occurrences along with the series start:
+// the n-th occurrence from the old start becomes the n-th occurrence from the new one.
+// Edited occurrences keep their offset from the occurrence and must stay in the trip
+func shiftRecurrence(recurrence *models.Recurrence, oldRule string, from, to time.Time,
+	trip models.Trip) (*models.Recurrence, error) {
+	shifted := &models.Recurrence{Rule: recurrence.Rule}
+	if len(recurrence.Cancelled) == 0 && len(recurrence.Overrides) == 0 {
+		return shifted, nil
+	}
+	before, err := parseRRule(oldRule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence: %v", err)
+	}
+	after, err := parseRRule(recurrence.Rule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence: %v", err)
+	}
+	loc := trip.Location()
+	oldStarts := before.occurrences(from, loc, time.Time{})
+	newStarts := after.occurrences(to, loc, time.Time{})
+	// occurrences past the end of the moved series are gone, and so are their edits
+	moved := func(occurrence time.Time) (time.Time, bool) {
+		index := slices.IndexFunc(oldStarts, occurrence.Equal)
+		if index < 0 || index >= len(newStarts) {
+			return time.Time{}, false
+		}
+		return newStarts[index], true
+	}
+	for _, cancelled := range recurrence.Cancelled {
+		if start, ok := moved(cancelled); ok {
+			shifted.Cancelled = append(shifted.Cancelled, start)
+		}
+	}
+	for _, override := range recurrence.Overrides {
+		start, ok := moved(override.Occurrence)
+		if !ok {
+			continue
+		}
+		delta := start.Sub(override.Occurrence)
+		override.Occurrence = start
+		override.StartTime = override.StartTime.Add(delta)
+		override.EndTime = override.EndTime.Add(delta)
+		if err := checkTripWindow(trip, models.Task{StartTime: override.StartTime, EndTime: override.EndTime}); err != nil {
+			return nil, fmt.Errorf("cant move the occurrence changed for %s: %v",
+				override.StartTime.In(loc).Format(models.TripDateFormat), err)
+		}
+		shifted.Overrides = append(shifted.Overrides, override)
+	}
+	return shifted, nil
+}
+
+func replaceTask(tasks []models.Task, replacement models.Task) []models.Task {
+	replaced := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.ID == replacement.ID {
+			task = replacement
+		}
+		replaced = append(replaced, task)
+	}
+	return replaced
+}
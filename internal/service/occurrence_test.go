@@ -0,0 +1,148 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSplitSeries(t *testing.T) {
+	trip := models.Trip{Timezone: "Europe/Rome"}
+	loc := trip.Location()
+	at := func(day int) time.Time { return time.Date(2030, time.January, day, 9, 0, 0, 0, loc) }
+	override := func(occurrence time.Time) models.OccurrenceOverride {
+		return models.OccurrenceOverride{Occurrence: occurrence, Title: "late", StartTime: occurrence.Add(time.Hour),
+			EndTime: occurrence.Add(2 * time.Hour), Duration: 60}
+	}
+	tests := []struct {
+		name          string
+		rule          string
+		occurrence    time.Time
+		wantEarlier   string
+		wantFollowing string
+	}{
+		{name: "count is divided", rule: "FREQ=DAILY;COUNT=5", occurrence: at(9),
+			wantEarlier: "FREQ=DAILY;UNTIL=20300108", wantFollowing: "FREQ=DAILY;COUNT=3"},
+		{name: "until is kept", rule: "FREQ=DAILY;UNTIL=20300111", occurrence: at(9),
+			wantEarlier: "FREQ=DAILY;UNTIL=20300108", wantFollowing: "FREQ=DAILY;UNTIL=20300111"},
+		{name: "by day", rule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", occurrence: at(14),
+			wantEarlier: "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20300113", wantFollowing: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := models.Task{
+				ID:        primitive.NewObjectID(),
+				Title:     "breakfast",
+				StartTime: at(7).UTC(),
+				EndTime:   at(7).Add(time.Hour).UTC(),
+				Duration:  60,
+				Checklist: []models.ChecklistItem{{ID: primitive.NewObjectID(), Title: "coffee"}},
+				Recurrence: &models.Recurrence{
+					Rule:      tt.rule,
+					Cancelled: []time.Time{at(8), at(16)},
+					Overrides: []models.OccurrenceOverride{override(at(7)), override(tt.occurrence), override(at(21))},
+				},
+			}
+			earlier, following, err := splitSeries(task, tt.occurrence, trip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if earlier.Recurrence.Rule != tt.wantEarlier {
+				t.Errorf("earlier rule = %s, want %s", earlier.Recurrence.Rule, tt.wantEarlier)
+			}
+			if following.Recurrence.Rule != tt.wantFollowing {
+				t.Errorf("following rule = %s, want %s", following.Recurrence.Rule, tt.wantFollowing)
+			}
+			for _, cancelled := range earlier.Recurrence.Cancelled {
+				if !cancelled.Before(tt.occurrence) {
+					t.Errorf("earlier part has cancelled %v", cancelled)
+				}
+			}
+			for _, cancelled := range following.Recurrence.Cancelled {
+				if cancelled.Before(tt.occurrence) {
+					t.Errorf("following part has cancelled %v", cancelled)
+				}
+			}
+			if len(earlier.Recurrence.Cancelled)+len(following.Recurrence.Cancelled) != 2 {
+				t.Errorf("cancelled occurrences are lost: %v, %v", earlier.Recurrence.Cancelled, following.Recurrence.Cancelled)
+			}
+			if slices.ContainsFunc(following.Recurrence.Overrides, func(o models.OccurrenceOverride) bool {
+				return o.Occurrence.Equal(tt.occurrence)
+			}) {
+				t.Error("the changed occurrence keeps its old edit")
+			}
+			if len(following.Checklist) != 1 || following.Checklist[0].Title != "coffee" {
+				t.Fatalf("following checklist = %v", following.Checklist)
+			}
+			if following.Checklist[0].ID == task.Checklist[0].ID {
+				t.Error("following checklist shares item ids with the earlier part")
+			}
+			if earlier.Checklist[0].ID != task.Checklist[0].ID {
+				t.Error("earlier checklist items got new ids")
+			}
+		})
+	}
+}
+
+func TestShiftRecurrence(t *testing.T) {
+	trip := models.Trip{Timezone: "Europe/Rome"}
+	loc := trip.Location()
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2030, month, day, hour, 0, 0, 0, loc)
+	}
+	tests := []struct {
+		name          string
+		rule          string
+		from, to      time.Time
+		cancelled     time.Time
+		overridden    time.Time
+		wantCancelled []time.Time
+		wantOverride  []time.Time
+	}{
+		{name: "later on the same day", rule: "FREQ=DAILY;COUNT=5",
+			from: at(time.January, 7, 9), to: at(time.January, 7, 11),
+			cancelled: at(time.January, 8, 9), overridden: at(time.January, 9, 9),
+			wantCancelled: []time.Time{at(time.January, 8, 11)}, wantOverride: []time.Time{at(time.January, 9, 11)}},
+		{name: "local time across DST", rule: "FREQ=DAILY;COUNT=5",
+			from: at(time.March, 29, 9), to: at(time.March, 30, 10),
+			cancelled: at(time.March, 30, 9), overridden: at(time.April, 1, 9),
+			wantCancelled: []time.Time{at(time.March, 31, 10)}, wantOverride: []time.Time{at(time.April, 2, 10)}},
+		{name: "by day keeps the n-th occurrence", rule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			from: at(time.January, 7, 9), to: at(time.January, 9, 9),
+			cancelled: at(time.January, 9, 9), overridden: at(time.January, 14, 9),
+			wantCancelled: []time.Time{at(time.January, 14, 9)}, wantOverride: []time.Time{at(time.January, 16, 9)}},
+		{name: "unknown occurrences are dropped", rule: "FREQ=DAILY;COUNT=5",
+			from: at(time.January, 7, 9), to: at(time.January, 8, 9),
+			cancelled: at(time.January, 7, 10), overridden: at(time.January, 20, 9)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence := &models.Recurrence{
+				Rule:      tt.rule,
+				Cancelled: []time.Time{tt.cancelled},
+				Overrides: []models.OccurrenceOverride{{Occurrence: tt.overridden, Title: "late",
+					StartTime: tt.overridden.Add(time.Hour), EndTime: tt.overridden.Add(2 * time.Hour), Duration: 60}},
+			}
+			shifted, err := shiftRecurrence(recurrence, tt.rule, tt.from, tt.to, trip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(shifted.Cancelled, tt.wantCancelled, time.Time.Equal) {
+				t.Errorf("cancelled = %v, want %v", shifted.Cancelled, tt.wantCancelled)
+			}
+			var overridden []time.Time
+			for _, override := range shifted.Overrides {
+				overridden = append(overridden, override.Occurrence)
+				if !override.StartTime.Equal(override.Occurrence.Add(time.Hour)) {
+					t.Errorf("override starts at %v, want an hour after %v", override.StartTime, override.Occurrence)
+				}
+			}
+			if !slices.EqualFunc(overridden, tt.wantOverride, time.Time.Equal) {
+				t.Errorf("overrides = %v, want %v", overridden, tt.wantOverride)
+			}
+		})
+	}
+}
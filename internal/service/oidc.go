@@ -0,0 +1,469 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	oidcStateTTL        = 10 * time.Minute
+	oidcRegistrationTTL = 15 * time.Minute
+	oidcHTTPTimeout     = 10 * time.Second
+	oidcRandomBytes     = 32
+)
+
+type OIDCRepository interface {
+	SaveState(ctx context.Context, state models.OIDCState) error
+	PopState(ctx context.Context, state string) (*models.OIDCState, error)
+	SaveRegistration(ctx context.Context, reg models.OIDCRegistration) error
+	GetRegistration(ctx context.Context, ticket string) (*models.OIDCRegistration, error)
+	PopRegistration(ctx context.Context, ticket string) (*models.OIDCRegistration, error)
+}
+
+type TokenGenerator interface {
+	GeneratePasetoToken(user *models.User) (string, error)
+}
+
+// OIDCProvider talks to any OpenID Connect provider that publishes a discovery
+// document, so a local mock server works the same way as a real one.
+type OIDCProvider struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcJWKS struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+func NewOIDCProviderFromEnv() *OIDCProvider {
+	scopes := strings.Fields(os.Getenv("OIDC_SCOPES"))
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDCProvider{
+		IssuerURL:    strings.TrimSuffix(os.Getenv("OIDC_ISSUER_URL"), "/"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       scopes,
+		HTTPClient:   &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+func (p *OIDCProvider) IsConfigured() bool {
+	return p != nil && p.IssuerURL != "" && p.ClientID != "" && p.RedirectURL != ""
+}
+
+func (p *OIDCProvider) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	var discovery oidcDiscovery
+	err := p.getJSON(ctx, p.IssuerURL+"/.well-known/openid-configuration", &discovery)
+	if err != nil {
+		return nil, fmt.Errorf("discovery error: %v", err)
+	}
+	if discovery.Issuer != p.IssuerURL {
+		return nil, fmt.Errorf("discovery issuer mismatch: %v", discovery.Issuer)
+	}
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, endpoint string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("build request: %v", err)
+	}
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %v: %v", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request %v: unexpected status %v", endpoint, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("decode %v: %v", endpoint, err)
+	}
+	return nil
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.ClientID)
+	params.Set("redirect_uri", p.RedirectURL)
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("build token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request: unexpected status %v", resp.StatusCode)
+	}
+	var tokenResp struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("decode token response: %v", err)
+	}
+	if tokenResp.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+	return tokenResp.IDToken, nil
+}
+
+func (p *OIDCProvider) publicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	// the key is unknown, the provider may have rotated them
+	var jwks oidcJWKS
+	if err := p.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("jwks error: %v", err)
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	key, ok = keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %v", kid)
+	}
+	return key, nil
+}
+
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawToken, nonce string) (*models.OIDCIdentity, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("VerifyIDToken error: %v", err)
+	}
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, errors.New("id token has wrong issuer")
+	}
+	if !audienceContains(claims["aud"], p.ClientID) {
+		return nil, errors.New("id token has wrong audience")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("id token has wrong nonce")
+	}
+	identity := &models.OIDCIdentity{Issuer: discovery.Issuer}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+	if identity.Subject == "" {
+		return nil, errors.New("id token has no subject")
+	}
+	return identity, nil
+}
+
+func audienceContains(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type OIDCSrv struct {
+	User     UserRepository
+	OIDC     OIDCRepository
+	Provider *OIDCProvider
+	Tokens   TokenGenerator
+}
+
+func NewOIDCSrv(userRepo UserRepository, oidcRepo OIDCRepository,
+	provider *OIDCProvider, tokens TokenGenerator) *OIDCSrv {
+	return &OIDCSrv{User: userRepo, OIDC: oidcRepo, Provider: provider, Tokens: tokens}
+}
+
+func (s *OIDCSrv) BeginLogin(ctx context.Context) (string, error) {
+	if !s.Provider.IsConfigured() {
+		return "", errors.New("OIDC login is not configured")
+	}
+	state, err := randomURLString()
+	if err != nil {
+		logs.Error(err)
+		return "", errors.New("unfortunately we were unable to process your request, please try again later")
+	}
+	nonce, err := randomURLString()
+	if err != nil {
+		logs.Error(err)
+		return "", errors.New("unfortunately we were unable to process your request, please try again later")
+	}
+	verifier, err := randomURLString()
+	if err != nil {
+		logs.Error(err)
+		return "", errors.New("unfortunately we were unable to process your request, please try again later")
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL, err := s.Provider.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		logs.Error(err)
+		return "", errors.New("identity provider is unavailable")
+	}
+	err = s.OIDC.SaveState(ctx, models.OIDCState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().UTC().Add(oidcStateTTL),
+	})
+	if err != nil {
+		logs.Error(err)
+		return "", errors.New("System error")
+	}
+	return authURL, nil
+}
+
+func (s *OIDCSrv) CompleteLogin(ctx context.Context, code, state string) (*models.OIDCLoginResult, error) {
+	if !s.Provider.IsConfigured() {
+		return nil, errors.New("OIDC login is not configured")
+	}
+	oidcState, err := s.OIDC.PopState(ctx, state)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("login session is expired or invalid")
+	}
+	rawToken, err := s.Provider.Exchange(ctx, code, oidcState.CodeVerifier)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to exchange authorization code")
+	}
+	identity, err := s.Provider.VerifyIDToken(ctx, rawToken, oidcState.Nonce)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("invalid identity token")
+	}
+
+	user, err := s.User.GetUserByOIDC(ctx, identity.Issuer, identity.Subject)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	if user == nil {
+		if identity.Email == "" || !identity.EmailVerified {
+			return nil, errors.New("your email is not verified by the identity provider")
+		}
+		user, err = s.User.GetUserByEmail(ctx, identity.Email)
+		if err != nil {
+			logs.Error(err)
+			return nil, errors.New("System error")
+		}
+		if user == nil {
+			return s.startRegistration(ctx, identity)
+		}
+		// anyone can sign up with an address they do not own, only a confirmed one proves the account is the same person
+		if !user.EmailVerified {
+			return nil, errors.New("an account with this email exists but its email is not confirmed, " +
+				"sign in with your password and confirm the email first")
+		}
+		err = s.User.LinkOIDCIdentity(ctx, user.Login, identity.Issuer, identity.Subject)
+		if err != nil {
+			logs.Error(err)
+			return nil, errors.New("System error")
+		}
+	}
+	token, err := s.Tokens.GeneratePasetoToken(user)
+	if err != nil {
+		logs.Error(err)
+		return nil, fmt.Errorf("error during generating token: %v", err)
+	}
+	return &models.OIDCLoginResult{Token: token}, nil
+}
+
+func (s *OIDCSrv) startRegistration(ctx context.Context, identity *models.OIDCIdentity) (*models.OIDCLoginResult, error) {
+	ticket, err := randomURLString()
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("unfortunately we were unable to process your request, please try again later")
+	}
+	err = s.OIDC.SaveRegistration(ctx, models.OIDCRegistration{
+		Ticket:    ticket,
+		Issuer:    identity.Issuer,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		ExpiresAt: time.Now().UTC().Add(oidcRegistrationTTL),
+	})
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return &models.OIDCLoginResult{RegistrationTicket: ticket, Email: identity.Email}, nil
+}
+
+func (s *OIDCSrv) RegisterUser(ctx context.Context, signUp models.OIDCSignUp) (string, error) {
+	// the ticket is used up only after the checks, so a taken login can be replaced without a new sign in
+	reg, err := s.OIDC.GetRegistration(ctx, signUp.Ticket)
+	if err != nil {
+		logs.Error(err)
+		return "", errors.New("registration ticket is expired or invalid")
+	}
+	if _, err := s.User.GetUserByLogin(ctx, signUp.Login); err == nil {
+		return "", errors.New("this login is already registered")
+	}
+	existing, err := s.User.GetUserByEmail(ctx, reg.Email)
+	if err != nil {
+		logs.Error(err)
+		return "", errors.New("System error")
+	}
+	if existing != nil {
+		return "", errors.New("this email is already registered")
+	}
+	if err := checkLoginReserved(ctx, s.User, signUp.Login); err != nil {
+		return "", err
+	}
+	reg, err = s.OIDC.PopRegistration(ctx, signUp.Ticket)
+	if err != nil {
+		logs.Error(err)
+		return "", errors.New("registration ticket is expired or invalid")
+	}
+	newUser := models.User{
+		ID:            primitive.NewObjectID(),
+		Login:         signUp.Login,
+		Email:         reg.Email,
+		OIDCIssuer:    reg.Issuer,
+		OIDCSubject:   reg.Subject,
+		EmailVerified: true,
+	}
+	err = s.User.CreateUser(ctx, newUser)
+	if err != nil {
+		logs.Error(err)
+		return "", fmt.Errorf("error during creating user:%v", err)
+	}
+	token, err := s.Tokens.GeneratePasetoToken(&newUser)
+	if err != nil {
+		logs.Error(err)
+		return "", fmt.Errorf("error during generating token: %v", err)
+	}
+	return token, nil
+}
+
+func randomURLString() (string, error) {
+	buf := make([]byte, oidcRandomBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("random string error: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
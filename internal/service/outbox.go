@@ -0,0 +1,136 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	outboxRelayLease = "outbox_relay"
+	// the relay checks the outbox every tick and right after a commit on its replica
+	outboxRelayLeaseTTL = 15 * time.Second
+	outboxRelayTick     = time.Second
+	outboxBatch         = 100
+	// dispatched events are kept for a while to look into what happened
+	outboxRetention = 24 * time.Hour
+)
+
+// Transactor runs fn in a transaction, everything fn saves with the given ctx is committed or rolled back together
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// inTransaction returns the errors of fn as they are, a failed commit is a system error
+func inTransaction(ctx context.Context, tx Transactor, fn func(ctx context.Context) error) error {
+	var fnErr error
+	err := tx.WithTransaction(ctx, func(ctx context.Context) error {
+		fnErr = fn(ctx)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+type OutboxRepository interface {
+	GetPendingEvents(ctx context.Context, limit int64) ([]models.OutboxEvent, error)
+	MarkDispatched(ctx context.Context, eventID primitive.ObjectID, at time.Time, eventErr string) error
+	PurgeDispatched(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OutboxRelay dispatches the committed events of the outbox to the subscribers of the bus.
+// One replica holding the lease relays, an event is sent again if the relay dies before marking it
+type OutboxRelay struct {
+	Outbox   OutboxRepository
+	Lease    LeaseRepository
+	Bus      *EventBus
+	Instance string
+
+	wake chan struct{}
+}
+
+func NewOutboxRelay(outboxRepo OutboxRepository, leaseRepo LeaseRepository, bus *EventBus) *OutboxRelay {
+	return &OutboxRelay{Outbox: outboxRepo, Lease: leaseRepo, Bus: bus, Instance: newInstanceID(),
+		wake: make(chan struct{}, 1)}
+}
+
+// Wake makes the relay check the outbox without waiting for the next tick
+func (r *OutboxRelay) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxRelayTick)
+	defer ticker.Stop()
+	defer func() {
+		if err := r.Lease.ReleaseLease(context.Background(), outboxRelayLease, r.Instance); err != nil {
+			logs.Error(err)
+		}
+	}()
+	for {
+		r.relay(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// relay renews the lease before every batch, so a long backlog is not relayed by two replicas
+// once the lease of this one expired
+func (r *OutboxRelay) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		leader, err := r.Lease.AcquireLease(ctx, outboxRelayLease, r.Instance, outboxRelayLeaseTTL)
+		if err != nil {
+			logs.Error(err)
+			return
+		}
+		if !leader {
+			return
+		}
+		events, err := r.Outbox.GetPendingEvents(ctx, outboxBatch)
+		if err != nil {
+			logs.Error(err)
+			return
+		}
+		for _, outboxEvent := range events {
+			r.dispatch(ctx, outboxEvent)
+		}
+		if len(events) < outboxBatch {
+			return
+		}
+	}
+}
+
+func (r *OutboxRelay) dispatch(ctx context.Context, outboxEvent models.OutboxEvent) {
+	eventErr := ""
+	event, err := models.DecodeEvent(outboxEvent.Name, outboxEvent.Payload)
+	if err != nil {
+		logs.Error(err)
+		eventErr = err.Error()
+	} else {
+		r.Bus.Publish(withEvent(ctx, outboxEvent), event)
+	}
+	if err := r.Outbox.MarkDispatched(ctx, outboxEvent.ID, time.Now().UTC(), eventErr); err != nil {
+		logs.Error(err)
+	}
+}
+
+func (r *OutboxRelay) PurgeDispatched(ctx context.Context) error {
+	_, err := r.Outbox.PurgeDispatched(ctx, time.Now().UTC().Add(-outboxRetention))
+	return err
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"JourneyPlanner/internal/models"
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -11,15 +13,27 @@ import (
 var pasetoInstance = paseto.NewV2()
 
 type TokenPayload struct {
+	TokenID    string    `json:"token_id"`
+	UserID     string    `json:"user_id"`
 	UserLogin  string    `json:"user_login"`
+	IssuedAt   time.Time `json:"issued_at"`
 	Expiration time.Time `json:"expiration"`
 }
 
-func (s *UserSrv) GeneratePasetoToken(userLogin string) (string, error) {
+func (s *UserSrv) GeneratePasetoToken(user *models.User) (string, error) {
 	symmetricKey := []byte(os.Getenv("SYMMETRIC_KEY"))
+	tokenID, err := randomURLString()
+	if err != nil {
+		logs.Error(err)
+		return "", fmt.Errorf("GeneratePasetoToken error: %v", err)
+	}
+	now := time.Now().UTC()
 	payload := TokenPayload{
-		UserLogin:  userLogin,
-		Expiration: time.Now().Add(HoursInDay * time.Hour),
+		TokenID:    tokenID,
+		UserID:     user.ID.Hex(),
+		UserLogin:  user.Login,
+		IssuedAt:   now,
+		Expiration: now.Add(HoursInDay * time.Hour),
 	}
 
 	encrypted, err := pasetoInstance.Encrypt(symmetricKey, payload, nil)
@@ -30,7 +44,7 @@ func (s *UserSrv) GeneratePasetoToken(userLogin string) (string, error) {
 	return encrypted, nil
 }
 
-func (s *UserSrv) ValidatePasetoToken(tokenString string) (*TokenPayload, error) {
+func (s *UserSrv) decryptPasetoToken(tokenString string) (*TokenPayload, error) {
 	symmetricKey := []byte(os.Getenv("SYMMETRIC_KEY"))
 	var payload TokenPayload
 	var footer string
@@ -45,3 +59,68 @@ func (s *UserSrv) ValidatePasetoToken(tokenString string) (*TokenPayload, error)
 	}
 	return &payload, nil
 }
+
+func (s *UserSrv) ValidatePasetoToken(ctx context.Context, tokenString string) (*TokenPayload, error) {
+	payload, err := s.decryptPasetoToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := s.Token.IsTokenRevoked(ctx, payload.TokenID)
+	if err != nil {
+		logs.Error(err)
+		return nil, fmt.Errorf("validatePasetoToken error: %v", err)
+	}
+	if revoked {
+		return nil, fmt.Errorf("token revoked")
+	}
+	user, err := s.User.GetUserByLogin(ctx, payload.UserLogin)
+	if err != nil {
+		return nil, fmt.Errorf("validatePasetoToken error: %v", err)
+	}
+	// tokens are bound to the account they were issued for, not only to its login
+	if payload.UserID != user.ID.Hex() || payload.IssuedAt.Before(user.SessionsRevokedAt) {
+		return nil, fmt.Errorf("token revoked")
+	}
+	return payload, nil
+}
+
+func (s *UserSrv) Logout(ctx context.Context, tokenString string) error {
+	payload, err := s.decryptPasetoToken(tokenString)
+	if err != nil {
+		return fmt.Errorf("invalid token")
+	}
+	err = s.Token.RevokeToken(ctx, models.RevokedToken{
+		TokenID:   payload.TokenID,
+		ExpiresAt: payload.Expiration,
+	})
+	if err != nil {
+		logs.Error(err)
+		return fmt.Errorf("System error")
+	}
+	return nil
+}
+
+// PurgeRevokedTokens forgets revoked tokens that are expired, they are rejected anyway
+func (s *UserSrv) PurgeRevokedTokens(ctx context.Context) error {
+	deleted, err := s.Token.PurgeRevokedTokens(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	logs.Infof("purged %d revoked tokens", deleted)
+	return nil
+}
+
+// LogoutEverywhere revokes all sessions and deletes the API keys, after a leak no credential of the account stays valid
+func (s *UserSrv) LogoutEverywhere(ctx context.Context, userLogin string) error {
+	err := s.User.RevokeSessions(ctx, userLogin, time.Now().UTC())
+	if err != nil {
+		logs.Error(err)
+		return fmt.Errorf("System error")
+	}
+	err = s.APIKey.DeleteUserAPIKeys(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return fmt.Errorf("System error")
+	}
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"JourneyPlanner/internal/models"
 	"context"
 	"errors"
+	"fmt"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type PollRepository interface {
@@ -15,15 +18,37 @@ type PollRepository interface {
 	ClosePoll(ctx context.Context, pollID string) error
 	RemoveVote(ctx context.Context, pollID, userLogin string) error
 	AddVote(ctx context.Context, pollID, voteOption, userLogin string) error
+	GetPollsToFinalize(ctx context.Context, staleBefore time.Time) ([]models.Poll, error)
+	ClaimPollResult(ctx context.Context, pollID string, staleBefore time.Time) (bool, error)
+	SetPollResult(ctx context.Context, pollID string, result models.PollResult) error
+}
+
+// TaskProposer sends a task for review when the poll creator cant create it directly
+type TaskProposer interface {
+	ProposeTask(ctx context.Context, userLogin string, taskInfo models.CreateTask, comment string) error
+}
+
+// GroupSettings changes the group on behalf of a member, with all the usual permission checks
+type GroupSettings interface {
+	UpdateTrip(ctx context.Context, groupID, userLogin string, update models.UpdateTrip) error
+	GiveLeaderRole(ctx context.Context, groupID, userLogin, memberLogin string) error
 }
 
 type PollSrv struct {
-	Poll  PollRepository
-	Group GroupRepository
+	Poll      PollRepository
+	Group     GroupRepository
+	Tasks     TaskCreator
+	Proposals TaskProposer
+	Settings  GroupSettings
+	Jobs      JobQueue
+	Events    EventPublisher
+	Tx        Transactor
 }
 
-func NewPollSrv(pollRepo PollRepository, groupRepo GroupRepository) *PollSrv {
-	return &PollSrv{Poll: pollRepo, Group: groupRepo}
+func NewPollSrv(pollRepo PollRepository, groupRepo GroupRepository, tasks TaskCreator, proposals TaskProposer,
+	settings GroupSettings, jobs JobQueue, events EventPublisher, tx Transactor) *PollSrv {
+	return &PollSrv{Poll: pollRepo, Group: groupRepo, Tasks: tasks, Proposals: proposals, Settings: settings,
+		Jobs: jobs, Events: events, Tx: tx}
 }
 
 func (s *PollSrv) CreatePoll(ctx context.Context, pollInfo models.CreatePoll, userLogin string) error {
@@ -36,10 +61,20 @@ func (s *PollSrv) CreatePoll(ctx context.Context, pollInfo models.CreatePoll, us
 		return errors.New("group is not found, or you are not a member of it")
 	}
 
+	firstAction, err := checkPollAction(group, userLogin, pollInfo.FirstAction)
+	if err != nil {
+		return fmt.Errorf("first option: %v", err)
+	}
+	secondAction, err := checkPollAction(group, userLogin, pollInfo.SecondAction)
+	if err != nil {
+		return fmt.Errorf("second option: %v", err)
+	}
+
 	now := time.Now().UTC()
 	votingEndTime := now.Add(time.Duration(pollInfo.Duration) * time.Minute)
 
 	newPoll := models.Poll{
+		ID:            primitive.NewObjectID(),
 		Creator:       userLogin,
 		Title:         pollInfo.Title,
 		FirstOption:   pollInfo.FirstOption,
@@ -48,11 +83,26 @@ func (s *PollSrv) CreatePoll(ctx context.Context, pollInfo models.CreatePoll, us
 		Votes2:        []string{},
 		EndTime:       votingEndTime,
 		IsEarlyClosed: false,
+		FirstAction:   firstAction,
+		SecondAction:  secondAction,
+	}
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Poll.CreatePoll(ctx, newPoll, pollInfo.GroupID)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return publishEvent(ctx, s.Events, models.PollCreated{Group: *group, Actor: userLogin, Poll: newPoll})
+	})
+	if err != nil {
+		return err
 	}
-	err = s.Poll.CreatePoll(ctx, newPoll, pollInfo.GroupID)
+	// results are announced and actions are run by the job, if scheduling fails
+	// the periodic check still finds the polls with actions
+	err = s.Jobs.Schedule(ctx, models.JobFinalizePoll, finalizePollJobKey(newPoll.ID.Hex()), votingEndTime,
+		map[string]string{"poll_id": newPoll.ID.Hex()})
 	if err != nil {
 		logs.Error(err)
-		return errors.New("System error")
 	}
 	return nil
 }
@@ -89,6 +139,9 @@ func (s *PollSrv) preparePollList(openPolls, closedPolls []models.Poll) models.P
 			SecondOption:     poll.SecondOption,
 			SecondVotesCount: len(poll.Votes2),
 			EndTime:          poll.EndTime.Format("2006-01-02 15:04:05"),
+			FirstAction:      poll.FirstAction,
+			SecondAction:     poll.SecondAction,
+			Result:           poll.Result,
 		}
 		pollList.OpenPolls = append(pollList.OpenPolls, printPoll)
 	}
@@ -102,12 +155,29 @@ func (s *PollSrv) preparePollList(openPolls, closedPolls []models.Poll) models.P
 			SecondOption:     poll.SecondOption,
 			SecondVotesCount: len(poll.Votes2),
 			EndTime:          poll.EndTime.Format("2006-01-02 15:04:05"),
+			FirstAction:      poll.FirstAction,
+			SecondAction:     poll.SecondAction,
+			Result:           poll.Result,
 		}
 		pollList.ClosedPolls = append(pollList.ClosedPolls, printPoll)
 	}
 	return pollList
 }
 
+// getGroupPoll returns the poll only if it belongs to the group the user was checked in,
+// so a poll of another group can't be closed, deleted or voted in
+func (s *PollSrv) getGroupPoll(ctx context.Context, pollID, groupID string) (*models.Poll, error) {
+	poll, err := s.Poll.GetPollById(ctx, pollID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("poll is not found")
+	}
+	if poll.GroupID.Hex() != groupID {
+		return nil, errors.New("poll is not found")
+	}
+	return poll, nil
+}
+
 func (s *PollSrv) DeletePollByID(ctx context.Context, pollID, groupID, userLogin string) error {
 	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
 	if err != nil {
@@ -118,49 +188,71 @@ func (s *PollSrv) DeletePollByID(ctx context.Context, pollID, groupID, userLogin
 		return errors.New("group is not found, or you are not a member of it")
 	}
 	
-	poll, err := s.Poll.GetPollById(ctx, pollID)
+	poll, err := s.getGroupPoll(ctx, pollID, groupID)
 	if err != nil {
-		logs.Error(err)
-		return errors.New("poll is not found")
+		return err
 	}
 	if group.LeaderLogin != userLogin && poll.Creator != userLogin {
 		return errors.New("you have no permissions to do this")
 	}
-	err = s.Poll.DeletePoll(ctx, pollID)
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Poll.DeletePoll(ctx, pollID)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return publishEvent(ctx, s.Events, models.PollDeleted{Group: *group, Actor: userLogin, Poll: *poll})
+	})
 	if err != nil {
+		return err
+	}
+	if err := s.Jobs.Cancel(ctx, finalizePollJobKey(pollID)); err != nil {
 		logs.Error(err)
-		return errors.New("System error")
 	}
 	return nil
 }
 
-func (s *PollSrv) ClosePoll(ctx context.Context, pollID, groupID, userLogin string) error {
+// ClosePoll closes the poll, announces the result and runs the action of the winning option, if there is one
+func (s *PollSrv) ClosePoll(ctx context.Context, pollID, groupID, userLogin string) (*models.PollResult, error) {
 	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
 	if err != nil {
 		logs.Error(err)
-		return errors.New("failed to find group")
+		return nil, errors.New("failed to find group")
 	}
 	if group == nil{
-		return errors.New("group is not found, or you are not a member of it")
+		return nil, errors.New("group is not found, or you are not a member of it")
 	}
-	poll, err := s.Poll.GetPollById(ctx, pollID)
+	poll, err := s.getGroupPoll(ctx, pollID, groupID)
 	if err != nil {
-		logs.Error(err)
-		return errors.New("poll is not found")
+		return nil, err
 	}
 	now := time.Now().UTC()
 	if poll.IsEarlyClosed || poll.EndTime.Before(now) {
-		return errors.New("poll is already closed")
+		return nil, errors.New("poll is already closed")
 	}
 	if group.LeaderLogin != userLogin && poll.Creator != userLogin {
-		return errors.New("you have no permissions to do this")
+		return nil, errors.New("you have no permissions to do this")
 	}
 	err = s.Poll.ClosePoll(ctx, pollID)
 	if err != nil {
 		logs.Error(err)
-		return errors.New("System error")
+		return nil, errors.New("System error")
 	}
-	return nil
+	if err := s.Jobs.Cancel(ctx, finalizePollJobKey(pollID)); err != nil {
+		logs.Error(err)
+	}
+	// votes could have changed since the poll was read, the winner is taken from the closed poll
+	poll, err = s.Poll.GetPollById(ctx, pollID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	result, err := s.finalizePoll(ctx, *poll, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("poll is closed, but its action failed to run")
+	}
+	return result, nil
 }
 
 func (s *PollSrv) VotePoll(ctx context.Context, userLogin string, vote models.AddVote) error {
@@ -172,10 +264,9 @@ func (s *PollSrv) VotePoll(ctx context.Context, userLogin string, vote models.Ad
 	if group == nil{
 		return errors.New("group is not found, or you are not a member of it")
 	}
-	poll, err := s.Poll.GetPollById(ctx, vote.PollID)
+	poll, err := s.getGroupPoll(ctx, vote.PollID, vote.GroupID)
 	if err != nil {
-		logs.Error(err)
-		return errors.New("poll is not found")
+		return err
 	}
 	now := time.Now().UTC()
 	if poll.IsEarlyClosed || poll.EndTime.Before(now) {
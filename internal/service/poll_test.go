@@ -0,0 +1,50 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func TestPollOfAnotherGroup(t *testing.T) {
+	SetLogger(zap.NewNop())
+	ours := &models.Group{ID: primitive.NewObjectID(), LeaderLogin: "alice123", Members: []string{"alice123"}}
+	theirs := &models.Group{ID: primitive.NewObjectID(), LeaderLogin: "bob123", Members: []string{"bob123"}}
+	poll := models.Poll{ID: primitive.NewObjectID(), GroupID: theirs.ID, Creator: "bob123", Title: "dinner",
+		FirstOption: "pizza", SecondOption: "sushi", EndTime: time.Now().UTC().Add(time.Hour)}
+	tests := []struct {
+		name string
+		call func(s *PollSrv) error
+	}{
+		{name: "close", call: func(s *PollSrv) error {
+			_, err := s.ClosePoll(context.Background(), poll.ID.Hex(), ours.ID.Hex(), "alice123")
+			return err
+		}},
+		{name: "delete", call: func(s *PollSrv) error {
+			return s.DeletePollByID(context.Background(), poll.ID.Hex(), ours.ID.Hex(), "alice123")
+		}},
+		{name: "vote", call: func(s *PollSrv) error {
+			return s.VotePoll(context.Background(), "alice123",
+				models.AddVote{GroupID: ours.ID.Hex(), PollID: poll.ID.Hex(), Option: "pizza"})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls := &fakePolls{polls: map[string]models.Poll{poll.ID.Hex(): poll}}
+			events := &fakeEvents{}
+			groups := &fakeGroups{groups: map[string]*models.Group{ours.ID.Hex(): ours, theirs.ID.Hex(): theirs}}
+			s := NewPollSrv(polls, groups, nil, nil, nil, nil, events, fakeTx{})
+			err := tt.call(s)
+			if err == nil || err.Error() != "poll is not found" {
+				t.Errorf("error = %v, want poll is not found", err)
+			}
+			if len(polls.changed) != 0 || len(events.events) != 0 {
+				t.Errorf("the poll of another group was changed: %v, events %v", polls.changed, events.events)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// checkPollAction validates the action when the poll is created, permissions are checked again when it runs
+func checkPollAction(group *models.Group, userLogin string, action *models.PollAction) (*models.PollAction, error) {
+	if action == nil {
+		return nil, nil
+	}
+	checked := &models.PollAction{Kind: action.Kind}
+	switch action.Kind {
+	case models.PollActionTask:
+		if action.Task == nil || action.Task.IsEmpty() {
+			return nil, errors.New("task action needs title, start and duration of the task")
+		}
+		task := *action.Task
+		task.GroupID = group.ID.Hex()
+		task.Cascade = false
+		if err := validateCoordinates(task.Location); err != nil {
+			return nil, err
+		}
+		checked.Task = &task
+	case models.PollActionTrip:
+		if action.Trip == nil || action.Trip.IsEmpty() {
+			return nil, errors.New("trip action needs at least one trip field")
+		}
+		if group.LeaderLogin != userLogin {
+			return nil, errors.New("only leader can change trip details with a poll")
+		}
+		checked.Trip = action.Trip
+	case models.PollActionLeader:
+		if !slices.Contains(group.Members, action.Leader) {
+			return nil, errors.New("nominated leader is not a member of the group")
+		}
+		if group.LeaderLogin != userLogin {
+			return nil, errors.New("only leader can give the leader role with a poll")
+		}
+		if action.Leader == userLogin {
+			return nil, errors.New("you are the leader already")
+		}
+		checked.Leader = action.Leader
+	default:
+		return nil, fmt.Errorf("unknown poll action %q", action.Kind)
+	}
+	return checked, nil
+}
+
+// pollWinner returns empty winner when votes are tied
+func pollWinner(poll models.Poll) (string, *models.PollAction) {
+	switch {
+	case len(poll.Votes1) > len(poll.Votes2):
+		return poll.FirstOption, poll.FirstAction
+	case len(poll.Votes2) > len(poll.Votes1):
+		return poll.SecondOption, poll.SecondAction
+	}
+	return "", nil
+}
+
+// a poll action that is still running after this time is taken as crashed and run again
+const pollRunTimeout = 5 * time.Minute
+
+// finalizePoll runs the action once, whoever comes first: ClosePoll or the finalizer of expired polls.
+// closedBy is who closed the poll early, it is empty when the time of the poll is over
+func (s *PollSrv) finalizePoll(ctx context.Context, poll models.Poll, closedBy string) (*models.PollResult, error) {
+	claimed, err := s.Poll.ClaimPollResult(ctx, poll.ID.Hex(), time.Now().UTC().Add(-pollRunTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, nil
+	}
+	result := s.runPollAction(ctx, poll)
+	result.FinishedAt = time.Now().UTC()
+	err = s.Tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.Poll.SetPollResult(ctx, poll.ID.Hex(), result); err != nil {
+			return err
+		}
+		return s.Events.Publish(ctx, models.PollClosed{Poll: poll, Result: result, ClosedBy: closedBy})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// runPollAction acts on behalf of the poll creator, so a poll never gives more permissions than its creator has.
+// A task chosen in a poll of a member is sent to the review queue, as the member would do it
+func (s *PollSrv) runPollAction(ctx context.Context, poll models.Poll) models.PollResult {
+	winner, action := pollWinner(poll)
+	result := models.PollResult{Winner: winner, Status: models.PollResultDone}
+	switch {
+	case winner == "":
+		result.Status, result.Message = models.PollResultSkipped, "votes are tied, nothing is done"
+		return result
+	case action == nil:
+		result.Status, result.Message = models.PollResultSkipped, "winning option has no action"
+		return result
+	}
+	groupID := poll.GroupID.Hex()
+	var err error
+	switch action.Kind {
+	case models.PollActionTask:
+		var group *models.Group
+		group, err = s.Group.GetGroup(ctx, groupID, poll.Creator)
+		switch {
+		case err != nil:
+			logs.Error(err)
+			err = errors.New("failed to find group")
+		case group == nil:
+			err = errors.New("poll creator is not a member of the group anymore")
+		case group.LeaderLogin == poll.Creator:
+			err = s.Tasks.CreateTask(ctx, *action.Task, poll.Creator)
+			result.Message = "task is created"
+		default:
+			err = s.Proposals.ProposeTask(ctx, poll.Creator, *action.Task, fmt.Sprintf("chosen in poll %q", poll.Title))
+			result.Status, result.Message = models.PollResultProposed, "task is sent to the leader for review"
+		}
+	case models.PollActionTrip:
+		err = s.Settings.UpdateTrip(ctx, groupID, poll.Creator, *action.Trip)
+		result.Message = "trip details are changed"
+	case models.PollActionLeader:
+		err = s.Settings.GiveLeaderRole(ctx, groupID, poll.Creator, action.Leader)
+		result.Message = action.Leader + " is the new leader"
+	}
+	if err != nil {
+		result.Status, result.Message = models.PollResultFailed, err.Error()
+	}
+	return result
+}
+
+func finalizePollJobKey(pollID string) string {
+	return models.JobFinalizePoll + ":" + pollID
+}
+
+// FinalizePollJob runs the action of one poll when its time is over
+func (s *PollSrv) FinalizePollJob(ctx context.Context, job models.Job) error {
+	poll, err := s.Poll.GetPollById(ctx, job.Payload["poll_id"])
+	if err != nil {
+		return err
+	}
+	if poll.Result != nil && poll.Result.Status != models.PollResultRunning {
+		return nil
+	}
+	_, err = s.finalizePoll(ctx, *poll, "")
+	return err
+}
+
+// FinalizePolls runs actions of all polls that closed by time and were missed, it is a periodic job
+func (s *PollSrv) FinalizePolls(ctx context.Context) error {
+	polls, err := s.Poll.GetPollsToFinalize(ctx, time.Now().UTC().Add(-pollRunTimeout))
+	if err != nil {
+		return err
+	}
+	for _, poll := range polls {
+		if _, err := s.finalizePoll(ctx, poll, ""); err != nil {
+			logs.Error(err)
+		}
+	}
+	return nil
+}
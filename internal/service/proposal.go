@@ -0,0 +1,286 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	maxPendingProposals = 20
+	maxProposalText     = 500
+)
+
+type ProposalRepository interface {
+	AddProposal(ctx context.Context, proposal models.TaskProposal) error
+	GetProposals(ctx context.Context, filter models.ProposalFilter) ([]models.TaskProposal, error)
+	CountPending(ctx context.Context, groupID, userLogin string) (int64, error)
+	GetProposal(ctx context.Context, proposalID, groupID string) (*models.TaskProposal, error)
+	UpdateProposalTask(ctx context.Context, proposalID string, task models.Task, editedBy string) (bool, error)
+	ReviewProposal(ctx context.Context, proposalID, status, reviewer, reason string) (bool, error)
+}
+
+// TaskPreparer checks a task the same way CreateTask does, without saving it
+type TaskPreparer interface {
+	PrepareTask(ctx context.Context, group *models.Group, taskInfo models.CreateTask) (*models.Task, error)
+}
+
+type ProposalSrv struct {
+	Proposal  ProposalRepository
+	Group     GroupRepository
+	Task      TaskRepository
+	Tasks     TaskPreparer
+	Reminders ReminderScheduler
+	Revisions TaskRevisionRepository
+	Events    EventPublisher
+	Tx        Transactor
+}
+
+func NewProposalSrv(proposalRepo ProposalRepository, groupRepo GroupRepository, taskRepo TaskRepository,
+	tasks TaskPreparer, reminders ReminderScheduler, revisions TaskRevisionRepository, events EventPublisher,
+	tx Transactor) *ProposalSrv {
+	return &ProposalSrv{Proposal: proposalRepo, Group: groupRepo, Task: taskRepo, Tasks: tasks,
+		Reminders: reminders, Revisions: revisions, Events: events, Tx: tx}
+}
+
+// ProposeTask lets any member suggest a task, it is checked now and once again on approval
+func (s *ProposalSrv) ProposeTask(ctx context.Context, userLogin string, taskInfo models.CreateTask, comment string) error {
+	group, err := s.getGroup(ctx, taskInfo.GroupID, userLogin)
+	if err != nil {
+		return err
+	}
+	if len(comment) > maxProposalText {
+		return fmt.Errorf("comment must be at most %d characters long", maxProposalText)
+	}
+	pending, err := s.Proposal.CountPending(ctx, taskInfo.GroupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if pending >= maxPendingProposals {
+		return fmt.Errorf("you already have %d proposals waiting for review", maxPendingProposals)
+	}
+	task, err := s.Tasks.PrepareTask(ctx, group, taskInfo)
+	if err != nil {
+		return err
+	}
+	proposal := models.TaskProposal{
+		GroupID:    group.ID,
+		Task:       *task,
+		Comment:    comment,
+		ProposedBy: userLogin,
+		CreatedAt:  time.Now().UTC(),
+		Status:     models.ProposalPending,
+	}
+	proposal.Task.GroupID = group.ID
+	err = s.Proposal.AddProposal(ctx, proposal)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+// GetProposals shows the review queue to the leader and moderators, other members see only their own proposals
+func (s *ProposalSrv) GetProposals(ctx context.Context, groupID, userLogin, status string) ([]models.TaskProposal, error) {
+	group, err := s.getGroup(ctx, groupID, userLogin)
+	if err != nil {
+		return nil, err
+	}
+	if status != "" && !slices.Contains(models.ProposalStatuses, status) {
+		return nil, errors.New("unknown proposal status")
+	}
+	filter := models.ProposalFilter{GroupID: groupID, Status: status}
+	if !group.CanReview(userLogin) {
+		filter.ProposedBy = userLogin
+	}
+	proposals, err := s.Proposal.GetProposals(ctx, filter)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return proposals, nil
+}
+
+// EditProposal changes a pending proposal, empty fields stay as proposed
+func (s *ProposalSrv) EditProposal(ctx context.Context, proposalID, userLogin string, update models.CreateTask) error {
+	group, err := s.getGroup(ctx, update.GroupID, userLogin)
+	if err != nil {
+		return err
+	}
+	proposal, err := s.getPending(ctx, proposalID, update.GroupID)
+	if err != nil {
+		return err
+	}
+	if proposal.ProposedBy != userLogin && !group.CanReview(userLogin) {
+		return errors.New("you have no permissions to do this")
+	}
+	taskInfo := proposalTaskInfo(proposal.Task, group)
+	if update.Title != "" {
+		taskInfo.Title = update.Title
+	}
+	if update.StartTime.StartDate != "" {
+		taskInfo.StartTime.StartDate = update.StartTime.StartDate
+	}
+	if update.StartTime.StartTime != "" {
+		taskInfo.StartTime.StartTime = update.StartTime.StartTime
+	}
+	if !update.Duration.IsEmpty() {
+		taskInfo.Duration = update.Duration
+	}
+	if update.Location != nil {
+		taskInfo.Location = update.Location
+	}
+	if update.Recurrence != "" {
+		taskInfo.Recurrence = update.Recurrence
+	}
+	task, err := s.Tasks.PrepareTask(ctx, group, taskInfo)
+	if err != nil {
+		return err
+	}
+	task.GroupID = group.ID
+	ok, err := s.Proposal.UpdateProposalTask(ctx, proposalID, *task, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if !ok {
+		return errors.New("proposal was already reviewed")
+	}
+	return nil
+}
+
+// ApproveProposal creates the task, the time could have been taken since the proposal was made so it is checked again
+func (s *ProposalSrv) ApproveProposal(ctx context.Context, groupID, proposalID, userLogin, reason string) error {
+	group, proposal, err := s.getForReview(ctx, groupID, proposalID, userLogin, reason)
+	if err != nil {
+		return err
+	}
+	task, err := s.Tasks.PrepareTask(ctx, group, proposalTaskInfo(proposal.Task, group))
+	if err != nil {
+		return fmt.Errorf("proposal cant be approved: %v", err)
+	}
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		ok, err := s.Proposal.ReviewProposal(ctx, proposalID, models.ProposalApproved, userLogin, reason)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		if !ok {
+			return errors.New("proposal was already reviewed")
+		}
+		err = s.Task.AddTask(ctx, *task, groupID)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		summary := fmt.Sprintf("%s approved the task %s proposed by %s", userLogin, task.Title, proposal.ProposedBy)
+		err = addRevision(ctx, s.Revisions, group, userLogin, task.ID, taskCreated, summary, nil, task)
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, s.Events, models.TaskCreated{TaskChange: models.TaskChange{
+			Group:   *group,
+			Actor:   userLogin,
+			TaskID:  task.ID,
+			Title:   task.Title,
+			Summary: summary,
+			After:   task,
+		}})
+	})
+	if err != nil {
+		return err
+	}
+	s.Reminders.RescheduleReminders(ctx, groupID, task.ID.Hex())
+	return nil
+}
+
+func (s *ProposalSrv) RejectProposal(ctx context.Context, groupID, proposalID, userLogin, reason string) error {
+	if reason == "" {
+		return errors.New("tell the member why the proposal is rejected")
+	}
+	group, proposal, err := s.getForReview(ctx, groupID, proposalID, userLogin, reason)
+	if err != nil {
+		return err
+	}
+	// the member is told the reason only if the rejection is saved
+	return inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		ok, err := s.Proposal.ReviewProposal(ctx, proposalID, models.ProposalRejected, userLogin, reason)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		if !ok {
+			return errors.New("proposal was already reviewed")
+		}
+		return publishEvent(ctx, s.Events, models.TaskProposalRejected{
+			Group:    *group,
+			Actor:    userLogin,
+			Proposal: *proposal,
+			Reason:   reason,
+		})
+	})
+}
+
+func (s *ProposalSrv) getForReview(ctx context.Context, groupID, proposalID, userLogin, reason string) (*models.Group, *models.TaskProposal, error) {
+	group, err := s.getGroup(ctx, groupID, userLogin)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !group.CanReview(userLogin) {
+		return nil, nil, errors.New("you have no permissions to do this")
+	}
+	if len(reason) > maxProposalText {
+		return nil, nil, fmt.Errorf("reason must be at most %d characters long", maxProposalText)
+	}
+	proposal, err := s.getPending(ctx, proposalID, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return group, proposal, nil
+}
+
+func (s *ProposalSrv) getGroup(ctx context.Context, groupID, userLogin string) (*models.Group, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	return group, nil
+}
+
+func (s *ProposalSrv) getPending(ctx context.Context, proposalID, groupID string) (*models.TaskProposal, error) {
+	proposal, err := s.Proposal.GetProposal(ctx, proposalID, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find proposal")
+	}
+	if proposal == nil {
+		return nil, errors.New("proposal is not found")
+	}
+	if proposal.Status != models.ProposalPending {
+		return nil, errors.New("proposal was already reviewed")
+	}
+	return proposal, nil
+}
+
+// proposalTaskInfo turns the stored task back into the input of CreateTask
+func proposalTaskInfo(task models.Task, group *models.Group) models.CreateTask {
+	taskInfo := models.CreateTask{
+		GroupID:   group.ID.Hex(),
+		Title:     task.Title,
+		StartTime: taskStartInput(task.StartTime, group.Trip),
+		Duration:  models.Duration{DurMinutes: task.Duration},
+		Location:  task.Location,
+	}
+	if task.Recurrence != nil {
+		taskInfo.Recurrence = task.Recurrence.Rule
+	}
+	return taskInfo
+}
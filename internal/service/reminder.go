@@ -0,0 +1,283 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultReminderMinutes = 30
+	// a week is the longest time a reminder can be sent before the task
+	maxReminderMinutes = 7 * HoursInDay * MinutesInHour
+)
+
+type ReminderRepository interface {
+	SetOverride(ctx context.Context, override models.ReminderOverride) error
+	DeleteOverride(ctx context.Context, groupID, userLogin string) error
+	GetOverrides(ctx context.Context, groupID string) ([]models.ReminderOverride, error)
+	DeleteUserOverrides(ctx context.Context, userLogin string) error
+}
+
+// ChatPoster saves messages of the application to the group chat, the key makes posting twice a no-op
+type ChatPoster interface {
+	PostSystemMessage(ctx context.Context, key string, msg models.Message) (bool, error)
+}
+
+// ReminderScheduler is told about every change of task time, so reminders follow the task
+type ReminderScheduler interface {
+	RescheduleReminders(ctx context.Context, groupID, taskID string)
+	CancelReminders(ctx context.Context, taskID string)
+}
+
+// ReminderSrv sends reminders before tasks start. Each task has one job, it sends everything that is due
+// and schedules itself for the next reminder. Messages are keyed by task, occurrence and offset,
+// so a job run again after a crash sends nothing twice.
+type ReminderSrv struct {
+	Task          TaskRepository
+	Group         GroupRepository
+	Reminder      ReminderRepository
+	Notifications Notifier
+	Chat          ChatPoster
+	Jobs          JobQueue
+	// Broadcast pushes a new system message to the connected members of the group
+	Broadcast func(msg models.Message)
+}
+
+func NewReminderSrv(taskRepo TaskRepository, groupRepo GroupRepository, reminderRepo ReminderRepository,
+	notifications Notifier, chat ChatPoster, jobs JobQueue) *ReminderSrv {
+	return &ReminderSrv{Task: taskRepo, Group: groupRepo, Reminder: reminderRepo,
+		Notifications: notifications, Chat: chat, Jobs: jobs}
+}
+
+func taskReminderJobKey(taskID string) string {
+	return "task_reminder:" + taskID
+}
+
+func groupReminderMinutes(group *models.Group) int {
+	if group.ReminderMinutes == nil {
+		return DefaultReminderMinutes
+	}
+	return *group.ReminderMinutes
+}
+
+func checkReminderMinutes(minutes int) error {
+	if minutes < 0 || minutes > maxReminderMinutes {
+		return fmt.Errorf("minutes must be from 0 to %d, 0 turns reminders off", maxReminderMinutes)
+	}
+	return nil
+}
+
+// RescheduleReminders lets the reminder job of the task look at the task again right away
+func (s *ReminderSrv) RescheduleReminders(ctx context.Context, groupID, taskID string) {
+	err := s.Jobs.Schedule(ctx, models.JobTaskReminder, taskReminderJobKey(taskID), time.Now(),
+		map[string]string{"group_id": groupID, "task_id": taskID})
+	if err != nil {
+		logs.Error(err)
+	}
+}
+
+func (s *ReminderSrv) CancelReminders(ctx context.Context, taskID string) {
+	if err := s.Jobs.Cancel(ctx, taskReminderJobKey(taskID)); err != nil {
+		logs.Error(err)
+	}
+}
+
+func (s *ReminderSrv) rescheduleGroup(ctx context.Context, group *models.Group) {
+	tasks, err := s.Task.GetTaskList(ctx, group.LeaderLogin, group.ID.Hex())
+	if err != nil {
+		logs.Error(err)
+		return
+	}
+	for _, task := range tasks {
+		s.RescheduleReminders(ctx, group.ID.Hex(), task.ID.Hex())
+	}
+}
+
+func (s *ReminderSrv) GetReminderSettings(ctx context.Context, groupID, userLogin string) (*models.ReminderSettings, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	overrides, err := s.Reminder.GetOverrides(ctx, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	settings := &models.ReminderSettings{GroupMinutes: groupReminderMinutes(group)}
+	settings.Effective = settings.GroupMinutes
+	for _, override := range overrides {
+		if override.UserLogin == userLogin {
+			settings.MyMinutes = &override.Minutes
+			settings.Effective = override.Minutes
+		}
+	}
+	return settings, nil
+}
+
+// SetGroupReminder changes how long before tasks members are reminded, 0 turns reminders off
+func (s *ReminderSrv) SetGroupReminder(ctx context.Context, groupID, userLogin string, minutes int) error {
+	if err := checkReminderMinutes(minutes); err != nil {
+		return err
+	}
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return errors.New("you have no permissions to do this")
+	}
+	err = s.Group.SetReminderMinutes(ctx, groupID, &minutes)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	s.rescheduleGroup(ctx, group)
+	return nil
+}
+
+// SetMyReminder overrides the reminder time of the group for the user, 0 turns reminders off
+func (s *ReminderSrv) SetMyReminder(ctx context.Context, groupID, userLogin string, minutes int) error {
+	if err := checkReminderMinutes(minutes); err != nil {
+		return err
+	}
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	err = s.Reminder.SetOverride(ctx, models.ReminderOverride{GroupID: groupID, UserLogin: userLogin, Minutes: minutes})
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	s.rescheduleGroup(ctx, group)
+	return nil
+}
+
+// ResetMyReminder returns the user to the reminder time of the group
+func (s *ReminderSrv) ResetMyReminder(ctx context.Context, groupID, userLogin string) error {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to find group")
+	}
+	if group == nil {
+		return errors.New("group is not found, or you are not a member of it")
+	}
+	err = s.Reminder.DeleteOverride(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	s.rescheduleGroup(ctx, group)
+	return nil
+}
+
+// SendTaskReminders is the job of one task, it sends reminders that are due and not sent yet.
+// Reminders whose task already started are dropped, there is nothing left to remind about.
+func (s *ReminderSrv) SendTaskReminders(ctx context.Context, job models.Job) error {
+	groupID, taskID := job.Payload["group_id"], job.Payload["task_id"]
+	group, err := s.Group.GetGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return nil
+	}
+	tasks, err := s.Task.GetTaskList(ctx, group.LeaderLogin, groupID)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(tasks, func(task models.Task) bool { return task.ID.Hex() == taskID })
+	if i < 0 || tasks[i].CurrentStatus() == models.TaskCancelled {
+		return nil
+	}
+	overrides, err := s.Reminder.GetOverrides(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	groupMinutes := groupReminderMinutes(group)
+	memberMinutes := make(map[string]int, len(group.Members))
+	for _, member := range group.Members {
+		memberMinutes[member] = groupMinutes
+	}
+	for _, override := range overrides {
+		if _, ok := memberMinutes[override.UserLogin]; ok {
+			memberMinutes[override.UserLogin] = override.Minutes
+		}
+	}
+
+	now := time.Now().UTC()
+	var next time.Time
+	// due tells whether the reminder is to be sent now, reminders of the future move the next run
+	due := func(start time.Time, minutes int) bool {
+		if minutes == 0 || !start.After(now) {
+			return false
+		}
+		remindAt := start.Add(-time.Duration(minutes) * time.Minute)
+		if remindAt.After(now) {
+			if next.IsZero() || remindAt.Before(next) {
+				next = remindAt
+			}
+			return false
+		}
+		return true
+	}
+	loc := group.Trip.Location()
+	for _, occurrence := range expandTasks(tasks[i:i+1], group.Trip) {
+		text := fmt.Sprintf("Reminder: %s starts at %s", occurrence.Title,
+			occurrence.StartTime.In(loc).Format("2006-01-02 15:04"))
+		key := fmt.Sprintf("task_reminder:%s:%d", taskID, occurrence.StartTime.Unix())
+		if due(occurrence.StartTime, groupMinutes) {
+			msgKey := fmt.Sprintf("%s:%d", key, groupMinutes)
+			msg := models.Message{Content: text, GroupID: groupID}
+			posted, err := s.Chat.PostSystemMessage(ctx, msgKey, msg)
+			if err != nil {
+				return err
+			}
+			if posted && s.Broadcast != nil {
+				msg.User, msg.Time = models.SystemUser, now
+				s.Broadcast(msg)
+			}
+		}
+		for member, minutes := range memberMinutes {
+			if !due(occurrence.StartTime, minutes) {
+				continue
+			}
+			err = s.Notifications.Notify(ctx, models.Notification{
+				Key:     fmt.Sprintf("%s:%d", key, minutes),
+				Kind:    models.NotificationTaskReminder,
+				GroupID: groupID,
+				Text:    text,
+				Data: map[string]string{
+					"task_id":    taskID,
+					"start_time": occurrence.StartTime.Format(time.RFC3339),
+				},
+				CreatedAt: now,
+			}, member)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if next.IsZero() {
+		return nil
+	}
+	// scheduling the running job again keeps it after the run completes
+	return s.Jobs.Schedule(ctx, models.JobTaskReminder, job.Key, next, job.Payload)
+}
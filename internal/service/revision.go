@@ -0,0 +1,239 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// taskRevisionTTL is how long revisions are kept, the last change can be undone and a deleted task restored only within it
+const taskRevisionTTL = 30 * HoursInDay * time.Hour
+
+type TaskRevisionRepository interface {
+	AddRevision(ctx context.Context, revision models.TaskRevision) error
+	GetRevisions(ctx context.Context, groupID, taskID string) ([]models.TaskRevision, error)
+	GetLastRevision(ctx context.Context, groupID, taskID string) (*models.TaskRevision, error)
+	GetDeletedTasks(ctx context.Context, groupID string, since time.Time) ([]models.TaskRevision, error)
+	PurgeRevisions(ctx context.Context, createdBefore time.Time) (int64, error)
+}
+
+// addRevision saves a version of the task, it has to be called in the transaction of the change
+func addRevision(ctx context.Context, revisions TaskRevisionRepository, group *models.Group, author string,
+	taskID primitive.ObjectID, change, summary string, before, after *models.Task) error {
+	err := revisions.AddRevision(ctx, models.TaskRevision{
+		ID:        primitive.NewObjectID(),
+		TaskID:    taskID,
+		GroupID:   group.ID,
+		Author:    author,
+		Change:    change,
+		Summary:   summary,
+		Before:    before,
+		After:     after,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+// recordTaskChange saves a revision of a change the group is not told about, like checklist and dependency edits
+func (s *TaskSrv) recordTaskChange(ctx context.Context, group *models.Group, actor string, before *models.Task, text string) error {
+	after, err := s.Task.GetTaskById(ctx, before.ID.Hex(), group.ID.Hex())
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return addRevision(ctx, s.Revisions, group, actor, before.ID, taskUpdated, text, before, after)
+}
+
+func (s *TaskSrv) getMemberGroup(ctx context.Context, groupID, userLogin string) (*models.Group, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	return group, nil
+}
+
+// GetTaskHistory returns the revisions of the task, newest first, the task can be already deleted
+func (s *TaskSrv) GetTaskHistory(ctx context.Context, groupID, taskID, userLogin string) ([]models.TaskRevision, error) {
+	if _, err := s.getMemberGroup(ctx, groupID, userLogin); err != nil {
+		return nil, err
+	}
+	revisions, err := s.Revisions.GetRevisions(ctx, groupID, taskID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return revisions, nil
+}
+
+// GetDeletedTasks returns the deletions of tasks that can still be restored, newest first
+func (s *TaskSrv) GetDeletedTasks(ctx context.Context, groupID, userLogin string) ([]models.TaskRevision, error) {
+	if _, err := s.getMemberGroup(ctx, groupID, userLogin); err != nil {
+		return nil, err
+	}
+	revisions, err := s.Revisions.GetDeletedTasks(ctx, groupID, time.Now().UTC().Add(-taskRevisionTTL))
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return revisions, nil
+}
+
+// UndoTaskChange brings the task back to how it was before its last change. Undo is a change too,
+// so undoing twice redoes the change
+func (s *TaskSrv) UndoTaskChange(ctx context.Context, groupID, taskID, userLogin string) (*models.Task, error) {
+	group, err := s.getMemberGroup(ctx, groupID, userLogin)
+	if err != nil {
+		return nil, err
+	}
+	if group.LeaderLogin != userLogin {
+		return nil, errors.New("you have no permissions to do this")
+	}
+	current, err := s.Task.GetTaskById(ctx, taskID, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("task was not found")
+	}
+	revision, err := s.getRestorableRevision(ctx, groupID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Change == taskDeleted {
+		return nil, errors.New("task was deleted after its last change, restore it instead")
+	}
+	if revision.Before == nil {
+		return nil, errors.New("task has no earlier version, delete it instead")
+	}
+	task, err := s.checkRestoredTask(ctx, group, userLogin, current, *revision.Before)
+	if err != nil {
+		return nil, err
+	}
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.ReplaceTask(ctx, task)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.publishTaskChange(ctx, group, userLogin, current, task.ID, taskUndone,
+			fmt.Sprintf("%s undid the last change of the task %s", userLogin, task.Title))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Reminders.RescheduleReminders(ctx, groupID, taskID)
+	return &task, nil
+}
+
+// RestoreTask brings a deleted task back as it was when it was deleted
+func (s *TaskSrv) RestoreTask(ctx context.Context, groupID, taskID, userLogin string) (*models.Task, error) {
+	group, err := s.getMemberGroup(ctx, groupID, userLogin)
+	if err != nil {
+		return nil, err
+	}
+	if group.LeaderLogin != userLogin {
+		return nil, errors.New("you have no permissions to do this")
+	}
+	revision, err := s.getRestorableRevision(ctx, groupID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Change != taskDeleted || revision.Before == nil {
+		return nil, errors.New("task is not deleted, undo its last change instead")
+	}
+	task, err := s.checkRestoredTask(ctx, group, userLogin, nil, *revision.Before)
+	if err != nil {
+		return nil, err
+	}
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.AddTask(ctx, task, groupID)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.publishTaskChange(ctx, group, userLogin, nil, task.ID, taskRestored,
+			fmt.Sprintf("%s restored the task %s", userLogin, task.Title))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Reminders.RescheduleReminders(ctx, groupID, taskID)
+	return &task, nil
+}
+
+func (s *TaskSrv) getRestorableRevision(ctx context.Context, groupID, taskID string) (*models.TaskRevision, error) {
+	revision, err := s.Revisions.GetLastRevision(ctx, groupID, taskID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	if revision == nil {
+		return nil, errors.New("task has no saved versions")
+	}
+	if revision.CreatedAt.Before(time.Now().UTC().Add(-taskRevisionTTL)) {
+		return nil, fmt.Errorf("changes older than %d days cant be undone", int(taskRevisionTTL.Hours()/HoursInDay))
+	}
+	return revision, nil
+}
+
+// checkRestoredTask runs the checks of a changed task against the current plan: the past time, the trip dates,
+// overlaps and dependencies. As with editing, a task keeping its current start may stay in the past, current is nil
+// for a deleted task. Dependencies on tasks that do not exist anymore are dropped
+func (s *TaskSrv) checkRestoredTask(ctx context.Context, group *models.Group, userLogin string, current *models.Task,
+	task models.Task) (models.Task, error) {
+	if (current == nil || !current.StartTime.Equal(task.StartTime)) && task.StartTime.Before(time.Now().UTC()) {
+		return task, errors.New("you cant add tasks to past time")
+	}
+	tasks, err := s.Task.GetTaskList(ctx, userLogin, group.ID.Hex())
+	if err != nil {
+		logs.Error(err)
+		return task, errors.New("System error")
+	}
+	task.DependsOn = slices.DeleteFunc(slices.Clone(task.DependsOn), func(dependency models.Dependency) bool {
+		return !slices.ContainsFunc(tasks, func(other models.Task) bool { return other.ID == dependency.TaskID })
+	})
+	if err := checkTripWindow(group.Trip, task); err != nil {
+		return task, err
+	}
+	planned := replaceTask(tasks, task)
+	if !slices.ContainsFunc(tasks, func(other models.Task) bool { return other.ID == task.ID }) {
+		planned = append(planned, task)
+	}
+	if err := findOverlap(planned, []primitive.ObjectID{task.ID}, group.Trip); err != nil {
+		return task, err
+	}
+	for _, dependency := range task.DependsOn {
+		if dependsOnPath(planned, dependency.TaskID, task.ID) {
+			return task, errors.New("task cant be restored, its dependencies would make a cycle")
+		}
+	}
+	shifted, err := shiftDependents(tasks, task, true)
+	if err != nil {
+		return task, err
+	}
+	if len(shifted) > 0 {
+		return task, fmt.Errorf("task cant be restored, it breaks dependency of %s, move it first", shifted[0].Title)
+	}
+	return task, nil
+}
+
+// PurgeRevisions removes revisions older than the time changes can be undone, it is a periodic job
+func (s *TaskSrv) PurgeRevisions(ctx context.Context) error {
+	deleted, err := s.Revisions.PurgeRevisions(ctx, time.Now().UTC().Add(-taskRevisionTTL))
+	if err != nil {
+		return err
+	}
+	logs.Infof("purged %d task revisions", deleted)
+	return nil
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxOccurrences        = 1000
+	maxRecurrenceInterval = 30
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// rrule is the supported subset of RFC 5545 recurrence rules:
+// FREQ=DAILY|WEEKLY, INTERVAL, COUNT, UNTIL and BYDAY for weekly rules
+type rrule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+}
+
+func parseRRule(rule string) (*rrule, error) {
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	parsed := &rrule{interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part: %q", part)
+		}
+		switch key {
+		case "FREQ":
+			if value != "DAILY" && value != "WEEKLY" {
+				return nil, errors.New("only DAILY and WEEKLY rules are supported")
+			}
+			parsed.freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > maxRecurrenceInterval {
+				return nil, fmt.Errorf("INTERVAL must be from 1 to %d", maxRecurrenceInterval)
+			}
+			parsed.interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 || count > maxOccurrences {
+				return nil, fmt.Errorf("COUNT must be from 1 to %d", maxOccurrences)
+			}
+			parsed.count = count
+		case "UNTIL":
+			until, err := time.Parse("20060102", value[:min(len(value), 8)])
+			if err != nil {
+				return nil, errors.New("UNTIL must be a date like 20241028")
+			}
+			parsed.until = until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("unknown BYDAY value: %q", day)
+				}
+				if !slices.Contains(parsed.byDay, weekday) {
+					parsed.byDay = append(parsed.byDay, weekday)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%s is not supported", key)
+		}
+	}
+	if parsed.freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if parsed.count > 0 && !parsed.until.IsZero() {
+		return nil, errors.New("use either COUNT or UNTIL")
+	}
+	if len(parsed.byDay) > 0 && parsed.freq != "WEEKLY" {
+		return nil, errors.New("BYDAY is supported only for WEEKLY rules")
+	}
+	return parsed, nil
+}
+
+func (r *rrule) isBounded() bool {
+	return r.count > 0 || !r.until.IsZero()
+}
+
+func (r *rrule) String() string {
+	parts := []string{"FREQ=" + r.freq}
+	if r.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.interval))
+	}
+	if len(r.byDay) > 0 {
+		var days []string
+		for _, weekday := range r.byDay {
+			for name, day := range rruleWeekdays {
+				if day == weekday {
+					days = append(days, name)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.count))
+	}
+	if !r.until.IsZero() {
+		parts = append(parts, "UNTIL="+r.until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// occurrences returns starts of the series before the end time (zero means no end).
+// The local clock time of the first start is kept on every day, also across DST changes.
+func (r *rrule) occurrences(start time.Time, loc *time.Location, end time.Time) []time.Time {
+	local := start.In(loc)
+	var starts []time.Time
+	add := func(day time.Time) bool {
+		occurrence := time.Date(day.Year(), day.Month(), day.Day(), local.Hour(), local.Minute(), 0, 0, loc)
+		if occurrence.Before(start) {
+			return true
+		}
+		if !r.until.IsZero() && dateInLocation(r.until, loc).AddDate(0, 0, 1).Compare(occurrence) <= 0 {
+			return false
+		}
+		if !end.IsZero() && !occurrence.Before(end) {
+			return false
+		}
+		if len(starts) >= maxOccurrences || (r.count > 0 && len(starts) >= r.count) {
+			return false
+		}
+		starts = append(starts, occurrence)
+		return true
+	}
+	firstDay := localMidnight(start, loc)
+	if r.freq == "DAILY" || len(r.byDay) == 0 {
+		step := r.interval
+		if r.freq == "WEEKLY" {
+			step *= 7
+		}
+		for day := firstDay; ; day = day.AddDate(0, 0, step) {
+			if !add(day) {
+				return starts
+			}
+		}
+	}
+	weekdays := slices.Clone(r.byDay)
+	// weeks start on Monday
+	slices.SortFunc(weekdays, func(a, b time.Weekday) int { return (int(a)+6)%7 - (int(b)+6)%7 })
+	monday := firstDay.AddDate(0, 0, -((int(firstDay.Weekday()) + 6) % 7))
+	for week := monday; ; week = week.AddDate(0, 0, 7*r.interval) {
+		for _, weekday := range weekdays {
+			if !add(week.AddDate(0, 0, (int(weekday)+6)%7)) {
+				return starts
+			}
+		}
+	}
+}
+
+// expandTasks replaces recurring tasks with their occurrences within the trip window,
+// cancelled occurrences are skipped and edited ones are taken as edited
+func expandTasks(tasks []models.Task, trip models.Trip) []models.Task {
+	loc := trip.Location()
+	_, end := trip.Window()
+	expanded := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Recurrence == nil {
+			expanded = append(expanded, task)
+			continue
+		}
+		expanded = append(expanded, expandTask(task, loc, end)...)
+	}
+	return expanded
+}
+
+func expandTask(task models.Task, loc *time.Location, end time.Time) []models.Task {
+	rule, err := parseRRule(task.Recurrence.Rule)
+	if err != nil {
+		logs.Error(err)
+		return []models.Task{task}
+	}
+	length := task.EndTime.Sub(task.StartTime)
+	var occurrences []models.Task
+	for _, start := range rule.occurrences(task.StartTime, loc, end) {
+		if slices.ContainsFunc(task.Recurrence.Cancelled, start.Equal) {
+			continue
+		}
+		occurrence := task
+		original := start
+		occurrence.Occurrence = &original
+		occurrence.StartTime = start.UTC()
+		occurrence.EndTime = start.Add(length).UTC()
+		for _, override := range task.Recurrence.Overrides {
+			if override.Occurrence.Equal(start) {
+				occurrence.Title = override.Title
+				occurrence.StartTime = override.StartTime
+				occurrence.EndTime = override.EndTime
+				occurrence.Duration = override.Duration
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences
+}
+
+// findOverlap checks expanded occurrences of the changed tasks against every other occurrence,
+// cancelled tasks do not take any time
+func findOverlap(tasks []models.Task, changed []primitive.ObjectID, trip models.Trip) error {
+	expanded := slices.DeleteFunc(expandTasks(tasks, trip), func(task models.Task) bool {
+		return task.CurrentStatus() == models.TaskCancelled
+	})
+	for _, changedTask := range expanded {
+		if !slices.Contains(changed, changedTask.ID) {
+			continue
+		}
+		for _, other := range expanded {
+			if other.ID == changedTask.ID && sameOccurrence(other, changedTask) {
+				continue
+			}
+			if doTasksOverlap(other, changedTask) {
+				if other.Occurrence != nil {
+					return fmt.Errorf("task overlaps with an existing task: %s on %s", other.Title,
+						other.StartTime.In(trip.Location()).Format(models.TripDateFormat))
+				}
+				return fmt.Errorf("task overlaps with an existing task: %s", other.Title)
+			}
+		}
+	}
+	return nil
+}
+
+func sameOccurrence(a, b models.Task) bool {
+	if a.Occurrence == nil || b.Occurrence == nil {
+		return a.Occurrence == nil && b.Occurrence == nil
+	}
+	return a.Occurrence.Equal(*b.Occurrence)
+}
+
+// taskKey tells occurrences of the same recurring task apart
+func taskKey(task models.Task) string {
+	if task.Occurrence == nil {
+		return task.ID.Hex()
+	}
+	return task.ID.Hex() + "@" + task.Occurrence.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{name: "daily", rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "prefix and case", rule: " rrule:freq=daily;interval=2 ", want: "FREQ=DAILY;INTERVAL=2"},
+		{name: "weekly by day", rule: "FREQ=WEEKLY;BYDAY=MO,WE,MO;COUNT=4", want: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4"},
+		{name: "until", rule: "FREQ=DAILY;UNTIL=20301028T000000Z", want: "FREQ=DAILY;UNTIL=20301028"},
+		{name: "no freq", rule: "INTERVAL=2", wantErr: true},
+		{name: "monthly", rule: "FREQ=MONTHLY", wantErr: true},
+		{name: "count and until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20301028", wantErr: true},
+		{name: "zero count", rule: "FREQ=DAILY;COUNT=0", wantErr: true},
+		{name: "interval too big", rule: "FREQ=DAILY;INTERVAL=31", wantErr: true},
+		{name: "by day for daily", rule: "FREQ=DAILY;BYDAY=MO", wantErr: true},
+		{name: "unknown day", rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{name: "invalid until", rule: "FREQ=DAILY;UNTIL=tomorrow", wantErr: true},
+		{name: "unsupported part", rule: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+		{name: "no value", rule: "FREQ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseRRule(tt.rule)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseRRule(%q) = %s, want an error", tt.rule, rule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRRule(%q) error: %v", tt.rule, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("parseRRule(%q) = %s, want %s", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRuleOccurrences(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Skip("no timezone data")
+	}
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2030, month, day, hour, 0, 0, 0, rome)
+	}
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		end   time.Time
+		want  []time.Time
+	}{
+		{name: "count", rule: "FREQ=DAILY;COUNT=3", start: at(time.January, 7, 9),
+			want: []time.Time{at(time.January, 7, 9), at(time.January, 8, 9), at(time.January, 9, 9)}},
+		{name: "until is inclusive", rule: "FREQ=DAILY;INTERVAL=2;UNTIL=20300111", start: at(time.January, 7, 9),
+			want: []time.Time{at(time.January, 7, 9), at(time.January, 9, 9), at(time.January, 11, 9)}},
+		{name: "end is exclusive", rule: "FREQ=DAILY", start: at(time.January, 7, 9), end: at(time.January, 9, 9),
+			want: []time.Time{at(time.January, 7, 9), at(time.January, 8, 9)}},
+		{name: "weekly", rule: "FREQ=WEEKLY;INTERVAL=2;COUNT=3", start: at(time.January, 9, 9),
+			want: []time.Time{at(time.January, 9, 9), at(time.January, 23, 9), at(time.February, 6, 9)}},
+		{name: "by day skips days before the start", rule: "FREQ=WEEKLY;BYDAY=FR,MO;COUNT=4", start: at(time.January, 9, 9),
+			want: []time.Time{at(time.January, 11, 9), at(time.January, 14, 9), at(time.January, 18, 9), at(time.January, 21, 9)}},
+		{name: "by day every other week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TU;COUNT=3", start: at(time.January, 7, 9),
+			want: []time.Time{at(time.January, 7, 9), at(time.January, 8, 9), at(time.January, 21, 9)}},
+		{name: "local time is kept across DST", rule: "FREQ=DAILY;COUNT=3", start: at(time.March, 30, 9),
+			want: []time.Time{at(time.March, 30, 9), at(time.March, 31, 9), at(time.April, 1, 9)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseRRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := rule.occurrences(tt.start, rome, tt.end)
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("occurrences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRuleOccurrencesDSTGap(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Skip("no timezone data")
+	}
+	rule, err := parseRRule("FREQ=DAILY;COUNT=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rule.occurrences(time.Date(2030, time.March, 30, 9, 0, 0, 0, rome), rome, time.Time{})
+	if len(got) != 2 {
+		t.Fatalf("got %d occurrences, want 2", len(got))
+	}
+	if gap := got[1].Sub(got[0]); gap != 23*time.Hour {
+		t.Errorf("occurrences are %v apart, want 23h on the day clocks go forward", gap)
+	}
+}
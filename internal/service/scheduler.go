@@ -0,0 +1,158 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"sort"
+	"time"
+)
+
+// planned activities start on a round time
+const scheduleStep = 5 * time.Minute
+
+// scheduleActivities greedily packs activities into free time of the trip.
+// The most important and the most constrained activities are placed first,
+// each one takes the earliest slot that fits its dates and opening hours
+// and leaves time to travel from the task before it and to the task after it.
+func scheduleActivities(activities []models.Activity, tasks []models.Task, from, to time.Time,
+	loc *time.Location, quietHours bool, buffer time.Duration, speed float64) ([]models.PlannedActivity, []models.Activity) {
+	ordered := make([]models.Activity, len(activities))
+	copy(ordered, activities)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if constraints(a) != constraints(b) {
+			return constraints(a) > constraints(b)
+		}
+		return a.Duration > b.Duration
+	})
+
+	busy := make([]models.Task, len(tasks), len(tasks)+len(ordered))
+	copy(busy, tasks)
+	planned := []models.PlannedActivity{}
+	unscheduled := []models.Activity{}
+	for _, activity := range ordered {
+		free := activityFreeTime(activity.Location, busy, from, to, loc, quietHours, buffer, speed)
+		slot, ok := fitActivity(activity, free, loc)
+		if !ok {
+			unscheduled = append(unscheduled, activity)
+			continue
+		}
+		planned = append(planned, models.PlannedActivity{
+			ActivityID: activity.ID,
+			Title:      activity.Title,
+			Priority:   activity.Priority,
+			StartTime:  slot.start,
+			EndTime:    slot.end,
+			Location:   activity.Location,
+		})
+		busy = append(busy, models.Task{StartTime: slot.start, EndTime: slot.end, Location: activity.Location})
+	}
+	sort.Slice(planned, func(i, j int) bool { return planned[i].StartTime.Before(planned[j].StartTime) })
+	return planned, unscheduled
+}
+
+// activityFreeTime is free time for an activity at the location. Every hole between tasks is shrunk
+// by the travel from the task before it and to the task after it, see travelGap
+func activityFreeTime(location *models.Location, tasks []models.Task, from, to time.Time, loc *time.Location,
+	quietHours bool, buffer time.Duration, speed float64) []interval {
+	var free []interval
+	for _, hole := range findFreeTime(tasks, from, to, loc, quietHours, 0) {
+		var previous, next *models.Task
+		for i := range tasks {
+			task := &tasks[i]
+			if task.CurrentStatus() == models.TaskCancelled {
+				continue
+			}
+			if !task.EndTime.After(hole.start) && (previous == nil || task.EndTime.After(previous.EndTime)) {
+				previous = task
+			}
+			if !task.StartTime.Before(hole.end) && (next == nil || task.StartTime.Before(next.StartTime)) {
+				next = task
+			}
+		}
+		if previous != nil {
+			if start := previous.EndTime.Add(travelGap(previous.Location, location, buffer, speed)); start.After(hole.start) {
+				hole.start = start.In(loc)
+			}
+		}
+		if next != nil {
+			if end := next.StartTime.Add(-travelGap(location, next.Location, buffer, speed)); end.Before(hole.end) {
+				hole.end = end.In(loc)
+			}
+		}
+		if hole.end.After(hole.start) {
+			free = append(free, hole)
+		}
+	}
+	return free
+}
+
+func constraints(activity models.Activity) int {
+	count := 0
+	if activity.NotBefore != nil {
+		count++
+	}
+	if activity.NotAfter != nil {
+		count++
+	}
+	if activity.OpeningHours != nil {
+		count++
+	}
+	return count
+}
+
+func fitActivity(activity models.Activity, free []interval, loc *time.Location) (interval, bool) {
+	length := time.Duration(activity.Duration) * time.Minute
+	var window interval
+	if activity.NotBefore != nil {
+		window.start = dateInLocation(*activity.NotBefore, loc)
+	}
+	if activity.NotAfter != nil {
+		window.end = dateInLocation(*activity.NotAfter, loc).AddDate(0, 0, 1)
+	}
+	for _, f := range free {
+		if !window.start.IsZero() && f.start.Before(window.start) {
+			f.start = window.start
+		}
+		if !window.end.IsZero() && f.end.After(window.end) {
+			f.end = window.end
+		}
+		for _, candidate := range openIntervals(activity.OpeningHours, f, loc) {
+			start := candidate.start.Truncate(scheduleStep)
+			if start.Before(candidate.start) {
+				start = start.Add(scheduleStep)
+			}
+			if !start.Add(length).After(candidate.end) {
+				return interval{start: start, end: start.Add(length)}, true
+			}
+		}
+	}
+	return interval{}, false
+}
+
+// openIntervals cuts the free interval down to the opening hours of every day it touches
+func openIntervals(hours *models.OpeningHours, free interval, loc *time.Location) []interval {
+	if !free.end.After(free.start) {
+		return nil
+	}
+	if hours == nil {
+		return []interval{free}
+	}
+	opens, _ := time.Parse(clockFormat, hours.Opens)
+	closes, _ := time.Parse(clockFormat, hours.Closes)
+	var open []interval
+	for day := localMidnight(free.start, loc); day.Before(free.end); day = day.AddDate(0, 0, 1) {
+		open = append(open, interval{
+			start: time.Date(day.Year(), day.Month(), day.Day(), opens.Hour(), opens.Minute(), 0, 0, loc),
+			end:   time.Date(day.Year(), day.Month(), day.Day(), closes.Hour(), closes.Minute(), 0, 0, loc),
+		})
+	}
+	return clipIntervals(open, free.start, free.end)
+}
+
+// dates are stored as UTC midnights, the day itself is meant in trip local time
+func dateInLocation(date time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"errors"
+	"time"
+)
+
+// nobody wants an activity planned between these hours of trip local time
+const (
+	quietHoursStart = 23
+	quietHoursEnd   = 7
+	maxSlotBuffer   = 12 * MinutesInHour
+)
+
+func (s *TaskSrv) FindFreeSlots(ctx context.Context, userLogin string, search models.SlotSearch) ([]models.FreeSlot, error) {
+	minutes := calculateDuration(search.Duration)
+	if minutes <= 0 {
+		return nil, errors.New("duration must be positive")
+	}
+	if search.Buffer < 0 || search.Buffer > maxSlotBuffer {
+		return nil, errors.New("buffer must be between 0 and 720 minutes")
+	}
+	group, err := s.Group.GetGroup(ctx, search.GroupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	tasks, err := s.Task.GetTaskList(ctx, userLogin, search.GroupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	loc := group.Trip.Location()
+	first, last, err := itineraryRange(group.Trip, nil, search.From, search.To, loc)
+	if err != nil {
+		return nil, err
+	}
+	if first.IsZero() {
+		return nil, errors.New("set the date range or the trip dates first")
+	}
+	from, to := first, last.AddDate(0, 0, 1)
+	if now := time.Now().UTC(); from.Before(now) {
+		from = now.Truncate(time.Minute).Add(time.Minute)
+	}
+
+	slots := []models.FreeSlot{}
+	for _, free := range findFreeTime(expandTasks(tasks, group.Trip), from, to, loc, search.QuietHours, time.Duration(search.Buffer)*time.Minute) {
+		if free.minutes() >= minutes {
+			slots = append(slots, models.FreeSlot{StartTime: free.start, EndTime: free.end, Minutes: free.minutes()})
+		}
+	}
+	return slots, nil
+}
+
+// findFreeTime returns time within [from, to) not taken by tasks, their buffers and, if asked, quiet hours
+func findFreeTime(tasks []models.Task, from, to time.Time, loc *time.Location,
+	quietHours bool, buffer time.Duration) []interval {
+	busy := make([]interval, 0, len(tasks))
+	for _, task := range tasks {
+		if task.CurrentStatus() == models.TaskCancelled {
+			continue
+		}
+		busy = append(busy, interval{start: task.StartTime.Add(-buffer).In(loc), end: task.EndTime.Add(buffer).In(loc)})
+	}
+	if quietHours {
+		for day := localMidnight(from, loc).AddDate(0, 0, -1); day.Before(to); day = day.AddDate(0, 0, 1) {
+			busy = append(busy, interval{
+				start: time.Date(day.Year(), day.Month(), day.Day(), quietHoursStart, 0, 0, 0, loc),
+				end:   time.Date(day.Year(), day.Month(), day.Day()+1, quietHoursEnd, 0, 0, 0, loc),
+			})
+		}
+	}
+	return freeIntervals(busy, from.In(loc), to.In(loc))
+}
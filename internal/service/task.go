@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TaskRepository interface {
@@ -14,15 +16,68 @@ type TaskRepository interface {
 	GetTaskById(ctx context.Context, taskID, groupID string) (*models.Task, error)
 	UpdateTask(ctx context.Context, taskID string, newTask models.Task) error
 	DeleteTask(ctx context.Context, taskID string) error
+	SetDependencies(ctx context.Context, taskID string, dependencies []models.Dependency) error
+	RemoveDependenciesOn(ctx context.Context, taskID string) error
+	SetTaskStatus(ctx context.Context, taskID, status string) error
+	AddChecklistItem(ctx context.Context, taskID string, item models.ChecklistItem) error
+	UpdateChecklistItem(ctx context.Context, taskID string, item models.ChecklistItem) error
+	DeleteChecklistItem(ctx context.Context, taskID, itemID string) error
+	ReplaceTask(ctx context.Context, task models.Task) error
 }
 
 type TaskSrv struct {
-	Task  TaskRepository
-	Group GroupRepository
+	Task      TaskRepository
+	Group     GroupRepository
+	Speeds    TravelSpeeds
+	Reminders ReminderScheduler
+	Revisions TaskRevisionRepository
+	Events    EventPublisher
+	Tx        Transactor
+}
+
+func NewTaskSrv(taskRepo TaskRepository, groupRepo GroupRepository, speeds TravelSpeeds,
+	reminders ReminderScheduler, revisions TaskRevisionRepository, events EventPublisher, tx Transactor) *TaskSrv {
+	return &TaskSrv{Task: taskRepo, Group: groupRepo, Speeds: speeds, Reminders: reminders, Revisions: revisions,
+		Events: events, Tx: tx}
 }
 
-func NewTaskSrv(taskRepo TaskRepository, groupRepo GroupRepository) *TaskSrv {
-	return &TaskSrv{Task: taskRepo, Group: groupRepo}
+const (
+	taskCreated  = models.RevisionCreated
+	taskUpdated  = models.RevisionUpdated
+	taskDeleted  = models.RevisionDeleted
+	taskRestored = models.RevisionRestored
+	taskUndone   = models.RevisionUndone
+)
+
+// publishTaskChange saves a revision of the task and publishes TaskCreated, TaskUpdated or TaskDeleted,
+// a restored task is published as created. text describes the change for people.
+// before is the task as it was, nil for a new task. The task as it is now is read back in the transaction of the change
+func (s *TaskSrv) publishTaskChange(ctx context.Context, group *models.Group, actor string, before *models.Task,
+	taskID primitive.ObjectID, change, text string) error {
+	taskChange := models.TaskChange{Group: *group, Actor: actor, TaskID: taskID, Summary: text, Before: before}
+	if change != taskDeleted {
+		after, err := s.Task.GetTaskById(ctx, taskID.Hex(), group.ID.Hex())
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		taskChange.After = after
+		taskChange.Title = after.Title
+	} else {
+		taskChange.Title = before.Title
+	}
+	err := addRevision(ctx, s.Revisions, group, actor, taskID, change, text, taskChange.Before, taskChange.After)
+	if err != nil {
+		return err
+	}
+	var event models.Event = models.TaskUpdated{TaskChange: taskChange}
+	switch change {
+	case taskCreated, taskRestored:
+		event = models.TaskCreated{TaskChange: taskChange}
+	case taskDeleted:
+		event = models.TaskDeleted{TaskChange: taskChange}
+	}
+	return publishEvent(ctx, s.Events, event)
 }
 
 func (s *TaskSrv) CreateTask(ctx context.Context, taskInfo models.CreateTask, userLogin string) error {
@@ -38,41 +93,91 @@ func (s *TaskSrv) CreateTask(ctx context.Context, taskInfo models.CreateTask, us
 		logs.Error(err)
 		return errors.New("you have no permissions to do this")
 	}
-	dateTimeStr := fmt.Sprintf("%sT%s:00Z", taskInfo.StartTime.StartDate, taskInfo.StartTime.StartTime)
+	newTask, err := s.PrepareTask(ctx, group, taskInfo)
+	if err != nil {
+		return err
+	}
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.AddTask(ctx, *newTask, taskInfo.GroupID)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.publishTaskChange(ctx, group, userLogin, nil, newTask.ID, taskCreated,
+			fmt.Sprintf("%s added the task %s", userLogin, newTask.Title))
+	})
+	if err != nil {
+		return err
+	}
+	s.Reminders.RescheduleReminders(ctx, taskInfo.GroupID, newTask.ID.Hex())
+	return nil
+}
 
-	startTime, err := time.Parse(dateformat, dateTimeStr)
+// PrepareTask runs all the checks of a new task against the current tasks of the group, nothing is saved
+func (s *TaskSrv) PrepareTask(ctx context.Context, group *models.Group, taskInfo models.CreateTask) (*models.Task, error) {
+	startTime, err := parseTaskStart(taskInfo.StartTime, group.Trip)
 	if err != nil {
 		logs.Error(err)
-		return fmt.Errorf("invalid date or time format: %v", err)
+		return nil, fmt.Errorf("invalid date or time format: %v", err)
 	}
 	now := time.Now().UTC()
 	if startTime.Before(now) {
-		return errors.New("you cant add tasks to past time")
+		return nil, errors.New("you cant add tasks to past time")
 	}
 	totalDuration := calculateDuration(taskInfo.Duration)
 	endTime := startTime.Add(time.Duration(totalDuration) * time.Minute)
 
+	if err := validateCoordinates(taskInfo.Location); err != nil {
+		return nil, err
+	}
 	newTask := models.Task{
 		Title:     taskInfo.Title,
 		StartTime: startTime,
 		Duration:  totalDuration,
 		EndTime:   endTime,
+		Location:  taskInfo.Location,
 	}
-	existingTasks, err := s.Task.GetTaskList(ctx, userLogin, taskInfo.GroupID)
+	if err := checkTripWindow(group.Trip, newTask); err != nil {
+		return nil, err
+	}
+	if taskInfo.Recurrence != "" {
+		newTask.Recurrence, err = newRecurrence(taskInfo.Recurrence, group.Trip)
+		if err != nil {
+			return nil, err
+		}
+	}
+	existingTasks, err := s.Task.GetTaskList(ctx, group.LeaderLogin, group.ID.Hex())
 	if err != nil {
 		logs.Error(err)
-		return errors.New("System error")
+		return nil, errors.New("System error")
 	}
 
-	for _, existingTask := range existingTasks {
-		if doTasksOverlap(existingTask, newTask) {
-			return fmt.Errorf("task overlaps with an existing task: %s", existingTask.Title)
-		}
+	newTask.ID = primitive.NewObjectID()
+	err = findOverlap(append(existingTasks, newTask), []primitive.ObjectID{newTask.ID}, group.Trip)
+	if err != nil {
+		return nil, err
 	}
-	err = s.Task.AddTask(ctx, newTask, taskInfo.GroupID)
+	return &newTask, nil
+}
+
+func newRecurrence(rule string, trip models.Trip) (*models.Recurrence, error) {
+	parsed, err := parseRRule(rule)
 	if err != nil {
-		logs.Error(err)
-		return errors.New("System error")
+		return nil, fmt.Errorf("invalid recurrence: %v", err)
+	}
+	if _, to := trip.Window(); to.IsZero() && !parsed.isBounded() {
+		return nil, errors.New("recurring task needs trip end date, COUNT or UNTIL")
+	}
+	return &models.Recurrence{Rule: parsed.String()}, nil
+}
+
+func checkTripWindow(trip models.Trip, task models.Task) error {
+	from, to := trip.Window()
+	if !from.IsZero() && task.StartTime.Before(from) {
+		return fmt.Errorf("task starts before the trip, trip starts on %s", trip.StartDate.Format(models.TripDateFormat))
+	}
+	if !to.IsZero() && task.EndTime.After(to) {
+		return fmt.Errorf("task ends after the trip, trip ends on %s", trip.EndDate.Format(models.TripDateFormat))
 	}
 	return nil
 }
@@ -98,38 +203,56 @@ func (s *TaskSrv) GetTaskList(ctx context.Context, groupID, userLogin string) ([
 	return tasks, nil
 }
 
-var dateformat string = "2006-01-02T15:04:05Z"
+const taskStartFormat = "2006-01-02 15:04"
+
+// parseTaskStart reads the start of a task as the user entered it, in the trip timezone
+// the itinerary and free slots are shown in
+func parseTaskStart(start models.StartTime, trip models.Trip) (time.Time, error) {
+	startTime, err := time.ParseInLocation(taskStartFormat, start.StartDate+" "+start.StartTime, trip.Location())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return startTime.UTC(), nil
+}
+
+// taskStartInput is the opposite of parseTaskStart, it turns a stored start back into the input of a task
+func taskStartInput(start time.Time, trip models.Trip) models.StartTime {
+	local := start.In(trip.Location())
+	return models.StartTime{
+		StartDate: local.Format(models.TripDateFormat),
+		StartTime: local.Format(clockFormat),
+	}
+}
 
-func (s *TaskSrv) UpdateTask(ctx context.Context, taskID, userLogin string, updateTask models.CreateTask) error {
+func (s *TaskSrv) UpdateTask(ctx context.Context, taskID, userLogin string, updateTask models.CreateTask) ([]models.Task, error) {
 	group, err := s.Group.GetGroup(ctx, updateTask.GroupID, userLogin)
 	if err != nil {
 		logs.Error(err)
-		return errors.New("failed to find group")
+		return nil, errors.New("failed to find group")
 	}
 	if group == nil{
-		return errors.New("group is not found, or you are not a member of it")
+		return nil, errors.New("group is not found, or you are not a member of it")
 	}
 	if group.LeaderLogin != userLogin {
 		logs.Error(err)
-		return errors.New("you have no permissions to do this")
+		return nil, errors.New("you have no permissions to do this")
 	}
 	task, err := s.Task.GetTaskById(ctx, taskID, updateTask.GroupID)
 	if err != nil {
 		logs.Error(err)
-		return errors.New("task was not found")
+		return nil, errors.New("task was not found")
 	}
 	var startTime time.Time
 	if !updateTask.StartTime.IsFullEmpty() {
-		dateTimeStr := fmt.Sprintf("%sT%s:00Z", updateTask.StartTime.StartDate, updateTask.StartTime.StartTime)
-		startTime, err = time.Parse(dateformat, dateTimeStr)
+		startTime, err = parseTaskStart(updateTask.StartTime, group.Trip)
 		if err != nil {
 			logs.Error(err)
-			return fmt.Errorf("invalid date or time format: %v", err)
+			return nil, fmt.Errorf("invalid date or time format: %v", err)
 		}
 		now := time.Now().UTC()
 		if startTime.Before(now) {
 			logs.Error(err)
-			return errors.New("you cant add tasks to past time")
+			return nil, errors.New("you cant add tasks to past time")
 		}
 	} else {
 		startTime = task.StartTime
@@ -149,32 +272,129 @@ func (s *TaskSrv) UpdateTask(ctx context.Context, taskID, userLogin string, upda
 		totalDuration = task.Duration
 		endTime = startTime.Add(time.Duration(totalDuration) * time.Minute)
 	}
+	if err := validateCoordinates(updateTask.Location); err != nil {
+		return nil, err
+	}
 	updates := models.Task{
 		Title:     updateTask.Title,
 		StartTime: startTime,
 		Duration:  totalDuration,
 		EndTime:   endTime,
+		Location:  updateTask.Location,
+	}
+	if startTimeProvided || durationProvided {
+		if err := checkTripWindow(group.Trip, updates); err != nil {
+			return nil, err
+		}
 	}
 	existingTasks, err := s.Task.GetTaskList(ctx, userLogin, updateTask.GroupID)
 	if err != nil {
 		logs.Error(err)
-		return errors.New("System error")
+		return nil, errors.New("System error")
 	}
 
+	movedTask := *task
+	var shifted []models.Task
+	if startTimeProvided || durationProvided {
+		movedTask.StartTime, movedTask.EndTime = updates.StartTime, updates.EndTime
+		shifted, err = shiftDependents(existingTasks, movedTask, updateTask.Cascade)
+		if err != nil {
+			return nil, err
+		}
+		for _, shiftedTask := range shifted {
+			if err := checkTripWindow(group.Trip, shiftedTask); err != nil {
+				return nil, fmt.Errorf("cant shift %s: %v", shiftedTask.Title, err)
+			}
+		}
+	}
+	if updateTask.Recurrence != "" {
+		if len(task.DependsOn) > 0 {
+			return nil, errors.New("tasks with dependencies cant be recurring")
+		}
+		recurrence, err := newRecurrence(updateTask.Recurrence, group.Trip)
+		if err != nil {
+			return nil, err
+		}
+		if movedTask.Recurrence != nil {
+			recurrence.Cancelled = movedTask.Recurrence.Cancelled
+			recurrence.Overrides = movedTask.Recurrence.Overrides
+		}
+		movedTask.Recurrence = recurrence
+		updates.Recurrence = recurrence
+	}
+	if startTimeProvided && task.Recurrence != nil {
+		// occurrences are known by their original start, so cancelled and edited ones move with the series,
+		// a task that only becomes recurring now has its rule anchored to the new start already
+		movedTask.Recurrence, err = shiftRecurrence(movedTask.Recurrence, task.Recurrence.Rule,
+			task.StartTime, movedTask.StartTime, group.Trip)
+		if err != nil {
+			return nil, err
+		}
+		updates.Recurrence = movedTask.Recurrence
+	}
+	changedIDs := []primitive.ObjectID{movedTask.ID}
+	for _, shiftedTask := range shifted {
+		changedIDs = append(changedIDs, shiftedTask.ID)
+	}
+	planned := make([]models.Task, 0, len(existingTasks))
 	for _, existingTask := range existingTasks {
-		if existingTask.ID.Hex() != taskID {
-			if doTasksOverlap(existingTask, updates) {
-				return fmt.Errorf("task overlaps with an existing task: %s", existingTask.Title)
+		if existingTask.ID == movedTask.ID {
+			existingTask = movedTask
+		}
+		for _, shiftedTask := range shifted {
+			if shiftedTask.ID == existingTask.ID {
+				existingTask = shiftedTask
 			}
 		}
+		planned = append(planned, existingTask)
 	}
-	err = s.Task.UpdateTask(ctx, taskID, updates)
+	err = findOverlap(planned, changedIDs, group.Trip)
 	if err != nil {
-		logs.Error(err)
-		return errors.New("System error")
+		return nil, err
+	}
+	title := task.Title
+	if updates.Title != "" {
+		title = updates.Title
+	}
+	text := fmt.Sprintf("%s changed the task %s", userLogin, title)
+	if len(shifted) > 0 {
+		text += fmt.Sprintf(", %d dependent tasks were moved", len(shifted))
+	}
+	// the task and the tasks depending on it move together or not at all
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.UpdateTask(ctx, taskID, updates)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		for _, shiftedTask := range shifted {
+			err = s.Task.UpdateTask(ctx, shiftedTask.ID.Hex(), models.Task{StartTime: shiftedTask.StartTime, EndTime: shiftedTask.EndTime})
+			if err != nil {
+				logs.Error(err)
+				return errors.New("System error")
+			}
+			for _, existingTask := range existingTasks {
+				if existingTask.ID != shiftedTask.ID {
+					continue
+				}
+				err = s.recordTaskChange(ctx, group, userLogin, &existingTask,
+					fmt.Sprintf("%s moved the task %s together with %s", userLogin, shiftedTask.Title, title))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return s.publishTaskChange(ctx, group, userLogin, task, task.ID, taskUpdated, text)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Reminders.RescheduleReminders(ctx, updateTask.GroupID, taskID)
+	for _, shiftedTask := range shifted {
+		s.Reminders.RescheduleReminders(ctx, updateTask.GroupID, shiftedTask.ID.Hex())
 	}
 
-	return nil
+	return shifted, nil
 }
 
 const (
@@ -198,10 +418,28 @@ func (s *TaskSrv) DeleteTask(ctx context.Context, taskID, groupID, userLogin str
 	if group.LeaderLogin != userLogin {
 		return errors.New("you have no permissions to do this")
 	}
-	err = s.Task.DeleteTask(ctx, taskID)
+	task, err := s.Task.GetTaskById(ctx, taskID, groupID)
 	if err != nil {
 		logs.Error(err)
-		return errors.New("System error")
+		return errors.New("task was not found")
+	}
+	err = inTransaction(ctx, s.Tx, func(ctx context.Context) error {
+		err := s.Task.DeleteTask(ctx, taskID)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		err = s.Task.RemoveDependenciesOn(ctx, taskID)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("System error")
+		}
+		return s.publishTaskChange(ctx, group, userLogin, task, task.ID, taskDeleted,
+			fmt.Sprintf("%s deleted the task %s", userLogin, task.Title))
+	})
+	if err != nil {
+		return err
 	}
+	s.Reminders.CancelReminders(ctx, taskID)
 	return nil
 }
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func TestUpdateTaskRecurrenceWithStart(t *testing.T) {
+	SetLogger(zap.NewNop())
+	trip := models.Trip{Timezone: "Europe/Rome"}
+	year := time.Now().Year() + 1
+	at := func(day, hour int) time.Time {
+		return time.Date(year, time.March, day, hour, 0, 0, 0, trip.Location()).UTC()
+	}
+	tests := []struct {
+		name          string
+		recurrence    *models.Recurrence
+		update        string
+		wantRule      string
+		wantCancelled []time.Time
+	}{
+		{name: "one-off task becomes recurring", update: "FREQ=DAILY;COUNT=3", wantRule: "FREQ=DAILY;COUNT=3"},
+		{name: "series moves with its cancelled occurrences",
+			recurrence: &models.Recurrence{Rule: "FREQ=DAILY;COUNT=3", Cancelled: []time.Time{at(11, 9)}},
+			wantRule:   "FREQ=DAILY;COUNT=3", wantCancelled: []time.Time{at(11, 11)}},
+		{name: "series gets a new rule and moves",
+			recurrence: &models.Recurrence{Rule: "FREQ=DAILY;COUNT=3", Cancelled: []time.Time{at(11, 9)}},
+			update:     "FREQ=DAILY;COUNT=5", wantRule: "FREQ=DAILY;COUNT=5", wantCancelled: []time.Time{at(11, 11)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &models.Group{ID: primitive.NewObjectID(), LeaderLogin: "alice123", Members: []string{"alice123"}, Trip: trip}
+			task := models.Task{ID: primitive.NewObjectID(), GroupID: group.ID, Title: "breakfast",
+				StartTime: at(10, 9), EndTime: at(10, 10), Duration: 60, Recurrence: tt.recurrence}
+			tasks := &fakeTasks{tasks: map[string]models.Task{task.ID.Hex(): task}}
+			s := NewTaskSrv(tasks, &fakeGroups{groups: map[string]*models.Group{group.ID.Hex(): group}}, TravelSpeeds{},
+				fakeReminders{}, fakeRevisions{}, &fakeEvents{}, fakeTx{})
+
+			_, err := s.UpdateTask(context.Background(), task.ID.Hex(), "alice123", models.CreateTask{
+				GroupID:    group.ID.Hex(),
+				StartTime:  taskStartInput(at(10, 11), trip),
+				Recurrence: tt.update,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			updated := tasks.updates[task.ID.Hex()]
+			if updated.Recurrence == nil || updated.Recurrence.Rule != tt.wantRule {
+				t.Fatalf("recurrence = %+v, want rule %s", updated.Recurrence, tt.wantRule)
+			}
+			if !slices.EqualFunc(updated.Recurrence.Cancelled, tt.wantCancelled, time.Time.Equal) {
+				t.Errorf("cancelled = %v, want %v", updated.Recurrence.Cancelled, tt.wantCancelled)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	earthRadiusKm     = 6371.0
+	defaultTravelMode = "car"
+)
+
+// TravelSpeeds are average speeds in km/h per travel mode
+type TravelSpeeds map[string]float64
+
+var DefaultTravelSpeeds = TravelSpeeds{
+	"walk":    5,
+	"bike":    15,
+	"transit": 25,
+	"car":     60,
+	"train":   100,
+}
+
+// NewTravelSpeedsFromEnv reads TRAVEL_SPEEDS like "walk=4.5,car=50", unknown modes are added
+func NewTravelSpeedsFromEnv() (TravelSpeeds, error) {
+	speeds := TravelSpeeds{}
+	for mode, speed := range DefaultTravelSpeeds {
+		speeds[mode] = speed
+	}
+	config := strings.TrimSpace(os.Getenv("TRAVEL_SPEEDS"))
+	if config == "" {
+		return speeds, nil
+	}
+	for _, pair := range strings.Split(config, ",") {
+		mode, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid TRAVEL_SPEEDS entry: %q", pair)
+		}
+		speed, err := strconv.ParseFloat(value, 64)
+		if err != nil || speed <= 0 {
+			return nil, fmt.Errorf("invalid speed for %s: %q", mode, value)
+		}
+		speeds[strings.ToLower(mode)] = speed
+	}
+	return speeds, nil
+}
+
+// distanceKm is the great-circle distance by the haversine formula
+func distanceKm(from, to *models.Location) float64 {
+	lat1, lat2 := *from.Lat*math.Pi/180, *to.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (*to.Lon - *from.Lon) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// travelMinutes is how long the distance takes at the speed, rounded up
+func travelMinutes(distance, speed float64) int {
+	return int(math.Ceil(distance / speed * MinutesInHour))
+}
+
+// travelGap is the time to keep between two places, the travel time but at least the buffer.
+// Without coordinates of both places it is the buffer
+func travelGap(from, to *models.Location, buffer time.Duration, speed float64) time.Duration {
+	if !from.HasCoordinates() || !to.HasCoordinates() {
+		return buffer
+	}
+	return max(buffer, time.Duration(travelMinutes(distanceKm(from, to), speed))*time.Minute)
+}
+
+// travelBetween estimates travel to every task from the previous task that has coordinates.
+// tasks must be sorted by start time.
+func travelBetween(tasks []models.Task, mode string, speed float64) map[string]*models.Travel {
+	travels := make(map[string]*models.Travel)
+	var previous *models.Task
+	for i := range tasks {
+		task := &tasks[i]
+		if !task.Location.HasCoordinates() || task.CurrentStatus() == models.TaskCancelled {
+			continue
+		}
+		if previous != nil {
+			distance := distanceKm(previous.Location, task.Location)
+			minutes := travelMinutes(distance, speed)
+			available := int(task.StartTime.Sub(previous.EndTime) / time.Minute)
+			travels[taskKey(*task)] = &models.Travel{
+				FromTaskID:       previous.ID,
+				DistanceKm:       math.Round(distance*10) / 10,
+				Mode:             mode,
+				Minutes:          minutes,
+				AvailableMinutes: available,
+				Impossible:       minutes > available,
+			}
+		}
+		previous = task
+	}
+	return travels
+}
+
+func validateCoordinates(location *models.Location) error {
+	if location == nil {
+		return nil
+	}
+	if (location.Lat == nil) != (location.Lon == nil) {
+		return errors.New("both lat and lon are needed")
+	}
+	if !location.HasCoordinates() {
+		return nil
+	}
+	for _, coordinate := range []float64{*location.Lat, *location.Lon} {
+		if math.IsNaN(coordinate) || math.IsInf(coordinate, 0) {
+			return errors.New("coordinates must be finite numbers")
+		}
+	}
+	if math.Abs(*location.Lat) > 90 || math.Abs(*location.Lon) > 180 {
+		return errors.New("coordinates are out of range")
+	}
+	return nil
+}
@@ -2,10 +2,15 @@ package service
 
 import (
 	"JourneyPlanner/internal/models"
+	"JourneyPlanner/pkg/mail"
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"os"
 	"regexp"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -15,14 +20,72 @@ type UserRepository interface {
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByLogin(ctx context.Context, login string) (*models.User, error)
 	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	GetUserByOIDC(ctx context.Context, issuer, subject string) (*models.User, error)
+	LinkOIDCIdentity(ctx context.Context, login, issuer, subject string) error
+	UpdateProfile(ctx context.Context, login string, profile models.UpdateProfile) error
+	SetPendingEmail(ctx context.Context, login, email, tokenHash string, expires time.Time) error
+	GetUserByEmailToken(ctx context.Context, tokenHash string) (*models.User, error)
+	ConfirmEmail(ctx context.Context, login, email string) error
+	RevokeSessions(ctx context.Context, login string, revokedAt time.Time) error
+	SetDeleteToken(ctx context.Context, login, tokenHash string, expires time.Time) error
+	GetUserByDeleteToken(ctx context.Context, tokenHash string) (*models.User, error)
+	ReserveLogin(ctx context.Context, login string, deletedAt time.Time) error
+	IsLoginReserved(ctx context.Context, login string) (bool, error)
+	DeleteUser(ctx context.Context, login string) error
+	UpdatePrivacy(ctx context.Context, login string, privacy models.Privacy) error
+	SearchUsers(ctx context.Context, search models.UserSearch) ([]models.User, error)
+	GetUsersByLogins(ctx context.Context, logins []string) ([]models.User, error)
+}
+
+type TokenRepository interface {
+	RevokeToken(ctx context.Context, token models.RevokedToken) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	PurgeRevokedTokens(ctx context.Context, expiredBefore time.Time) (int64, error)
+}
+
+type LoginAuditRepository interface {
+	AddLoginAttempt(ctx context.Context, attempt models.LoginAttempt) error
+	GetLoginAttempts(ctx context.Context, login string, limit int64) ([]models.LoginAttempt, error)
+	DeleteLoginAttempts(ctx context.Context, login string) error
+}
+
+// LoginProtection slows down password guessing per account and per ip
+type LoginProtection struct {
+	Account LoginLimiter
+	IP      LoginLimiter
+	Audit   LoginAuditRepository
+}
+
+type GroupLeaver interface {
+	LeaveGroup(ctx context.Context, groupID, userLogin string) error
 }
 
 type UserSrv struct {
-	User UserRepository
+	User      UserRepository
+	Token     TokenRepository
+	Group     GroupRepository
+	Groups    GroupLeaver
+	Invite    InviteRepository
+	BlackList BlackListRepository
+	APIKey    APIKeyRepository
+	Contact   ContactRepository
+	Mailer    mail.Mailer
+	Login     LoginProtection
+	// the personal data of the user, it is removed with the account
+	Notification   NotificationRepository
+	NotifySettings NotificationSettingsRepository
+	Reminder       ReminderRepository
 }
 
-func NewUserSrv(userRepo UserRepository) *UserSrv {
-	return &UserSrv{User: userRepo}
+func NewUserSrv(userRepo UserRepository, tokenRepo TokenRepository, groupRepo GroupRepository,
+	groups GroupLeaver, inviteRepo InviteRepository, blackList BlackListRepository,
+	apiKeyRepo APIKeyRepository, contactRepo ContactRepository, mailer mail.Mailer,
+	loginProtection LoginProtection, notificationRepo NotificationRepository,
+	notifySettingsRepo NotificationSettingsRepository, reminderRepo ReminderRepository) *UserSrv {
+	return &UserSrv{User: userRepo, Token: tokenRepo, Group: groupRepo, Groups: groups,
+		Invite: inviteRepo, BlackList: blackList, APIKey: apiKeyRepo, Contact: contactRepo, Mailer: mailer,
+		Login: loginProtection, Notification: notificationRepo, NotifySettings: notifySettingsRepo,
+		Reminder: reminderRepo}
 }
 
 func (s *UserSrv) RegisterUser(ctx context.Context, user models.SignUp) error {
@@ -52,7 +115,12 @@ func (s *UserSrv) RegisterUser(ctx context.Context, user models.SignUp) error {
 	return nil
 }
 
-func (s *UserSrv) LoginUser(ctx context.Context, option, password string) (string, error) {
+func (s *UserSrv) LoginUser(ctx context.Context, option, password, ip string) (string, error) {
+	attempt := models.LoginAttempt{
+		Option: option,
+		IP:     ip,
+		Time:   time.Now().UTC(),
+	}
 	var user *models.User
 	var err error
 	if s.isValidEmail(option) {
@@ -60,18 +128,47 @@ func (s *UserSrv) LoginUser(ctx context.Context, option, password string) (strin
 	} else {
 		user, err = s.User.GetUserByLogin(ctx, option)
 	}
+	// unknown accounts are counted too, so they can't be told apart from existing ones
+	accountKey := "account:" + strings.ToLower(option)
+	if err == nil && user != nil {
+		accountKey = "account:" + user.Login
+		attempt.Login = user.Login
+	}
+	ipKey := "ip:" + ip
+
+	retryAfter, err := s.reserveLoginAttempt(ctx, accountKey, ipKey)
 	if err != nil {
 		logs.Error(err)
-		return "", errors.New("invalid credentials")
+		return "", errors.New("System error")
+	}
+	if retryAfter > 0 {
+		attempt.Reason = models.LoginFailLocked
+		s.auditLoginFailure(ctx, attempt)
+		return "", &LoginLockedError{RetryAfter: retryAfter}
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if user == nil {
+		// the password is compared anyway, so the response time doesn't tell whether the login exists
+		_ = comparePassword("", password)
+		attempt.Reason = models.LoginFailUnknownUser
+		s.auditLoginFailure(ctx, attempt)
+		return "", errors.New("invalid credentials")
+	}
+	err = comparePassword(user.PasswordHash, password)
 	if err != nil {
 		logs.Error(err)
+		attempt.Reason = models.LoginFailInvalidPassword
+		s.auditLoginFailure(ctx, attempt)
 		return "", errors.New("invalid credentials")
 	}
+	if err := s.Login.Account.Reset(ctx, accountKey); err != nil {
+		logs.Error(err)
+	}
+	if err := s.Login.IP.Forgive(ctx, ipKey); err != nil {
+		logs.Error(err)
+	}
 
-	token, err := s.GeneratePasetoToken(user.Login)
+	token, err := s.GeneratePasetoToken(user)
 	if err != nil {
 		logs.Error(err)
 		return "", fmt.Errorf("error during generating token: %v", err)
@@ -80,6 +177,51 @@ func (s *UserSrv) LoginUser(ctx context.Context, option, password string) (strin
 	return token, nil
 }
 
+// reserveLoginAttempt counts the attempt as failed before the password is checked, it returns
+// for how long the account or ip is blocked. A blocked attempt is not counted
+func (s *UserSrv) reserveLoginAttempt(ctx context.Context, accountKey, ipKey string) (time.Duration, error) {
+	accountBlock, err := s.Login.Account.Attempt(ctx, accountKey)
+	if err != nil || accountBlock > 0 {
+		return accountBlock, err
+	}
+	ipBlock, err := s.Login.IP.Attempt(ctx, ipKey)
+	if err != nil || ipBlock > 0 {
+		if err := s.Login.Account.Forgive(ctx, accountKey); err != nil {
+			logs.Error(err)
+		}
+		return ipBlock, err
+	}
+	return 0, nil
+}
+
+// dummyPasswordHash is compared for unknown users and accounts without a password
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("journey planner dummy password"), bcrypt.DefaultCost)
+
+func comparePassword(hash, password string) error {
+	if hash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return errors.New("account has no password")
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
+
+func (s *UserSrv) auditLoginFailure(ctx context.Context, attempt models.LoginAttempt) {
+	if err := s.Login.Audit.AddLoginAttempt(ctx, attempt); err != nil {
+		logs.Error(err)
+	}
+}
+
+const maxLoginAttemptsShown = 50
+
+func (s *UserSrv) GetFailedLogins(ctx context.Context, userLogin string) ([]models.LoginAttempt, error) {
+	attempts, err := s.Login.Audit.GetLoginAttempts(ctx, userLogin, maxLoginAttemptsShown)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return attempts, nil
+}
+
 const emailRegex = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
 
 func (s *UserSrv) isValidEmail(email string) bool {
@@ -92,9 +234,249 @@ func (s *UserSrv) duplicateCheck(ctx context.Context, user models.SignUp) error
 		logs.Error(err)
 		return errors.New("this login is already registered")
 	}
-	if _, err := s.User.GetUserByEmail(ctx, user.Email); err == nil {
+	existing, err := s.User.GetUserByEmail(ctx, user.Email)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if existing != nil {
+		return errors.New("this email is already registered")
+	}
+	return checkLoginReserved(ctx, s.User, user.Login)
+}
+
+// checkLoginReserved rejects logins of deleted accounts, the data of other users can still refer to them
+func checkLoginReserved(ctx context.Context, users UserRepository, login string) error {
+	reserved, err := users.IsLoginReserved(ctx, login)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if reserved {
+		return errors.New("this login is already registered")
+	}
+	return nil
+}
+
+const emailTokenTTL = HoursInDay * time.Hour
+
+func (s *UserSrv) GetProfile(ctx context.Context, userLogin string) (*models.User, error) {
+	user, err := s.User.GetUserByLogin(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func (s *UserSrv) GetPublicProfile(ctx context.Context, login string) (*models.PublicProfile, error) {
+	user, err := s.User.GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return &models.PublicProfile{Login: user.Login, Profile: user.Profile}, nil
+}
+
+func (s *UserSrv) UpdateProfile(ctx context.Context, userLogin string, profile models.UpdateProfile) error {
+	if profile.Timezone != nil && *profile.Timezone != "" {
+		if _, err := time.LoadLocation(*profile.Timezone); err != nil {
+			return errors.New("unknown timezone")
+		}
+	}
+	if profile.AvatarURL != nil && *profile.AvatarURL != "" {
+		avatar, err := url.Parse(*profile.AvatarURL)
+		if err != nil || (avatar.Scheme != "https" && avatar.Scheme != "http") {
+			return errors.New("avatar must be http or https link")
+		}
+	}
+	err := s.User.UpdateProfile(ctx, userLogin, profile)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+func (s *UserSrv) ChangeEmail(ctx context.Context, userLogin, newEmail, password string) error {
+	if !s.isValidEmail(newEmail) {
+		return errors.New("invalid email")
+	}
+	user, err := s.User.GetUserByLogin(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("user not found")
+	}
+	if err := s.checkPassword(user, password); err != nil {
+		return err
+	}
+	// an unverified email can be sent again to confirm it
+	if user.Email == newEmail && user.EmailVerified {
+		return errors.New("this is already your email")
+	}
+	existing, err := s.User.GetUserByEmail(ctx, newEmail)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	if existing != nil && existing.Login != user.Login {
+		return errors.New("this email is already registered")
+	}
+	token, err := randomURLString()
+	if err != nil {
+		logs.Error(err)
+		return errors.New("unfortunately we were unable to process your request, please try again later")
+	}
+	err = s.User.SetPendingEmail(ctx, userLogin, newEmail, hashAPIKey(token), time.Now().UTC().Add(emailTokenTTL))
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	link := fmt.Sprintf("%s/users/verifyemail?token=%s", appURL(), url.QueryEscape(token))
+	body := fmt.Sprintf("Hi %s,\n\nconfirm your new email for Journey Planner by opening the link:\n%s\n\n"+
+		"The link is valid for 24 hours. If you did not request this, ignore this email.", user.Login, link)
+	err = s.Mailer.Send(ctx, newEmail, "Confirm your new email", body)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to send verification email, please try later")
+	}
+	return nil
+}
+
+func (s *UserSrv) VerifyEmail(ctx context.Context, token string) error {
+	user, err := s.User.GetUserByEmailToken(ctx, hashAPIKey(token))
+	if err != nil {
+		logs.Error(err)
+		return errors.New("verification link is expired or invalid")
+	}
+	existing, err := s.User.GetUserByEmail(ctx, user.PendingEmail)
+	if err != nil {
 		logs.Error(err)
+		return errors.New("System error")
+	}
+	if existing != nil && existing.Login != user.Login {
 		return errors.New("this email is already registered")
 	}
+	err = s.User.ConfirmEmail(ctx, user.Login, user.PendingEmail)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+const deleteTokenTTL = time.Hour
+
+// DeleteAccount deletes the account of the user after checking the password. Accounts created with OIDC
+// have no password, they get an email with a link instead, and the account is deleted when it is opened.
+// It returns true when the link is sent
+func (s *UserSrv) DeleteAccount(ctx context.Context, userLogin, password string) (bool, error) {
+	user, err := s.User.GetUserByLogin(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return false, errors.New("user not found")
+	}
+	if user.PasswordHash == "" {
+		return true, s.sendDeleteConfirmation(ctx, user)
+	}
+	if err := s.checkPassword(user, password); err != nil {
+		return false, err
+	}
+	return false, s.deleteAccount(ctx, userLogin)
+}
+
+func (s *UserSrv) sendDeleteConfirmation(ctx context.Context, user *models.User) error {
+	token, err := randomURLString()
+	if err != nil {
+		logs.Error(err)
+		return errors.New("unfortunately we were unable to process your request, please try again later")
+	}
+	err = s.User.SetDeleteToken(ctx, user.Login, hashAPIKey(token), time.Now().UTC().Add(deleteTokenTTL))
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	link := fmt.Sprintf("%s/users/confirmdelete?token=%s", appURL(), url.QueryEscape(token))
+	body := fmt.Sprintf("Hi %s,\n\nconfirm deleting your Journey Planner account by opening the link:\n%s\n\n"+
+		"The link is valid for 1 hour. If you did not request this, ignore this email.", user.Login, link)
+	err = s.Mailer.Send(ctx, user.Email, "Confirm deleting your account", body)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to send confirmation email, please try later")
+	}
+	return nil
+}
+
+func (s *UserSrv) ConfirmAccountDeletion(ctx context.Context, token string) error {
+	user, err := s.User.GetUserByDeleteToken(ctx, hashAPIKey(token))
+	if err != nil {
+		logs.Error(err)
+		return errors.New("confirmation link is expired or invalid")
+	}
+	return s.deleteAccount(ctx, user.Login)
+}
+
+// deleteAccount removes the user and everything kept for the login. The login itself stays reserved and
+// the bans of the user stay, so a new account with the same login can't take over what the old one had
+func (s *UserSrv) deleteAccount(ctx context.Context, userLogin string) error {
+	groups, err := s.Group.GetGroupList(ctx, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("failed to delete account, please try later")
+	}
+	// leaving the same way as LeaveGroup does hands off leadership and deletes groups left empty
+	for _, group := range groups {
+		err = s.Groups.LeaveGroup(ctx, group.ID.Hex(), userLogin)
+		if err != nil {
+			logs.Error(err)
+			return errors.New("failed to delete account, please try later")
+		}
+	}
+	purges := []func(ctx context.Context, userLogin string) error{
+		s.Invite.InvalidateUserInvites,
+		s.APIKey.DeleteUserAPIKeys,
+		s.Contact.DeleteUserContacts,
+		s.Notification.DeleteUserNotifications,
+		s.NotifySettings.DeleteSettings,
+		s.Reminder.DeleteUserOverrides,
+		s.Login.Audit.DeleteLoginAttempts,
+		func(ctx context.Context, userLogin string) error {
+			return s.Login.Account.Reset(ctx, "account:"+userLogin)
+		},
+	}
+	for _, purge := range purges {
+		if err := purge(ctx, userLogin); err != nil {
+			logs.Error(err)
+			return errors.New("failed to delete account, please try later")
+		}
+	}
+	if err := s.User.ReserveLogin(ctx, userLogin, time.Now().UTC()); err != nil {
+		logs.Error(err)
+		return errors.New("failed to delete account, please try later")
+	}
+	// sessions are rejected as soon as the user record is gone
+	if err := s.User.DeleteUser(ctx, userLogin); err != nil {
+		logs.Error(err)
+		return errors.New("failed to delete account, please try later")
+	}
 	return nil
 }
+
+// checkPassword confirms a change with the password, accounts created with OIDC have no password
+// and are confirmed by the session itself
+func (s *UserSrv) checkPassword(user *models.User, password string) error {
+	if user.PasswordHash == "" {
+		return nil
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		return errors.New("invalid password")
+	}
+	return nil
+}
+
+func appURL() string {
+	if u := os.Getenv("APP_URL"); u != "" {
+		return u
+	}
+	return "http://localhost:8080"
+}
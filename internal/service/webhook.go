@@ -0,0 +1,400 @@
+package service
+
+import (
+	"JourneyPlanner/internal/models"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxGroupWebhooks    = 10
+	maxWebhookURL       = 2048
+	webhookSecretBytes  = 32
+	webhookTimeout      = 10 * time.Second
+	webhookDeliveryList = 50
+	// webhookRequestFailed is stored instead of the error of the request, which could tell about the inner network
+	webhookRequestFailed = "webhook request failed"
+)
+
+var errWebhookAddress = errors.New("webhook address is not allowed")
+
+type WebhookRepository interface {
+	AddWebhook(ctx context.Context, webhook models.Webhook) error
+	GetWebhooks(ctx context.Context, groupID string) ([]models.Webhook, error)
+	GetEventWebhooks(ctx context.Context, groupID, event string) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	AddDelivery(ctx context.Context, delivery models.WebhookDelivery) (*models.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, deliveryID string) (*models.WebhookDelivery, error)
+	AddDeliveryAttempt(ctx context.Context, deliveryID primitive.ObjectID, attempt models.WebhookAttempt, status string) error
+	GetDeliveries(ctx context.Context, webhookID string, limit int64) ([]models.WebhookDelivery, error)
+}
+
+// WebhookSrv lets leaders subscribe URLs to group events. Every event is saved as a delivery
+// and sent by a job, so failed requests are retried with the backoff of the scheduler
+type WebhookSrv struct {
+	Webhook WebhookRepository
+	Group   GroupRepository
+	Jobs    JobQueue
+	Client  *http.Client
+	// MaxAttempts is the number of attempts of the scheduler, after the last one the delivery is failed
+	MaxAttempts int
+}
+
+func NewWebhookSrv(webhookRepo WebhookRepository, groupRepo GroupRepository, jobs JobQueue, maxAttempts int) *WebhookSrv {
+	return &WebhookSrv{Webhook: webhookRepo, Group: groupRepo, Jobs: jobs,
+		Client: newWebhookClient(), MaxAttempts: maxAttempts}
+}
+
+// newWebhookClient checks every address right before connecting, so a name that resolves
+// to the inner network, even after the url was checked, is not reached. There is no proxy
+// as it would connect instead of us, and redirects are not followed
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: webhookDialControl}
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isPublicAddr(addr) {
+		return errWebhookAddress
+	}
+	return nil
+}
+
+// isPublicAddr is false for loopback, private, link-local, multicast and unspecified addresses
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && addr.IsGlobalUnicast() && !addr.IsPrivate() && !addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() && !addr.IsUnspecified()
+}
+
+func (s *WebhookSrv) getLeaderGroup(ctx context.Context, groupID, userLogin string) (*models.Group, error) {
+	group, err := s.Group.GetGroup(ctx, groupID, userLogin)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("failed to find group")
+	}
+	if group == nil {
+		return nil, errors.New("group is not found, or you are not a member of it")
+	}
+	if group.LeaderLogin != userLogin {
+		return nil, errors.New("you have no permissions to do this")
+	}
+	return group, nil
+}
+
+func checkWebhookURL(rawURL string) error {
+	if len(rawURL) > maxWebhookURL {
+		return errors.New("url is too long")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("url must be an absolute http or https url")
+	}
+	host := parsed.Hostname()
+	if addr, err := netip.ParseAddr(host); (err == nil && !isPublicAddr(addr)) || host == "localhost" {
+		return errors.New("url must point to a public address")
+	}
+	return nil
+}
+
+// AddWebhook registers the url for the events, the secret to check signatures is returned only here
+func (s *WebhookSrv) AddWebhook(ctx context.Context, groupID, userLogin, rawURL string, events []string) (*models.CreatedWebhook, error) {
+	if err := checkWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, errors.New("choose at least one event")
+	}
+	for _, event := range events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			return nil, fmt.Errorf("unknown event %q", event)
+		}
+	}
+	group, err := s.getLeaderGroup(ctx, groupID, userLogin)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := s.Webhook.GetWebhooks(ctx, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	if len(webhooks) >= maxGroupWebhooks {
+		return nil, fmt.Errorf("group can have at most %d webhooks", maxGroupWebhooks)
+	}
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	slices.Sort(events)
+	webhook := models.Webhook{
+		ID:        primitive.NewObjectID(),
+		GroupID:   group.ID.Hex(),
+		URL:       rawURL,
+		Events:    slices.Compact(events),
+		Secret:    hex.EncodeToString(secret),
+		CreatedBy: userLogin,
+		CreatedAt: time.Now().UTC(),
+	}
+	err = s.Webhook.AddWebhook(ctx, webhook)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return &models.CreatedWebhook{Webhook: webhook, Secret: webhook.Secret}, nil
+}
+
+func (s *WebhookSrv) GetWebhooks(ctx context.Context, groupID, userLogin string) ([]models.Webhook, error) {
+	if _, err := s.getLeaderGroup(ctx, groupID, userLogin); err != nil {
+		return nil, err
+	}
+	webhooks, err := s.Webhook.GetWebhooks(ctx, groupID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return webhooks, nil
+}
+
+func (s *WebhookSrv) getGroupWebhook(ctx context.Context, groupID, webhookID, userLogin string) (*models.Webhook, error) {
+	if _, err := s.getLeaderGroup(ctx, groupID, userLogin); err != nil {
+		return nil, err
+	}
+	webhook, err := s.Webhook.GetWebhook(ctx, webhookID)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	if webhook == nil || webhook.GroupID != groupID {
+		return nil, errors.New("webhook is not found")
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook stops the deliveries, the ones still being retried are dropped
+func (s *WebhookSrv) DeleteWebhook(ctx context.Context, groupID, webhookID, userLogin string) error {
+	if _, err := s.getGroupWebhook(ctx, groupID, webhookID, userLogin); err != nil {
+		return err
+	}
+	err := s.Webhook.DeleteWebhook(ctx, webhookID)
+	if err != nil {
+		logs.Error(err)
+		return errors.New("System error")
+	}
+	return nil
+}
+
+// GetDeliveries returns the latest deliveries of the webhook with all their attempts
+func (s *WebhookSrv) GetDeliveries(ctx context.Context, groupID, webhookID, userLogin string) ([]models.WebhookDelivery, error) {
+	if _, err := s.getGroupWebhook(ctx, groupID, webhookID, userLogin); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.Webhook.GetDeliveries(ctx, webhookID, webhookDeliveryList)
+	if err != nil {
+		logs.Error(err)
+		return nil, errors.New("System error")
+	}
+	return deliveries, nil
+}
+
+func webhookDeliveryJobKey(deliveryID string) string {
+	return models.JobWebhookDelivery + ":" + deliveryID
+}
+
+// Subscribe sends the group events to the webhooks
+func (s *WebhookSrv) Subscribe(bus *EventBus) {
+	Subscribe(bus, func(ctx context.Context, event models.MemberJoined) {
+		s.publish(ctx, event.Group.ID.Hex(), models.WebhookMemberJoined, map[string]string{"login": event.Login})
+	})
+	Subscribe(bus, func(ctx context.Context, event models.MemberLeft) {
+		s.publish(ctx, event.Group.ID.Hex(), models.WebhookMemberLeft,
+			map[string]string{"login": event.Login, "leader": event.NewLeader})
+	})
+	Subscribe(bus, func(ctx context.Context, event models.MemberBanned) {
+		s.publish(ctx, event.Group.ID.Hex(), models.WebhookMemberBanned,
+			map[string]string{"login": event.Login, "actor": event.Actor})
+	})
+	Subscribe(bus, func(ctx context.Context, event models.TaskCreated) {
+		s.publishTaskChange(ctx, models.WebhookTaskCreated, event.TaskChange)
+	})
+	Subscribe(bus, func(ctx context.Context, event models.TaskUpdated) {
+		s.publishTaskChange(ctx, models.WebhookTaskUpdated, event.TaskChange)
+	})
+	Subscribe(bus, func(ctx context.Context, event models.TaskDeleted) {
+		s.publishTaskChange(ctx, models.WebhookTaskDeleted, event.TaskChange)
+	})
+	Subscribe(bus, func(ctx context.Context, event models.PollCreated) {
+		s.publish(ctx, event.Group.ID.Hex(), models.WebhookPollCreated, event.Poll)
+	})
+	Subscribe(bus, func(ctx context.Context, event models.PollClosed) {
+		s.publish(ctx, event.Poll.GroupID.Hex(), models.WebhookPollClosed,
+			map[string]interface{}{"poll_id": event.Poll.ID.Hex(), "title": event.Poll.Title, "result": event.Result})
+	})
+	Subscribe(bus, func(ctx context.Context, event models.MessagePosted) {
+		s.publish(ctx, event.Message.GroupID, models.WebhookMessagePosted, event.Message)
+	})
+}
+
+func (s *WebhookSrv) publishTaskChange(ctx context.Context, webhookEvent string, change models.TaskChange) {
+	s.publish(ctx, change.Group.ID.Hex(), webhookEvent,
+		map[string]string{"task_id": change.TaskID.Hex(), "title": change.Title, "actor": change.Actor})
+}
+
+// publish saves a delivery for every webhook subscribed to the event, errors are only logged
+// as the event itself already happened. Deliveries are keyed by the event and the webhook,
+// so an event dispatched again reuses its delivery and only schedules it if it was never tried
+func (s *WebhookSrv) publish(ctx context.Context, groupID, event string, data interface{}) {
+	webhooks, err := s.Webhook.GetEventWebhooks(ctx, groupID, event)
+	if err != nil {
+		logs.Error(err)
+		return
+	}
+	key := eventKey(ctx)
+	now := time.Now().UTC()
+	for _, webhook := range webhooks {
+		delivery := models.WebhookDelivery{
+			ID:        primitive.NewObjectID(),
+			WebhookID: webhook.ID.Hex(),
+			GroupID:   groupID,
+			Event:     event,
+			Status:    models.DeliveryPending,
+			Attempts:  []models.WebhookAttempt{},
+			CreatedAt: now,
+		}
+		if key != "" {
+			delivery.Key = key + ":" + webhook.ID.Hex()
+		}
+		payload, err := json.Marshal(models.WebhookPayload{
+			ID:        delivery.ID.Hex(),
+			Event:     event,
+			GroupID:   groupID,
+			CreatedAt: now,
+			Data:      data,
+		})
+		if err != nil {
+			logs.Error(err)
+			return
+		}
+		delivery.Payload = string(payload)
+		saved, err := s.Webhook.AddDelivery(ctx, delivery)
+		if err != nil {
+			logs.Error(err)
+			continue
+		}
+		if saved.Status != models.DeliveryPending || len(saved.Attempts) > 0 {
+			continue
+		}
+		err = s.Jobs.Schedule(ctx, models.JobWebhookDelivery, webhookDeliveryJobKey(saved.ID.Hex()), now,
+			map[string]string{"delivery_id": saved.ID.Hex()})
+		if err != nil {
+			logs.Error(err)
+		}
+	}
+}
+
+// signWebhook signs "timestamp.body", the timestamp lets receivers reject old requests
+func signWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliverWebhook is the job that sends one delivery, any answer but 2xx is retried
+func (s *WebhookSrv) DeliverWebhook(ctx context.Context, job models.Job) error {
+	delivery, err := s.Webhook.GetDelivery(ctx, job.Payload["delivery_id"])
+	if err != nil {
+		return err
+	}
+	if delivery == nil || delivery.Status != models.DeliveryPending {
+		return nil
+	}
+	webhook, err := s.Webhook.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if webhook == nil {
+		return s.Webhook.AddDeliveryAttempt(ctx, delivery.ID,
+			models.WebhookAttempt{At: now, Error: "webhook was deleted"}, models.DeliveryFailed)
+	}
+
+	attempt := models.WebhookAttempt{At: now}
+	statusCode, sendErr := s.send(ctx, *webhook, *delivery, now)
+	attempt.StatusCode = statusCode
+	attempt.DurationMs = time.Since(now).Milliseconds()
+	status := models.DeliveryDelivered
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+		status = models.DeliveryPending
+		if job.Attempts >= s.MaxAttempts {
+			status = models.DeliveryFailed
+		}
+	}
+	if err := s.Webhook.AddDeliveryAttempt(ctx, delivery.ID, attempt, status); err != nil {
+		logs.Error(err)
+	}
+	return sendErr
+}
+
+func (s *WebhookSrv) send(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		logs.Error(err)
+		return 0, errors.New(webhookRequestFailed)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "JourneyPlanner-Webhook")
+	req.Header.Set(models.WebhookEventHeader, delivery.Event)
+	req.Header.Set(models.WebhookDeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(models.WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(models.WebhookSignatureHeader, signWebhook(webhook.Secret, now.Unix(), body))
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		logs.Errorf("webhook %s request error: %v", webhook.ID.Hex(), err)
+		return 0, errors.New(webhookRequestFailed)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
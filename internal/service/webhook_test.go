@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"event":"task.created"}`)
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		want      string
+	}{
+		{name: "signed", secret: "secret", timestamp: 1700000000, body: body,
+			want: "sha256=fc53e1d22cb0ed2216fe98c535f28e2e668e9a812f23e87d18f07b966afb540a"},
+		{name: "timestamp is signed", secret: "secret", timestamp: 1700000001, body: body,
+			want: "sha256=b0279603b78ad1c8a00631900a2c469cc59455bc12bbad8ef4c02c3e582242d9"},
+		{name: "other secret", secret: "other", timestamp: 1700000000, body: body,
+			want: "sha256=8acebc5f8f05430cf2a7816beafbde9189161256b082db1deb640ec339dc6456"},
+		{name: "empty body", secret: "secret", timestamp: 1700000000, body: nil,
+			want: "sha256=4bc5f74d868b97888288889c5d9d65df02526f94c1592a79fdf4fe8b26e311e5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := signWebhook(tt.secret, tt.timestamp, tt.body); got != tt.want {
+				t.Errorf("signWebhook() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "127.0.0.1", want: false},
+		{addr: "::1", want: false},
+		{addr: "10.1.2.3", want: false},
+		{addr: "172.16.0.1", want: false},
+		{addr: "192.168.1.1", want: false},
+		{addr: "fd00::1", want: false},
+		{addr: "169.254.169.254", want: false},
+		{addr: "fe80::1", want: false},
+		{addr: "0.0.0.0", want: false},
+		{addr: "::", want: false},
+		{addr: "224.0.0.1", want: false},
+		{addr: "::ffff:127.0.0.1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := isPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("isPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://example.com/hook", wantErr: false},
+		{url: "http://93.184.216.34:8080/hook", wantErr: false},
+		{url: "ftp://example.com/hook", wantErr: true},
+		{url: "/hook", wantErr: true},
+		{url: "http://localhost/hook", wantErr: true},
+		{url: "http://127.0.0.1:8080/hook", wantErr: true},
+		{url: "http://[::1]/hook", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if err := checkWebhookURL(tt.url); (err != nil) != tt.wantErr {
+				t.Errorf("checkWebhookURL(%s) error = %v, want error %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookClientRefusesInnerAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, err := newWebhookClient().Post(server.URL, "application/json", nil)
+	if !errors.Is(err, errWebhookAddress) {
+		t.Errorf("request to %s error = %v, want %v", server.URL, err, errWebhookAddress)
+	}
+}
@@ -0,0 +1,91 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SMTPMailer sends plain text emails through SMTP server,
+// for local testing any SMTP sink like MailHog works
+type SMTPMailer struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+// LogMailer only writes emails to the log, it is used when SMTP is not configured
+type LogMailer struct {
+	Logs *zap.SugaredLogger
+}
+
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+func NewMailerFromEnv(logs *zap.SugaredLogger) Mailer {
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		return &LogMailer{Logs: logs}
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "noreply@journeyplanner.local"
+	}
+	return &SMTPMailer{
+		Addr:     addr,
+		From:     from,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("invalid email header")
+	}
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address: %v", err)
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+	msg := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"Date: " + time.Now().UTC().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(m.Addr, auth, m.From, []string{to}, []byte(msg))
+	}()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("send mail error: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("send mail error: %v", ctx.Err())
+	}
+}
+
+func (m *LogMailer) Send(_ context.Context, to, subject, body string) error {
+	m.Logs.Infow("email is not sent, SMTP is not configured", "to", to, "subject", subject, "body", body)
+	return nil
+}